@@ -14,8 +14,14 @@ limitations under the License.
 package v1alpha2
 
 import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 // log is for logging in this package.
@@ -26,3 +32,50 @@ func (r *BareMetalCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
 		For(r).
 		Complete()
 }
+
+// +kubebuilder:webhook:verbs=update,path=/validate-infrastructure-cluster-x-k8s-io-v1alpha2-baremetalcluster,mutating=false,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=baremetalclusters,versions=v1alpha2,name=validation.v1alpha2.baremetalcluster.infrastructure.cluster.x-k8s.io
+
+var _ webhook.Validator = &BareMetalCluster{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *BareMetalCluster) ValidateCreate() error {
+	return nil
+}
+
+// ValidateUpdate rejects a change of the host or port of the APIEndpoint once
+// the BareMetalCluster is Ready, since the control plane is then reachable at
+// that endpoint, as for the v1alpha3 ControlPlaneEndpoint. A change of the
+// scheme only is accepted.
+func (r *BareMetalCluster) ValidateUpdate(old runtime.Object) error {
+	oldCluster, ok := old.(*BareMetalCluster)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf(
+			"expected a BareMetalCluster but got a %T", old,
+		))
+	}
+	if !oldCluster.Status.Ready {
+		return nil
+	}
+	// An invalid endpoint is left for the user to fix
+	oldHost, oldPort, err := parseAPIEndpoint(oldCluster.Spec.APIEndpoint)
+	if err != nil {
+		return nil
+	}
+
+	host, port, err := parseAPIEndpoint(r.Spec.APIEndpoint)
+	if err == nil && host == oldHost && port == oldPort {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		GroupVersion.WithKind("BareMetalCluster").GroupKind(), r.Name,
+		field.ErrorList{field.Forbidden(
+			field.NewPath("spec", "apiEndpoint"),
+			"cannot be changed once the BareMetalCluster is Ready",
+		)},
+	)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *BareMetalCluster) ValidateDelete() error {
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBareMetalClusterValidateUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		ready       bool
+		oldEndpoint string
+		newEndpoint string
+		expectErr   bool
+	}{
+		{
+			name:        "should succeed when endpoint changed on a cluster not ready",
+			oldEndpoint: "https://192.168.111.249:6443",
+			newEndpoint: "https://192.168.111.250:6443",
+			expectErr:   false,
+		},
+		{
+			name:        "should succeed when endpoint unchanged on a ready cluster",
+			ready:       true,
+			oldEndpoint: "https://192.168.111.249:6443",
+			newEndpoint: "https://192.168.111.249:6443",
+			expectErr:   false,
+		},
+		{
+			name:        "should succeed when only the scheme changed on a ready cluster",
+			ready:       true,
+			oldEndpoint: "https://192.168.111.249",
+			newEndpoint: "http://192.168.111.249:6443",
+			expectErr:   false,
+		},
+		{
+			name:        "should return error when host changed on a ready cluster",
+			ready:       true,
+			oldEndpoint: "https://192.168.111.249:6443",
+			newEndpoint: "https://192.168.111.250:6443",
+			expectErr:   true,
+		},
+		{
+			name:        "should return error when port changed on a ready cluster",
+			ready:       true,
+			oldEndpoint: "https://192.168.111.249:6443",
+			newEndpoint: "https://192.168.111.249:443",
+			expectErr:   true,
+		},
+		{
+			name:        "should return error when endpoint invalid on a ready cluster",
+			ready:       true,
+			oldEndpoint: "https://192.168.111.249:6443",
+			newEndpoint: ":/sadfc7:/:",
+			expectErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			old := &BareMetalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "bmc",
+					Namespace: "foo",
+				},
+				Spec:   BareMetalClusterSpec{APIEndpoint: tt.oldEndpoint},
+				Status: BareMetalClusterStatus{Ready: tt.ready},
+			}
+			c := old.DeepCopy()
+			c.Spec.APIEndpoint = tt.newEndpoint
+
+			err := c.ValidateUpdate(old)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+				g.Expect(err.Error()).To(ContainSubstring("spec.apiEndpoint"))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
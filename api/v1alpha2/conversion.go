@@ -21,6 +21,7 @@ import (
 	"github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
 	apiconversion "k8s.io/apimachinery/pkg/conversion"
 	"net/url"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 	"strconv"
 )
@@ -32,7 +33,47 @@ const (
 
 func (src *BareMetalCluster) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1alpha3.BareMetalCluster)
-	return Convert_v1alpha2_BareMetalCluster_To_v1alpha3_BareMetalCluster(src, dst, nil)
+	if err := Convert_v1alpha2_BareMetalCluster_To_v1alpha3_BareMetalCluster(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve the v1alpha2 endpoint fields on up-conversion, when they are
+	// not the ones ConvertFrom derives from the ControlPlaneEndpoint
+	if src.Spec.APIEndpoint != formatAPIEndpoint(dst.Spec.ControlPlaneEndpoint) ||
+		len(src.Status.APIEndpoints) == 0 {
+		// The annotations are shared with src until copied
+		spoke := src.DeepCopy()
+		delete(spoke.Annotations, utilconversion.DataAnnotation)
+		annotations := map[string]string{}
+		for key, value := range dst.Annotations {
+			annotations[key] = value
+		}
+		dst.Annotations = annotations
+		if err := utilconversion.MarshalData(spoke, dst); err != nil {
+			return err
+		}
+	}
+
+	// Manually restore data from annotations
+	restored := &v1alpha3.BareMetalCluster{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+	dst.Spec.RequireAllMachinesReady = restored.Spec.RequireAllMachinesReady
+	dst.Spec.ExternallyManagedEndpoint = restored.Spec.ExternallyManagedEndpoint
+	dst.Spec.MaxSimultaneousProvisioning = restored.Spec.MaxSimultaneousProvisioning
+	dst.Spec.EndpointAddressFamily = restored.Spec.EndpointAddressFamily
+	dst.Spec.ServiceEndpoints = restored.Spec.ServiceEndpoints
+	dst.Status.ReadySince = restored.Status.ReadySince
+	dst.Status.ReadyReason = restored.Status.ReadyReason
+	dst.Status.ControlPlaneInitialized = restored.Status.ControlPlaneInitialized
+	dst.Status.AvailableHosts = restored.Status.AvailableHosts
+	dst.Status.APIEndpoints = restored.Status.APIEndpoints
+	dst.Status.ObservedGeneration = restored.Status.ObservedGeneration
+	dst.Status.ServiceEndpoints = restored.Status.ServiceEndpoints
+	dst.Status.FailureDomains = restored.Status.FailureDomains
+
+	return nil
 }
 
 func (dst *BareMetalCluster) ConvertFrom(srcRaw conversion.Hub) error {
@@ -41,12 +82,36 @@ func (dst *BareMetalCluster) ConvertFrom(srcRaw conversion.Hub) error {
 		return err
 	}
 
-	// Fill the APIEndpoint
-	dst.Status.APIEndpoints = []APIEndpoint{
-		APIEndpoint{
-			Host: src.Spec.ControlPlaneEndpoint.Host,
-			Port: src.Spec.ControlPlaneEndpoint.Port,
-		},
+	// Restore the v1alpha2 endpoint fields preserved by ConvertTo, unless
+	// the ControlPlaneEndpoint changed since
+	restored := &BareMetalCluster{}
+	ok, err := utilconversion.UnmarshalData(src, restored)
+	if err != nil {
+		return err
+	}
+	if ok {
+		host, port, err := parseAPIEndpoint(restored.Spec.APIEndpoint)
+		if err == nil && host == src.Spec.ControlPlaneEndpoint.Host &&
+			port == src.Spec.ControlPlaneEndpoint.Port {
+			dst.Spec.APIEndpoint = restored.Spec.APIEndpoint
+		}
+	}
+
+	// Fill the APIEndpoint, if the status does not list any yet, nor did
+	// the restored one
+	if len(dst.Status.APIEndpoints) == 0 &&
+		(!ok || len(restored.Status.APIEndpoints) > 0) {
+		dst.Status.APIEndpoints = []APIEndpoint{
+			APIEndpoint{
+				Host: src.Spec.ControlPlaneEndpoint.Host,
+				Port: src.Spec.ControlPlaneEndpoint.Port,
+			},
+		}
+	}
+
+	// Preserve Hub data on down-conversion
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
 	}
 	return nil
 }
@@ -65,12 +130,62 @@ func (dst *BareMetalClusterList) ConvertFrom(srcRaw conversion.Hub) error {
 
 func (src *BareMetalMachine) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1alpha3.BareMetalMachine)
-	return Convert_v1alpha2_BareMetalMachine_To_v1alpha3_BareMetalMachine(src, dst, nil)
+	if err := Convert_v1alpha2_BareMetalMachine_To_v1alpha3_BareMetalMachine(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data from annotations
+	restored := &v1alpha3.BareMetalMachine{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+	dst.Spec.RootDeviceHints = restored.Spec.RootDeviceHints
+	dst.Spec.AutomatedCleaningMode = restored.Spec.AutomatedCleaningMode
+	dst.Spec.BootstrapFormat = restored.Spec.BootstrapFormat
+	dst.Spec.FailureDomain = restored.Spec.FailureDomain
+	dst.Spec.NetworkData = restored.Spec.NetworkData
+	dst.Spec.MetaData = restored.Spec.MetaData
+	dst.Spec.Image.ChecksumType = restored.Spec.Image.ChecksumType
+	dst.Spec.Image.OSType = restored.Spec.Image.OSType
+	dst.Spec.Image.SignatureURL = restored.Spec.Image.SignatureURL
+	dst.Spec.Image.SignatureKeyRef = restored.Spec.Image.SignatureKeyRef
+	dst.Spec.DeprovisionTimeout = restored.Spec.DeprovisionTimeout
+	dst.Spec.PreferCachedImage = restored.Spec.PreferCachedImage
+	dst.Spec.PowerManagementPolicy = restored.Spec.PowerManagementPolicy
+	dst.Spec.Firmware = restored.Spec.Firmware
+	dst.Spec.ProvisioningTimeout = restored.Spec.ProvisioningTimeout
+	dst.Spec.MinCPUs = restored.Spec.MinCPUs
+	dst.Spec.MinMemoryMiB = restored.Spec.MinMemoryMiB
+	dst.Spec.MaxProvisioningRetries = restored.Spec.MaxProvisioningRetries
+	dst.Spec.DeployKernelURL = restored.Spec.DeployKernelURL
+	dst.Spec.DeployRamdiskURL = restored.Spec.DeployRamdiskURL
+	dst.Spec.BootMode = restored.Spec.BootMode
+	dst.Status.FailureDomain = restored.Status.FailureDomain
+	dst.Status.HostName = restored.Status.HostName
+	dst.Status.PoweredOn = restored.Status.PoweredOn
+	dst.Status.Conditions = restored.Status.Conditions
+	dst.Status.Remediation = restored.Status.Remediation
+	dst.Status.LastReboot = restored.Status.LastReboot
+	dst.Status.ProvisioningRetry = restored.Status.ProvisioningRetry
+	dst.Status.DeprovisionStartTime = restored.Status.DeprovisionStartTime
+	dst.Status.ImageDownloadProgress = restored.Status.ImageDownloadProgress
+	dst.Status.HardwareDetails = restored.Status.HardwareDetails
+	dst.Status.ProvisioningStartTime = restored.Status.ProvisioningStartTime
+
+	return nil
 }
 
 func (dst *BareMetalMachine) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1alpha3.BareMetalMachine)
-	return Convert_v1alpha3_BareMetalMachine_To_v1alpha2_BareMetalMachine(src, dst, nil)
+	if err := Convert_v1alpha3_BareMetalMachine_To_v1alpha2_BareMetalMachine(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+	return nil
 }
 
 func (src *BareMetalMachineList) ConvertTo(dstRaw conversion.Hub) error {
@@ -87,12 +202,51 @@ func (dst *BareMetalMachineList) ConvertFrom(srcRaw conversion.Hub) error {
 
 func (src *BareMetalMachineTemplate) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1alpha3.BareMetalMachineTemplate)
-	return Convert_v1alpha2_BareMetalMachineTemplate_To_v1alpha3_BareMetalMachineTemplate(src, dst, nil)
+	if err := Convert_v1alpha2_BareMetalMachineTemplate_To_v1alpha3_BareMetalMachineTemplate(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data from annotations
+	restored := &v1alpha3.BareMetalMachineTemplate{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+	dst.Spec.Template.Spec.RootDeviceHints = restored.Spec.Template.Spec.RootDeviceHints
+	dst.Spec.Template.Spec.AutomatedCleaningMode = restored.Spec.Template.Spec.AutomatedCleaningMode
+	dst.Spec.Template.Spec.BootstrapFormat = restored.Spec.Template.Spec.BootstrapFormat
+	dst.Spec.Template.Spec.FailureDomain = restored.Spec.Template.Spec.FailureDomain
+	dst.Spec.Template.Spec.NetworkData = restored.Spec.Template.Spec.NetworkData
+	dst.Spec.Template.Spec.MetaData = restored.Spec.Template.Spec.MetaData
+	dst.Spec.Template.Spec.Image.ChecksumType = restored.Spec.Template.Spec.Image.ChecksumType
+	dst.Spec.Template.Spec.Image.OSType = restored.Spec.Template.Spec.Image.OSType
+	dst.Spec.Template.Spec.Image.SignatureURL = restored.Spec.Template.Spec.Image.SignatureURL
+	dst.Spec.Template.Spec.Image.SignatureKeyRef = restored.Spec.Template.Spec.Image.SignatureKeyRef
+	dst.Spec.Template.Spec.DeprovisionTimeout = restored.Spec.Template.Spec.DeprovisionTimeout
+	dst.Spec.Template.Spec.PreferCachedImage = restored.Spec.Template.Spec.PreferCachedImage
+	dst.Spec.Template.Spec.PowerManagementPolicy = restored.Spec.Template.Spec.PowerManagementPolicy
+	dst.Spec.Template.Spec.Firmware = restored.Spec.Template.Spec.Firmware
+	dst.Spec.Template.Spec.ProvisioningTimeout = restored.Spec.Template.Spec.ProvisioningTimeout
+	dst.Spec.Template.Spec.MinCPUs = restored.Spec.Template.Spec.MinCPUs
+	dst.Spec.Template.Spec.MinMemoryMiB = restored.Spec.Template.Spec.MinMemoryMiB
+	dst.Spec.Template.Spec.MaxProvisioningRetries = restored.Spec.Template.Spec.MaxProvisioningRetries
+	dst.Spec.Template.Spec.DeployKernelURL = restored.Spec.Template.Spec.DeployKernelURL
+	dst.Spec.Template.Spec.DeployRamdiskURL = restored.Spec.Template.Spec.DeployRamdiskURL
+	dst.Spec.Template.Spec.BootMode = restored.Spec.Template.Spec.BootMode
+
+	return nil
 }
 
 func (dst *BareMetalMachineTemplate) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1alpha3.BareMetalMachineTemplate)
-	return Convert_v1alpha3_BareMetalMachineTemplate_To_v1alpha2_BareMetalMachineTemplate(src, dst, nil)
+	if err := Convert_v1alpha3_BareMetalMachineTemplate_To_v1alpha2_BareMetalMachineTemplate(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+	return nil
 }
 
 func (src *BareMetalMachineTemplateList) ConvertTo(dstRaw conversion.Hub) error {
@@ -135,30 +289,35 @@ func Convert_v1alpha2_BareMetalClusterSpec_To_v1alpha3_BareMetalClusterSpec(in *
 		return err
 	}
 
-	endPoint := in.APIEndpoint
+	ip, port, err := parseAPIEndpoint(in.APIEndpoint)
+	if err != nil {
+		return err
+	}
+
+	out.ControlPlaneEndpoint = v1alpha3.APIEndpoint{
+		Host: ip,
+		Port: port,
+	}
+	return nil
+}
 
-	// Parse
+// parseAPIEndpoint returns the host and port of an APIEndpoint URL. The port
+// defaults to APIEndpointPort.
+func parseAPIEndpoint(endPoint string) (string, int, error) {
 	u, err := url.Parse(endPoint)
 	if err != nil {
-		return err
+		return "", 0, err
 	}
 
-	ip := u.Hostname()
 	p := u.Port()
-
 	if p == "" {
 		p = APIEndpointPort
 	}
 	port, err := strconv.Atoi(p)
 	if err != nil {
-		return err
+		return "", 0, err
 	}
-
-	out.ControlPlaneEndpoint = v1alpha3.APIEndpoint{
-		Host: ip,
-		Port: port,
-	}
-	return nil
+	return u.Hostname(), port, nil
 }
 
 func Convert_v1alpha3_BareMetalClusterSpec_To_v1alpha2_BareMetalClusterSpec(in *v1alpha3.BareMetalClusterSpec, out *BareMetalClusterSpec, s apiconversion.Scope) error {
@@ -166,13 +325,16 @@ func Convert_v1alpha3_BareMetalClusterSpec_To_v1alpha2_BareMetalClusterSpec(in *
 		return err
 	}
 
-	out.APIEndpoint = fmt.Sprintf("https://%s:%d", in.ControlPlaneEndpoint.Host,
-		in.ControlPlaneEndpoint.Port,
-	)
+	out.APIEndpoint = formatAPIEndpoint(in.ControlPlaneEndpoint)
 
 	return nil
 }
 
+// formatAPIEndpoint returns the APIEndpoint URL of endpoint.
+func formatAPIEndpoint(endpoint v1alpha3.APIEndpoint) string {
+	return fmt.Sprintf("https://%s:%d", endpoint.Host, endpoint.Port)
+}
+
 func Convert_v1alpha2_BareMetalMachineStatus_To_v1alpha3_BareMetalMachineStatus(in *BareMetalMachineStatus, out *v1alpha3.BareMetalMachineStatus, s apiconversion.Scope) error {
 	if err := autoConvert_v1alpha2_BareMetalMachineStatus_To_v1alpha3_BareMetalMachineStatus(in, out, s); err != nil {
 		return err
@@ -194,3 +356,16 @@ func Convert_v1alpha3_BareMetalMachineStatus_To_v1alpha2_BareMetalMachineStatus(
 
 	return nil
 }
+
+func Convert_v1alpha3_BareMetalMachineSpec_To_v1alpha2_BareMetalMachineSpec(in *v1alpha3.BareMetalMachineSpec, out *BareMetalMachineSpec, s apiconversion.Scope) error {
+	// RootDeviceHints, AutomatedCleaningMode, BootstrapFormat, FailureDomain,
+	// NetworkData and MetaData do not exist in v1alpha2, they are preserved in
+	// an annotation by the callers
+	return autoConvert_v1alpha3_BareMetalMachineSpec_To_v1alpha2_BareMetalMachineSpec(in, out, s)
+}
+
+func Convert_v1alpha3_Image_To_v1alpha2_Image(in *v1alpha3.Image, out *Image, s apiconversion.Scope) error {
+	// ChecksumType, OSType, SignatureURL and SignatureKeyRef do not exist in
+	// v1alpha2, they are preserved in an annotation by the callers
+	return autoConvert_v1alpha3_Image_To_v1alpha2_Image(in, out, s)
+}
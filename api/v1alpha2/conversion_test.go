@@ -14,6 +14,7 @@ limitations under the License.
 package v1alpha2
 
 import (
+	"fmt"
 	"math/rand"
 	"testing"
 	"time"
@@ -21,9 +22,11 @@ import (
 	fuzz "github.com/google/gofuzz"
 	"github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
 	. "github.com/onsi/gomega"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/diff"
 	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 )
 
@@ -54,6 +57,60 @@ func TestFuzzyConversion(t *testing.T) {
 
 	t.Run("for BareMetalCluster", utilconversion.FuzzTestFunc(scheme, &v1alpha3.BareMetalCluster{}, &BareMetalCluster{}, apiEndpointFuzzerFuncs))
 	t.Run("for BareMetalMachine", utilconversion.FuzzTestFunc(scheme, &v1alpha3.BareMetalMachine{}, &BareMetalMachine{}))
+	t.Run("for BareMetalMachineTemplate", utilconversion.FuzzTestFunc(scheme, &v1alpha3.BareMetalMachineTemplate{}, &BareMetalMachineTemplate{}))
+}
+
+// spokeAPIEndpointFuzzerFuncs sets the v1alpha2 Spec endpoint to a URL
+// the conversion parses, with or without a port, independently of the
+// Status endpoints.
+func spokeAPIEndpointFuzzerFuncs(codecs runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		func(in *BareMetalCluster, c fuzz.Continue) {
+			c.FuzzNoCustom(in)
+
+			b := make([]byte, 1+seededRand.Intn(63))
+			for i := range b {
+				b[i] = charset[seededRand.Intn(26)]
+			}
+			scheme := "https"
+			if c.RandBool() {
+				scheme = "http"
+			}
+			in.Spec.APIEndpoint = fmt.Sprintf("%s://%s", scheme, b)
+			if c.RandBool() {
+				in.Spec.APIEndpoint += fmt.Sprintf(":%d", 1+seededRand.Intn(65534))
+			}
+		},
+	}
+}
+
+func TestFuzzySpokeConversion(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(Succeed())
+
+	t.Run("for BareMetalCluster", func(t *testing.T) {
+		g := NewWithT(t)
+		fuzzer := utilconversion.GetFuzzer(scheme, spokeAPIEndpointFuzzerFuncs)
+
+		for i := 0; i < 10000; i++ {
+			spoke := &BareMetalCluster{}
+			fuzzer.Fuzz(spoke)
+			delete(spoke.Annotations, utilconversion.DataAnnotation)
+			spokeCopy := spoke.DeepCopy()
+
+			hub := &v1alpha3.BareMetalCluster{}
+			g.Expect(spokeCopy.ConvertTo(hub)).To(Succeed())
+			after := &BareMetalCluster{}
+			g.Expect(after.ConvertFrom(hub)).To(Succeed())
+			delete(after.Annotations, utilconversion.DataAnnotation)
+
+			g.Expect(apiequality.Semantic.DeepEqual(spoke, after)).To(BeTrue(),
+				diff.ObjectDiff(spoke, after),
+			)
+		}
+	})
 }
 
 func TestConvertBareMetalCluster(t *testing.T) {
@@ -123,6 +180,29 @@ func TestConvertBareMetalCluster(t *testing.T) {
 			g.Expect(dst.Status.APIEndpoints[0].Host).To(Equal("example.com"))
 			g.Expect(dst.Status.APIEndpoints[0].Port).To(BeEquivalentTo(6443))
 		})
+
+		t.Run("should restore Spec.APIEndpoint unless Spec.ControlPlaneEndpoint changed", func(t *testing.T) {
+			spoke := &BareMetalCluster{
+				Spec: BareMetalClusterSpec{
+					APIEndpoint: "http://example.com",
+				},
+			}
+			hub := &v1alpha3.BareMetalCluster{}
+			g.Expect(spoke.ConvertTo(hub)).To(Succeed())
+			g.Expect(hub.Annotations).To(HaveKey(utilconversion.DataAnnotation))
+			g.Expect(spoke.Annotations).NotTo(HaveKey(utilconversion.DataAnnotation))
+
+			dst := &BareMetalCluster{}
+			g.Expect(dst.ConvertFrom(hub.DeepCopy())).To(Succeed())
+			g.Expect(dst.Spec.APIEndpoint).To(Equal("http://example.com"))
+			g.Expect(dst.Status.APIEndpoints).To(BeEmpty())
+
+			hub.Spec.ControlPlaneEndpoint.Host = "api.example.com"
+			hub.Status.APIEndpoints = []v1alpha3.APIEndpoint{hub.Spec.ControlPlaneEndpoint}
+			dst = &BareMetalCluster{}
+			g.Expect(dst.ConvertFrom(hub)).To(Succeed())
+			g.Expect(dst.Spec.APIEndpoint).To(Equal("https://api.example.com:6443"))
+		})
 	})
 }
 
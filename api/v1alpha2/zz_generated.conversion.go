@@ -95,11 +95,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha3.BareMetalMachineSpec)(nil), (*BareMetalMachineSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_BareMetalMachineSpec_To_v1alpha2_BareMetalMachineSpec(a.(*v1alpha3.BareMetalMachineSpec), b.(*BareMetalMachineSpec), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*BareMetalMachineTemplate)(nil), (*v1alpha3.BareMetalMachineTemplate)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_BareMetalMachineTemplate_To_v1alpha3_BareMetalMachineTemplate(a.(*BareMetalMachineTemplate), b.(*v1alpha3.BareMetalMachineTemplate), scope)
 	}); err != nil {
@@ -165,11 +160,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha3.Image)(nil), (*Image)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_Image_To_v1alpha2_Image(a.(*v1alpha3.Image), b.(*Image), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddConversionFunc((*BareMetalClusterSpec)(nil), (*v1alpha3.BareMetalClusterSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_BareMetalClusterSpec_To_v1alpha3_BareMetalClusterSpec(a.(*BareMetalClusterSpec), b.(*v1alpha3.BareMetalClusterSpec), scope)
 	}); err != nil {
@@ -195,11 +185,21 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1alpha3.BareMetalMachineSpec)(nil), (*BareMetalMachineSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_BareMetalMachineSpec_To_v1alpha2_BareMetalMachineSpec(a.(*v1alpha3.BareMetalMachineSpec), b.(*BareMetalMachineSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1alpha3.BareMetalMachineStatus)(nil), (*BareMetalMachineStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_BareMetalMachineStatus_To_v1alpha2_BareMetalMachineStatus(a.(*v1alpha3.BareMetalMachineStatus), b.(*BareMetalMachineStatus), scope)
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1alpha3.Image)(nil), (*Image)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Image_To_v1alpha2_Image(a.(*v1alpha3.Image), b.(*Image), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -308,6 +308,11 @@ func autoConvert_v1alpha2_BareMetalClusterSpec_To_v1alpha3_BareMetalClusterSpec(
 func autoConvert_v1alpha3_BareMetalClusterSpec_To_v1alpha2_BareMetalClusterSpec(in *v1alpha3.BareMetalClusterSpec, out *BareMetalClusterSpec, s conversion.Scope) error {
 	// WARNING: in.ControlPlaneEndpoint requires manual conversion: does not exist in peer-type
 	out.NoCloudProvider = in.NoCloudProvider
+	// WARNING: in.RequireAllMachinesReady requires manual conversion: does not exist in peer-type
+	// WARNING: in.ExternallyManagedEndpoint requires manual conversion: does not exist in peer-type
+	// WARNING: in.MaxSimultaneousProvisioning requires manual conversion: does not exist in peer-type
+	// WARNING: in.EndpointAddressFamily requires manual conversion: does not exist in peer-type
+	// WARNING: in.ServiceEndpoints requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -316,7 +321,7 @@ func autoConvert_v1alpha2_BareMetalClusterStatus_To_v1alpha3_BareMetalClusterSta
 	// WARNING: in.ErrorReason requires manual conversion: does not exist in peer-type
 	// WARNING: in.ErrorMessage requires manual conversion: does not exist in peer-type
 	out.Ready = in.Ready
-	// WARNING: in.APIEndpoints requires manual conversion: does not exist in peer-type
+	out.APIEndpoints = *(*[]v1alpha3.APIEndpoint)(unsafe.Pointer(&in.APIEndpoints))
 	return nil
 }
 
@@ -325,6 +330,14 @@ func autoConvert_v1alpha3_BareMetalClusterStatus_To_v1alpha2_BareMetalClusterSta
 	// WARNING: in.FailureReason requires manual conversion: does not exist in peer-type
 	// WARNING: in.FailureMessage requires manual conversion: does not exist in peer-type
 	out.Ready = in.Ready
+	// WARNING: in.ReadySince requires manual conversion: does not exist in peer-type
+	// WARNING: in.ReadyReason requires manual conversion: does not exist in peer-type
+	// WARNING: in.ControlPlaneInitialized requires manual conversion: does not exist in peer-type
+	// WARNING: in.AvailableHosts requires manual conversion: does not exist in peer-type
+	out.APIEndpoints = *(*[]APIEndpoint)(unsafe.Pointer(&in.APIEndpoints))
+	// WARNING: in.ObservedGeneration requires manual conversion: does not exist in peer-type
+	// WARNING: in.ServiceEndpoints requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailureDomains requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -428,14 +441,26 @@ func autoConvert_v1alpha3_BareMetalMachineSpec_To_v1alpha2_BareMetalMachineSpec(
 	if err := Convert_v1alpha3_HostSelector_To_v1alpha2_HostSelector(&in.HostSelector, &out.HostSelector, s); err != nil {
 		return err
 	}
+	// WARNING: in.RootDeviceHints requires manual conversion: does not exist in peer-type
+	// WARNING: in.AutomatedCleaningMode requires manual conversion: does not exist in peer-type
+	// WARNING: in.BootstrapFormat requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailureDomain requires manual conversion: does not exist in peer-type
+	// WARNING: in.NetworkData requires manual conversion: does not exist in peer-type
+	// WARNING: in.MetaData requires manual conversion: does not exist in peer-type
+	// WARNING: in.DeprovisionTimeout requires manual conversion: does not exist in peer-type
+	// WARNING: in.PreferCachedImage requires manual conversion: does not exist in peer-type
+	// WARNING: in.PowerManagementPolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.Firmware requires manual conversion: does not exist in peer-type
+	// WARNING: in.ProvisioningTimeout requires manual conversion: does not exist in peer-type
+	// WARNING: in.MinCPUs requires manual conversion: does not exist in peer-type
+	// WARNING: in.MinMemoryMiB requires manual conversion: does not exist in peer-type
+	// WARNING: in.MaxProvisioningRetries requires manual conversion: does not exist in peer-type
+	// WARNING: in.DeployKernelURL requires manual conversion: does not exist in peer-type
+	// WARNING: in.DeployRamdiskURL requires manual conversion: does not exist in peer-type
+	// WARNING: in.BootMode requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha3_BareMetalMachineSpec_To_v1alpha2_BareMetalMachineSpec is an autogenerated conversion function.
-func Convert_v1alpha3_BareMetalMachineSpec_To_v1alpha2_BareMetalMachineSpec(in *v1alpha3.BareMetalMachineSpec, out *BareMetalMachineSpec, s conversion.Scope) error {
-	return autoConvert_v1alpha3_BareMetalMachineSpec_To_v1alpha2_BareMetalMachineSpec(in, out, s)
-}
-
 func autoConvert_v1alpha2_BareMetalMachineStatus_To_v1alpha3_BareMetalMachineStatus(in *BareMetalMachineStatus, out *v1alpha3.BareMetalMachineStatus, s conversion.Scope) error {
 	out.LastUpdated = (*v1.Time)(unsafe.Pointer(in.LastUpdated))
 	// WARNING: in.ErrorReason requires manual conversion: does not exist in peer-type
@@ -451,6 +476,17 @@ func autoConvert_v1alpha3_BareMetalMachineStatus_To_v1alpha2_BareMetalMachineSta
 	// WARNING: in.FailureReason requires manual conversion: does not exist in peer-type
 	// WARNING: in.FailureMessage requires manual conversion: does not exist in peer-type
 	out.Addresses = *(*apiv1alpha2.MachineAddresses)(unsafe.Pointer(&in.Addresses))
+	// WARNING: in.FailureDomain requires manual conversion: does not exist in peer-type
+	// WARNING: in.HostName requires manual conversion: does not exist in peer-type
+	// WARNING: in.PoweredOn requires manual conversion: does not exist in peer-type
+	// WARNING: in.Conditions requires manual conversion: does not exist in peer-type
+	// WARNING: in.Remediation requires manual conversion: does not exist in peer-type
+	// WARNING: in.LastReboot requires manual conversion: does not exist in peer-type
+	// WARNING: in.ProvisioningRetry requires manual conversion: does not exist in peer-type
+	// WARNING: in.DeprovisionStartTime requires manual conversion: does not exist in peer-type
+	// WARNING: in.ImageDownloadProgress requires manual conversion: does not exist in peer-type
+	// WARNING: in.HardwareDetails requires manual conversion: does not exist in peer-type
+	// WARNING: in.ProvisioningStartTime requires manual conversion: does not exist in peer-type
 	out.Phase = in.Phase
 	out.Ready = in.Ready
 	return nil
@@ -484,7 +520,17 @@ func Convert_v1alpha3_BareMetalMachineTemplate_To_v1alpha2_BareMetalMachineTempl
 
 func autoConvert_v1alpha2_BareMetalMachineTemplateList_To_v1alpha3_BareMetalMachineTemplateList(in *BareMetalMachineTemplateList, out *v1alpha3.BareMetalMachineTemplateList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]v1alpha3.BareMetalMachineTemplate)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1alpha3.BareMetalMachineTemplate, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_BareMetalMachineTemplate_To_v1alpha3_BareMetalMachineTemplate(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -495,7 +541,17 @@ func Convert_v1alpha2_BareMetalMachineTemplateList_To_v1alpha3_BareMetalMachineT
 
 func autoConvert_v1alpha3_BareMetalMachineTemplateList_To_v1alpha2_BareMetalMachineTemplateList(in *v1alpha3.BareMetalMachineTemplateList, out *BareMetalMachineTemplateList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]BareMetalMachineTemplate)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BareMetalMachineTemplate, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha3_BareMetalMachineTemplate_To_v1alpha2_BareMetalMachineTemplate(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -612,10 +668,9 @@ func Convert_v1alpha2_Image_To_v1alpha3_Image(in *Image, out *v1alpha3.Image, s
 func autoConvert_v1alpha3_Image_To_v1alpha2_Image(in *v1alpha3.Image, out *Image, s conversion.Scope) error {
 	out.URL = in.URL
 	out.Checksum = in.Checksum
+	// WARNING: in.ChecksumType requires manual conversion: does not exist in peer-type
+	// WARNING: in.OSType requires manual conversion: does not exist in peer-type
+	// WARNING: in.SignatureURL requires manual conversion: does not exist in peer-type
+	// WARNING: in.SignatureKeyRef requires manual conversion: does not exist in peer-type
 	return nil
 }
-
-// Convert_v1alpha3_Image_To_v1alpha2_Image is an autogenerated conversion function.
-func Convert_v1alpha3_Image_To_v1alpha2_Image(in *v1alpha3.Image, out *Image, s conversion.Scope) error {
-	return autoConvert_v1alpha3_Image_To_v1alpha2_Image(in, out, s)
-}
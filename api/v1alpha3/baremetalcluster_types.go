@@ -17,9 +17,9 @@ limitations under the License.
 package v1alpha3
 
 import (
-	"fmt"
-
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 )
 
@@ -29,29 +29,89 @@ const (
 	ClusterFinalizer = "baremetalcluster.infrastructure.cluster.x-k8s.io"
 )
 
+const (
+	// ReadyReasonEndpointValidated is set when the endpoints are valid and
+	// the cluster is Ready.
+	ReadyReasonEndpointValidated = "EndpointValidated"
+	// ReadyReasonEndpointMissing is set when no ControlPlaneEndpoint is
+	// given nor could be discovered.
+	ReadyReasonEndpointMissing = "EndpointMissing"
+	// ReadyReasonEndpointInvalid is set when the additional or named
+	// endpoints are invalid.
+	ReadyReasonEndpointInvalid = "EndpointInvalid"
+	// ReadyReasonProbeFailed is set when the ControlPlaneEndpoint does not
+	// resolve or does not accept connections.
+	ReadyReasonProbeFailed = "ProbeFailed"
+	// ReadyReasonDescendantsPending is set while the Machines of the cluster
+	// are not all provisioned, when it is required.
+	ReadyReasonDescendantsPending = "DescendantsPending"
+)
+
 // BareMetalClusterSpec defines the desired state of BareMetalCluster.
 type BareMetalClusterSpec struct {
 	// ControlPlaneEndpoint represents the endpoint used to communicate with the control plane.
 	ControlPlaneEndpoint APIEndpoint `json:"controlPlaneEndpoint"`
 	NoCloudProvider      bool        `json:"noCloudProvider,omitempty"`
+
+	// RequireAllMachinesReady makes the cluster Ready only once all its
+	// Machines are provisioned. A cluster without Machines is Ready.
+	// +optional
+	RequireAllMachinesReady bool `json:"requireAllMachinesReady,omitempty"`
+
+	// ExternallyManagedEndpoint tells that the ControlPlaneEndpoint is
+	// provided by an external load balancer. The endpoint may then be left
+	// empty, to be discovered from the load balancer.
+	// +optional
+	ExternallyManagedEndpoint bool `json:"externallyManagedEndpoint,omitempty"`
+
+	// MaxSimultaneousProvisioning caps the number of BareMetalMachines of the
+	// cluster provisioning a host at the same time. The others wait for a
+	// slot before being associated with a host. No cap when unset or 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxSimultaneousProvisioning int `json:"maxSimultaneousProvisioning,omitempty"`
+
+	// EndpointAddressFamily is the IP family listed first in
+	// Status.APIEndpoints when the control plane has several endpoints. The
+	// order is left as is when unset or "dualstack".
+	// +kubebuilder:validation:Enum=ipv4;ipv6;dualstack
+	// +optional
+	EndpointAddressFamily EndpointAddressFamily `json:"endpointAddressFamily,omitempty"`
+
+	// ServiceEndpoints are additional endpoints exposed by the cluster, e.g.
+	// an ingress VIP, by name. They are published in the status once valid.
+	// +optional
+	ServiceEndpoints map[string]APIEndpoint `json:"serviceEndpoints,omitempty"`
 }
 
+// EndpointAddressFamily is the preferred IP family of the control plane
+// endpoints.
+type EndpointAddressFamily string
+
+const (
+	// EndpointAddressFamilyIPv4 lists the IPv4 endpoints first.
+	EndpointAddressFamilyIPv4 EndpointAddressFamily = "ipv4"
+	// EndpointAddressFamilyIPv6 lists the IPv6 endpoints first.
+	EndpointAddressFamilyIPv6 EndpointAddressFamily = "ipv6"
+	// EndpointAddressFamilyDualStack has no preference.
+	EndpointAddressFamilyDualStack EndpointAddressFamily = "dualstack"
+)
+
 // IsValid returns an error if the object is not valid, otherwise nil. The
-// string representation of the error is suitable for human consumption.
+// string representation of the error is suitable for human consumption, and
+// names the paths of the missing fields.
 func (s *BareMetalClusterSpec) IsValid() error {
-	missing := []string{}
+	var allErrs field.ErrorList
+	endpointPath := field.NewPath("spec", "controlPlaneEndpoint")
 	if s.ControlPlaneEndpoint.Host == "" {
-		missing = append(missing, "ControlPlaneEndpoint.Host")
+		allErrs = append(allErrs, field.Required(endpointPath.Child("host"), ""))
 	}
 
 	if s.ControlPlaneEndpoint.Port == 0 {
-		missing = append(missing, "ControlPlaneEndpoint.Host")
+		allErrs = append(allErrs, field.Required(endpointPath.Child("port"), ""))
 	}
 
-	if len(missing) > 0 {
-		return fmt.Errorf("Missing fields from Spec: %v", missing)
-	}
-	return nil
+	return allErrs.ToAggregate()
 }
 
 // BareMetalClusterStatus defines the observed state of BareMetalCluster.
@@ -76,6 +136,50 @@ type BareMetalClusterStatus struct {
 	// steps need to be performed. Required by Cluster API. Set to True by the
 	// BaremetalCluster controller after creation.
 	Ready bool `json:"ready"`
+
+	// ReadySince is the time at which Ready was last set to true. It is
+	// cleared when Ready becomes false.
+	// +optional
+	ReadySince *metav1.Time `json:"readySince,omitempty"`
+
+	// ReadyReason is a machine-readable reason for the value of Ready, one
+	// of EndpointValidated, EndpointMissing, EndpointInvalid, ProbeFailed or
+	// DescendantsPending.
+	// +optional
+	ReadyReason string `json:"readyReason,omitempty"`
+
+	// ControlPlaneInitialized denotes that a control plane Machine of the
+	// cluster has been provisioned. It is never cleared once set. Until then,
+	// the cluster is only waiting for its first control plane node.
+	// +optional
+	ControlPlaneInitialized bool `json:"controlPlaneInitialized,omitempty"`
+
+	// AvailableHosts is the number of BareMetalHosts, in the namespace of the
+	// BaremetalCluster, that have no consumer and can be provisioned.
+	// +optional
+	AvailableHosts int `json:"availableHosts,omitempty"`
+
+	// APIEndpoints are the endpoints to communicate with the control plane:
+	// the ControlPlaneEndpoint, and those discovered at runtime, e.g. by a
+	// load balancer controller.
+	// +optional
+	APIEndpoints []APIEndpoint `json:"apiEndpoints,omitempty"`
+
+	// ObservedGeneration is the Generation of the BareMetalCluster last
+	// reconciled successfully. The status does not reflect the latest spec
+	// while it is lower than the Generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ServiceEndpoints are the named endpoints of the Spec, once validated.
+	// +optional
+	ServiceEndpoints map[string]APIEndpoint `json:"serviceEndpoints,omitempty"`
+
+	// FailureDomains are the failure domains, e.g. the racks, of the
+	// available BareMetalHosts, as given by their failure domain label. They
+	// are synced to the Cluster by Cluster API, to spread the Machines.
+	// +optional
+	FailureDomains capi.FailureDomains `json:"failureDomains,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -87,6 +191,7 @@ type BareMetalClusterStatus struct {
 // +kubebuilder:printcolumn:name="Error",type="string",JSONPath=".status.failureReason",description="Most recent error"
 // +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this BMCluster belongs"
 // +kubebuilder:printcolumn:name="Endpoint",type="string",JSONPath=".spec.controlPlaneEndpoint",description="Control plane endpoint"
+// +kubebuilder:printcolumn:name="Available",type="integer",JSONPath=".status.availableHosts",description="BareMetalHosts available for provisioning",priority=1
 
 // BareMetalCluster is the Schema for the baremetalclusters API
 type BareMetalCluster struct {
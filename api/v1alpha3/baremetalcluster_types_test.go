@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -83,3 +84,18 @@ func TestClusterSpecIsValid(t *testing.T) {
 		}
 	}
 }
+
+func TestClusterSpecIsValidFieldPaths(t *testing.T) {
+	spec := BareMetalClusterSpec{}
+	err := spec.IsValid()
+	if err == nil {
+		t.Fatal("Did not get error from empty spec")
+	}
+	for _, path := range []string{
+		"spec.controlPlaneEndpoint.host", "spec.controlPlaneEndpoint.port",
+	} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("Error %q does not name %s", err, path)
+		}
+	}
+}
@@ -14,8 +14,17 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -33,9 +42,14 @@ func (c *BareMetalCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
 var _ webhook.Defaulter = &BareMetalCluster{}
 var _ webhook.Validator = &BareMetalCluster{}
 
+// Default trims the spaces around the host of the ControlPlaneEndpoint, then
+// sets the API server port to 6443 when only the host is given, and records it
+// in the DefaultedFieldsAnnotation. An empty endpoint is left for validation.
 func (c *BareMetalCluster) Default() {
-	if c.Spec.ControlPlaneEndpoint.Port == 0 {
+	c.Spec.ControlPlaneEndpoint.Host = strings.TrimSpace(c.Spec.ControlPlaneEndpoint.Host)
+	if c.Spec.ControlPlaneEndpoint.Host != "" && c.Spec.ControlPlaneEndpoint.Port == 0 {
 		c.Spec.ControlPlaneEndpoint.Port = 6443
+		recordDefaultedFields(&c.ObjectMeta, []string{"spec.controlPlaneEndpoint.port"})
 	}
 }
 
@@ -44,9 +58,27 @@ func (c *BareMetalCluster) ValidateCreate() error {
 	return c.validate()
 }
 
-// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+// ValidateUpdate implements webhook.Validator so a webhook will be registered
+// for the type. On top of the checks of ValidateCreate, it rejects a change of
+// the host or port of the ControlPlaneEndpoint once the BareMetalCluster is
+// Ready, since the control plane is then reachable at that endpoint. An empty
+// endpoint may still be set, e.g. when discovered by the controller.
 func (c *BareMetalCluster) ValidateUpdate(old runtime.Object) error {
-	return c.validate()
+	if err := c.validate(); err != nil {
+		return err
+	}
+	oldCluster, ok := old.(*BareMetalCluster)
+	if !ok || !oldCluster.Status.Ready ||
+		oldCluster.Spec.ControlPlaneEndpoint.Host == "" {
+		return nil
+	}
+	if c.Spec.ControlPlaneEndpoint == oldCluster.Spec.ControlPlaneEndpoint {
+		return nil
+	}
+	return c.invalidError(field.ErrorList{field.Forbidden(
+		field.NewPath("spec", "controlPlaneEndpoint"),
+		"cannot be changed once the BareMetalCluster is Ready",
+	)})
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -56,20 +88,183 @@ func (c *BareMetalCluster) ValidateDelete() error {
 
 func (c *BareMetalCluster) validate() error {
 	var allErrs field.ErrorList
-	if len(c.Spec.ControlPlaneEndpoint.Host) == 0 {
+	// An externally managed endpoint may be left empty, to be discovered
+	// from the load balancer
+	if !c.Spec.ExternallyManagedEndpoint || c.Spec.ControlPlaneEndpoint != (APIEndpoint{}) {
+		allErrs = ValidateControlPlaneEndpoint(c.Spec.ControlPlaneEndpoint,
+			field.NewPath("spec", "controlPlaneEndpoint"),
+		)
+	}
+	allErrs = append(allErrs, validateEndpointAddressFamily(
+		c.Spec.EndpointAddressFamily,
+		field.NewPath("spec", "endpointAddressFamily"),
+	)...)
+	allErrs = append(allErrs, ValidateServiceEndpoints(
+		c.Spec.ServiceEndpoints,
+		field.NewPath("spec", "serviceEndpoints"),
+	)...)
+	allErrs = append(allErrs, validateOwnerReferences(
+		c.OwnerReferences,
+		field.NewPath("metadata", "ownerReferences"),
+	)...)
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return c.invalidError(allErrs)
+}
+
+// invalidError returns an Invalid error whose message names the namespace
+// and name of the BareMetalCluster, so that the failing object can be told
+// apart when many are applied at once.
+func (c *BareMetalCluster) invalidError(allErrs field.ErrorList) error {
+	groupKind := GroupVersion.WithKind("BareMetalCluster").GroupKind()
+	err := apierrors.NewInvalid(groupKind, c.Name, allErrs)
+	key := types.NamespacedName{Namespace: c.Namespace, Name: c.Name}
+	err.ErrStatus.Message = fmt.Sprintf("%s %q is invalid: %v",
+		groupKind.String(), key.String(), allErrs.ToAggregate(),
+	)
+	return err
+}
+
+// validateEndpointAddressFamily checks that the family is one of the known
+// ones. An empty family is accepted, the endpoints are then left unordered.
+func validateEndpointAddressFamily(family EndpointAddressFamily, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch family {
+	case "", EndpointAddressFamilyIPv4, EndpointAddressFamilyIPv6,
+		EndpointAddressFamilyDualStack:
+	default:
 		allErrs = append(
 			allErrs,
-			field.Invalid(
-				field.NewPath("spec", "controlPlaneEndpoint"),
-				c.Spec.ControlPlaneEndpoint.Host,
-				"is required",
+			field.NotSupported(
+				fldPath,
+				family,
+				[]string{
+					string(EndpointAddressFamilyIPv4),
+					string(EndpointAddressFamilyIPv6),
+					string(EndpointAddressFamilyDualStack),
+				},
 			),
 		)
+	}
+	return allErrs
+}
+
+// validateOwnerReferences checks that at most one owner reference is a
+// controller, and that no owner is referenced twice, so that the owner
+// Cluster is not ambiguous. References are compared by group, kind and name,
+// the version does not matter.
+func validateOwnerReferences(refs []metav1.OwnerReference, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := map[string]bool{}
+	controller := false
+	for i, ref := range refs {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(
+				fldPath.Index(i).Child("apiVersion"), ref.APIVersion, err.Error(),
+			))
+			continue
+		}
+		key := gv.Group + "/" + ref.Kind + "/" + ref.Name
+		if seen[key] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), key))
+		}
+		seen[key] = true
 
+		if ref.Controller != nil && *ref.Controller {
+			if controller {
+				allErrs = append(allErrs, field.Invalid(
+					fldPath.Index(i).Child("controller"), true,
+					"only one owner reference can be a controller",
+				))
+			}
+			controller = true
+		}
 	}
+	return allErrs
+}
 
-	if len(allErrs) == 0 {
-		return nil
+// ValidateServiceEndpoints returns the errors found in named endpoints. The
+// names must be DNS labels, and each endpoint is checked like the control
+// plane endpoint.
+func ValidateServiceEndpoints(endpoints map[string]APIEndpoint, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	names := make([]string, 0, len(endpoints))
+	for name := range endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					fldPath.Key(name),
+					name,
+					fmt.Sprintf("must be a DNS label: %v", errs),
+				),
+			)
+		}
+		allErrs = append(allErrs,
+			ValidateControlPlaneEndpoint(endpoints[name], fldPath.Key(name))...,
+		)
+	}
+	return allErrs
+}
+
+// ValidateControlPlaneEndpoint returns the errors found in a control plane
+// endpoint. The host must be a DNS name or a non-reserved IP address, without
+// scheme, port or surrounding spaces. It is shared by the webhook and the
+// BareMetalCluster controller so that both reject the same endpoints.
+func ValidateControlPlaneEndpoint(endpoint APIEndpoint, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if host := strings.TrimSpace(endpoint.Host); len(host) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("host"), ""))
+	} else if host != endpoint.Host {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath.Child("host"),
+				endpoint.Host,
+				"must not have leading or trailing spaces",
+			),
+		)
+	} else if ip := net.ParseIP(endpoint.Host); ip != nil {
+		if ip.IsUnspecified() || ip.IsLoopback() || ip.IsMulticast() ||
+			ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					fldPath.Child("host"),
+					endpoint.Host,
+					"is a reserved address",
+				),
+			)
+		}
+	} else if errs := validation.IsDNS1123Subdomain(endpoint.Host); len(errs) > 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath.Child("host"),
+				endpoint.Host,
+				fmt.Sprintf("must be an IP address or a DNS name: %v", errs),
+			),
+		)
 	}
-	return apierrors.NewInvalid(GroupVersion.WithKind("BareMetalCluster").GroupKind(), c.Name, allErrs)
+
+	if endpoint.Port < 1 || endpoint.Port > 65535 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath.Child("port"),
+				endpoint.Port,
+				"must be between 1 and 65535",
+			),
+		)
+	}
+
+	return allErrs
 }
@@ -17,23 +17,81 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 func TestBareMetalClusterDefault(t *testing.T) {
-	g := NewWithT(t)
-
-	c := &BareMetalCluster{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "fooboo",
+	tests := []struct {
+		name              string
+		endpoint          APIEndpoint
+		expectedHost      string
+		expectedPort      int
+		expectedDefaulted []string
+		expectErr         bool
+	}{
+		{
+			name:              "should default the port when only host is set",
+			endpoint:          APIEndpoint{Host: "abc.com"},
+			expectedHost:      "abc.com",
+			expectedPort:      6443,
+			expectedDefaulted: []string{"spec.controlPlaneEndpoint.port"},
+			expectErr:         false,
 		},
-		Spec: BareMetalClusterSpec{
-			ControlPlaneEndpoint: APIEndpoint{},
+		{
+			name:         "should keep the port when host and port are set",
+			endpoint:     APIEndpoint{Host: "abc.com", Port: 443},
+			expectedHost: "abc.com",
+			expectedPort: 443,
+			expectErr:    false,
+		},
+		{
+			name:         "should not default the port when endpoint empty",
+			endpoint:     APIEndpoint{},
+			expectedPort: 0,
+			expectErr:    true,
+		},
+		{
+			name:         "should trim the spaces around the host",
+			endpoint:     APIEndpoint{Host: " abc.com ", Port: 443},
+			expectedHost: "abc.com",
+			expectedPort: 443,
+			expectErr:    false,
+		},
+		{
+			name:         "should not default the port when host is only spaces",
+			endpoint:     APIEndpoint{Host: "  "},
+			expectedHost: "",
+			expectedPort: 0,
+			expectErr:    true,
 		},
 	}
-	c.Default()
 
-	g.Expect(c.Spec.ControlPlaneEndpoint.Port).To(BeEquivalentTo(6443))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := &BareMetalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "fooboo",
+				},
+				Spec: BareMetalClusterSpec{
+					ControlPlaneEndpoint: tt.endpoint,
+				},
+			}
+			c.Default()
+
+			g.Expect(c.Spec.ControlPlaneEndpoint.Host).To(Equal(tt.expectedHost))
+			g.Expect(c.Spec.ControlPlaneEndpoint.Port).To(Equal(tt.expectedPort))
+			g.Expect(DefaultedFields(c)).To(Equal(tt.expectedDefaulted))
+			if tt.expectErr {
+				g.Expect(c.ValidateCreate()).NotTo(Succeed())
+			} else {
+				g.Expect(c.ValidateCreate()).To(Succeed())
+			}
+		})
+	}
 }
 
 func TestBareMetalClusterValidation(t *testing.T) {
@@ -50,6 +108,44 @@ func TestBareMetalClusterValidation(t *testing.T) {
 	}
 	invalidHost := valid.DeepCopy()
 	invalidHost.Spec.ControlPlaneEndpoint.Host = ""
+	spacesHost := valid.DeepCopy()
+	spacesHost.Spec.ControlPlaneEndpoint.Host = "  "
+	paddedHost := valid.DeepCopy()
+	paddedHost.Spec.ControlPlaneEndpoint.Host = " abc.com "
+	urlHost := valid.DeepCopy()
+	urlHost.Spec.ControlPlaneEndpoint.Host = "https://abc.com"
+	hostWithPort := valid.DeepCopy()
+	hostWithPort.Spec.ControlPlaneEndpoint.Host = "abc.com:443"
+	loopbackHost := valid.DeepCopy()
+	loopbackHost.Spec.ControlPlaneEndpoint.Host = "127.0.0.1"
+	ipHost := valid.DeepCopy()
+	ipHost.Spec.ControlPlaneEndpoint.Host = "192.168.111.249"
+	invalidPort := valid.DeepCopy()
+	invalidPort.Spec.ControlPlaneEndpoint.Port = 70000
+	externalEmpty := valid.DeepCopy()
+	externalEmpty.Spec.ControlPlaneEndpoint = APIEndpoint{}
+	externalEmpty.Spec.ExternallyManagedEndpoint = true
+	externalURLHost := urlHost.DeepCopy()
+	externalURLHost.Spec.ExternallyManagedEndpoint = true
+	ownedByCluster := valid.DeepCopy()
+	ownedByCluster.OwnerReferences = []metav1.OwnerReference{
+		clusterOwnerRef("cluster-1", true),
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "cm"},
+	}
+	duplicateOwners := valid.DeepCopy()
+	duplicateOwners.OwnerReferences = []metav1.OwnerReference{
+		clusterOwnerRef("cluster-1", false),
+		clusterOwnerRef("cluster-1", false),
+	}
+	conflictingOwners := valid.DeepCopy()
+	conflictingOwners.OwnerReferences = []metav1.OwnerReference{
+		clusterOwnerRef("cluster-1", true),
+		clusterOwnerRef("cluster-2", true),
+	}
+	serviceEndpoints := valid.DeepCopy()
+	serviceEndpoints.Spec.ServiceEndpoints = map[string]APIEndpoint{
+		"ingress": {Host: "192.168.111.250", Port: 443},
+	}
 
 	tests := []struct {
 		name      string
@@ -61,6 +157,71 @@ func TestBareMetalClusterValidation(t *testing.T) {
 			expectErr: true,
 			c:         invalidHost,
 		},
+		{
+			name:      "should return error when host is only spaces",
+			expectErr: true,
+			c:         spacesHost,
+		},
+		{
+			name:      "should return error when host has surrounding spaces",
+			expectErr: true,
+			c:         paddedHost,
+		},
+		{
+			name:      "should return error when host is a URL",
+			expectErr: true,
+			c:         urlHost,
+		},
+		{
+			name:      "should return error when host contains a port",
+			expectErr: true,
+			c:         hostWithPort,
+		},
+		{
+			name:      "should return error when host is a loopback address",
+			expectErr: true,
+			c:         loopbackHost,
+		},
+		{
+			name:      "should return error when port is out of range",
+			expectErr: true,
+			c:         invalidPort,
+		},
+		{
+			name:      "should return error when externally managed host is a URL",
+			expectErr: true,
+			c:         externalURLHost,
+		},
+		{
+			name:      "should succeed when externally managed endpoint empty",
+			expectErr: false,
+			c:         externalEmpty,
+		},
+		{
+			name:      "should return error when an owner is referenced twice",
+			expectErr: true,
+			c:         duplicateOwners,
+		},
+		{
+			name:      "should return error when two owners are controllers",
+			expectErr: true,
+			c:         conflictingOwners,
+		},
+		{
+			name:      "should succeed with a single controller owner",
+			expectErr: false,
+			c:         ownedByCluster,
+		},
+		{
+			name:      "should succeed with a valid service endpoint",
+			expectErr: false,
+			c:         serviceEndpoints,
+		},
+		{
+			name:      "should succeed when host is an IP address",
+			expectErr: false,
+			c:         ipHost,
+		},
 		{
 			name:      "should succeed when endpoint correct",
 			expectErr: false,
@@ -82,3 +243,179 @@ func TestBareMetalClusterValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestBareMetalClusterValidationFieldPaths(t *testing.T) {
+	valid := &BareMetalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+		},
+		Spec: BareMetalClusterSpec{
+			ControlPlaneEndpoint: APIEndpoint{
+				Host: "abc.com",
+				Port: 443,
+			},
+		},
+	}
+	missingHost := valid.DeepCopy()
+	missingHost.Spec.ControlPlaneEndpoint.Host = ""
+	spacesHost := valid.DeepCopy()
+	spacesHost.Spec.ControlPlaneEndpoint.Host = "  "
+	paddedHost := valid.DeepCopy()
+	paddedHost.Spec.ControlPlaneEndpoint.Host = " abc.com "
+	urlHost := valid.DeepCopy()
+	urlHost.Spec.ControlPlaneEndpoint.Host = "https://abc.com"
+	invalidPort := valid.DeepCopy()
+	invalidPort.Spec.ControlPlaneEndpoint.Port = 70000
+	invalidFamily := valid.DeepCopy()
+	invalidFamily.Spec.EndpointAddressFamily = "ipv5"
+	invalidServiceEndpoint := valid.DeepCopy()
+	invalidServiceEndpoint.Spec.ServiceEndpoints = map[string]APIEndpoint{
+		"ingress": {Host: "192.168.111.250", Port: 443},
+		"metrics": {Host: "127.0.0.1", Port: 9100},
+	}
+	invalidServiceName := valid.DeepCopy()
+	invalidServiceName.Spec.ServiceEndpoints = map[string]APIEndpoint{
+		"Ingress_VIP": {Host: "192.168.111.250", Port: 443},
+	}
+	duplicateOwners := valid.DeepCopy()
+	duplicateOwners.OwnerReferences = []metav1.OwnerReference{
+		clusterOwnerRef("cluster-1", true),
+		clusterOwnerRef("cluster-1", false),
+	}
+	conflictingOwners := valid.DeepCopy()
+	conflictingOwners.OwnerReferences = []metav1.OwnerReference{
+		clusterOwnerRef("cluster-1", true),
+		clusterOwnerRef("cluster-2", true),
+	}
+
+	tests := []struct {
+		name  string
+		field string
+		c     *BareMetalCluster
+	}{
+		{name: "missing host", field: "spec.controlPlaneEndpoint.host", c: missingHost},
+		{name: "host is only spaces", field: "spec.controlPlaneEndpoint.host", c: spacesHost},
+		{name: "host has surrounding spaces", field: "spec.controlPlaneEndpoint.host", c: paddedHost},
+		{name: "host is a URL", field: "spec.controlPlaneEndpoint.host", c: urlHost},
+		{name: "port out of range", field: "spec.controlPlaneEndpoint.port", c: invalidPort},
+		{name: "unknown address family", field: "spec.endpointAddressFamily", c: invalidFamily},
+		{name: "invalid service endpoint", field: "spec.serviceEndpoints[metrics].host", c: invalidServiceEndpoint},
+		{name: "invalid service name", field: "spec.serviceEndpoints[Ingress_VIP]", c: invalidServiceName},
+		{name: "duplicate owner", field: "metadata.ownerReferences[1]", c: duplicateOwners},
+		{name: "conflicting controllers", field: "metadata.ownerReferences[1].controller", c: conflictingOwners},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := tt.c.ValidateCreate()
+			g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			g.Expect(invalidFields(err)).To(ConsistOf(tt.field))
+		})
+	}
+}
+
+func TestBareMetalClusterValidationMessage(t *testing.T) {
+	g := NewWithT(t)
+
+	c := &BareMetalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster-2",
+			Namespace: "foo",
+		},
+		Spec: BareMetalClusterSpec{
+			ControlPlaneEndpoint: APIEndpoint{
+				Host: "abc.com",
+				Port: 70000,
+			},
+		},
+	}
+
+	err := c.ValidateCreate()
+	g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring(`"foo/cluster-2" is invalid`))
+	g.Expect(err.Error()).To(ContainSubstring("spec.controlPlaneEndpoint.port"))
+	g.Expect(invalidFields(err)).To(ConsistOf("spec.controlPlaneEndpoint.port"))
+}
+
+// clusterOwnerRef returns an owner reference to the named Cluster.
+func clusterOwnerRef(name string, controller bool) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: "cluster.x-k8s.io/v1alpha3",
+		Kind:       "Cluster",
+		Name:       name,
+		Controller: pointer.BoolPtr(controller),
+	}
+}
+
+func TestBareMetalClusterValidateUpdateControlPlaneEndpoint(t *testing.T) {
+	tests := []struct {
+		name        string
+		ready       bool
+		oldEndpoint APIEndpoint
+		newEndpoint APIEndpoint
+		expectErr   bool
+	}{
+		{
+			name:        "should succeed when endpoint changed on a cluster not ready",
+			oldEndpoint: APIEndpoint{Host: "192.168.111.249", Port: 6443},
+			newEndpoint: APIEndpoint{Host: "192.168.111.250", Port: 6443},
+			expectErr:   false,
+		},
+		{
+			name:        "should succeed when endpoint unchanged on a ready cluster",
+			ready:       true,
+			oldEndpoint: APIEndpoint{Host: "192.168.111.249", Port: 6443},
+			newEndpoint: APIEndpoint{Host: "192.168.111.249", Port: 6443},
+			expectErr:   false,
+		},
+		{
+			name:        "should succeed when an empty endpoint is set on a ready cluster",
+			ready:       true,
+			oldEndpoint: APIEndpoint{},
+			newEndpoint: APIEndpoint{Host: "192.168.111.249", Port: 6443},
+			expectErr:   false,
+		},
+		{
+			name:        "should return error when host changed on a ready cluster",
+			ready:       true,
+			oldEndpoint: APIEndpoint{Host: "192.168.111.249", Port: 6443},
+			newEndpoint: APIEndpoint{Host: "192.168.111.250", Port: 6443},
+			expectErr:   true,
+		},
+		{
+			name:        "should return error when port changed on a ready cluster",
+			ready:       true,
+			oldEndpoint: APIEndpoint{Host: "192.168.111.249", Port: 6443},
+			newEndpoint: APIEndpoint{Host: "192.168.111.249", Port: 443},
+			expectErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			old := &BareMetalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "bmc",
+					Namespace: "foo",
+				},
+				Spec:   BareMetalClusterSpec{ControlPlaneEndpoint: tt.oldEndpoint},
+				Status: BareMetalClusterStatus{Ready: tt.ready},
+			}
+			c := old.DeepCopy()
+			c.Spec.ControlPlaneEndpoint = tt.newEndpoint
+
+			err := c.ValidateUpdate(old)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+				g.Expect(err.Error()).To(ContainSubstring("spec.controlPlaneEndpoint"))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
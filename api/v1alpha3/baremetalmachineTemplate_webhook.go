@@ -52,29 +52,9 @@ func (c *BareMetalMachineTemplate) ValidateDelete() error {
 }
 
 func (c *BareMetalMachineTemplate) validate() error {
-	var allErrs field.ErrorList
-	if len(c.Spec.Template.Spec.Image.URL) == 0 {
-		allErrs = append(
-			allErrs,
-			field.Invalid(
-				field.NewPath("spec", "Template", "Spec", "Image", "URL"),
-				c.Spec.Template.Spec.Image.URL,
-				"is required",
-			),
-		)
-	}
-
-	if len(c.Spec.Template.Spec.Image.Checksum) == 0 {
-		allErrs = append(
-			allErrs,
-			field.Invalid(
-				field.NewPath("spec", "Template", "Spec", "Image", "Checksum"),
-				c.Spec.Template.Spec.Image.Checksum,
-				"is required",
-			),
-		)
-
-	}
+	allErrs := c.Spec.Template.Spec.validate(c.Namespace,
+		field.NewPath("spec", "template", "spec"),
+	)
 
 	if len(allErrs) == 0 {
 		return nil
@@ -17,7 +17,9 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 func TestBareMetalMachineTemplateDefault(t *testing.T) {
@@ -40,8 +42,9 @@ func TestBareMetalMachineTemplateValidation(t *testing.T) {
 			Template: BareMetalMachineTemplateResource{
 				Spec: BareMetalMachineSpec{
 					Image: Image{
-						URL:      "http://abc.com/image",
-						Checksum: "http://abc.com/image.md5sum",
+						URL:          "http://abc.com/image",
+						Checksum:     "http://abc.com/image.md5sum",
+						ChecksumType: ChecksumTypeMD5,
 					},
 				},
 			},
@@ -53,11 +56,61 @@ func TestBareMetalMachineTemplateValidation(t *testing.T) {
 	invalidChecksum := valid.DeepCopy()
 	invalidChecksum.Spec.Template.Spec.Image.Checksum = ""
 
+	emptyHints := valid.DeepCopy()
+	emptyHints.Spec.Template.Spec.RootDeviceHints = &RootDeviceHints{}
+
+	validHints := valid.DeepCopy()
+	validHints.Spec.Template.Spec.RootDeviceHints = &RootDeviceHints{
+		SerialNumber: "S3EVNX0J300111",
+	}
+
+	ignitionFormat := valid.DeepCopy()
+	ignitionFormat.Spec.Template.Spec.BootstrapFormat = BootstrapFormatIgnition
+
+	invalidFormat := valid.DeepCopy()
+	invalidFormat.Spec.Template.Spec.BootstrapFormat = "cloud-config"
+
+	missingChecksumType := valid.DeepCopy()
+	missingChecksumType.Spec.Template.Spec.Image.ChecksumType = ""
+
+	invalidFailureDomain := valid.DeepCopy()
+	invalidFailureDomain.Spec.Template.Spec.FailureDomain = pointer.StringPtr("rack 1")
+
 	tests := []struct {
 		name      string
 		expectErr bool
 		c         *BareMetalMachineTemplate
 	}{
+		{
+			name:      "should return error when checksum URL without type",
+			expectErr: true,
+			c:         missingChecksumType,
+		},
+		{
+			name:      "should return error when failure domain not a DNS label",
+			expectErr: true,
+			c:         invalidFailureDomain,
+		},
+		{
+			name:      "should succeed when bootstrap format ignition",
+			expectErr: false,
+			c:         ignitionFormat,
+		},
+		{
+			name:      "should return error when bootstrap format invalid",
+			expectErr: true,
+			c:         invalidFormat,
+		},
+		{
+			name:      "should return error when root device hints empty",
+			expectErr: true,
+			c:         emptyHints,
+		},
+		{
+			name:      "should succeed when root device hints valid",
+			expectErr: false,
+			c:         validHints,
+		},
 		{
 			name:      "should return error when url empty",
 			expectErr: true,
@@ -89,3 +142,50 @@ func TestBareMetalMachineTemplateValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestBareMetalMachineTemplateValidationFieldPaths(t *testing.T) {
+	valid := &BareMetalMachineTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+		},
+		Spec: BareMetalMachineTemplateSpec{
+			Template: BareMetalMachineTemplateResource{
+				Spec: BareMetalMachineSpec{
+					Image: Image{
+						URL:          "http://abc.com/image",
+						Checksum:     "http://abc.com/image.md5sum",
+						ChecksumType: ChecksumTypeMD5,
+					},
+				},
+			},
+		},
+	}
+	missingURL := valid.DeepCopy()
+	missingURL.Spec.Template.Spec.Image.URL = ""
+
+	missingChecksum := valid.DeepCopy()
+	missingChecksum.Spec.Template.Spec.Image.Checksum = ""
+
+	emptyHints := valid.DeepCopy()
+	emptyHints.Spec.Template.Spec.RootDeviceHints = &RootDeviceHints{}
+
+	tests := []struct {
+		name  string
+		field string
+		c     *BareMetalMachineTemplate
+	}{
+		{name: "missing url", field: "spec.template.spec.image.url", c: missingURL},
+		{name: "missing checksum", field: "spec.template.spec.image.checksum", c: missingChecksum},
+		{name: "empty root device hints", field: "spec.template.spec.rootDeviceHints", c: emptyHints},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := tt.c.ValidateCreate()
+			g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			g.Expect(invalidFields(err)).To(ConsistOf(tt.field))
+		})
+	}
+}
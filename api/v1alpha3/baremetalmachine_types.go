@@ -17,9 +17,9 @@ limitations under the License.
 package v1alpha3
 
 import (
-	"fmt"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 )
@@ -28,6 +28,18 @@ const (
 	// MachineFinalizer allows ReconcileBareMetalMachine to clean up resources associated with BareMetalMachine before
 	// removing it from the apiserver.
 	MachineFinalizer = "baremetalmachine.infrastructure.cluster.x-k8s.io"
+
+	// BareMetalMachinePhaseProvisioning is the phase while the host is
+	// provisioned, or while its network is configured.
+	BareMetalMachinePhaseProvisioning = "Provisioning"
+	// BareMetalMachinePhaseProvisioned is the phase once the host is
+	// provisioned and, if NetworkData is set, its NICs configured.
+	BareMetalMachinePhaseProvisioned = "Provisioned"
+	// BareMetalMachinePhaseFailed is the phase once the machine can not make
+	// progress without manual intervention, e.g. when its host did not
+	// provision within the ProvisioningTimeout, or deprovision within the
+	// DeprovisionTimeout.
+	BareMetalMachinePhaseFailed = "Failed"
 )
 
 // BareMetalMachineSpec defines the desired state of BareMetalMachine
@@ -42,29 +54,186 @@ type BareMetalMachineSpec struct {
 
 	// UserData references the Secret that holds user data needed by the bare metal
 	// operator. The Namespace is optional; it will default to the BaremetalMachine's
-	// namespace if not specified.
+	// namespace if not specified. The bootstrap data of the owning Machine,
+	// when it has some, takes precedence over this value, which is then
+	// replaced and reported in the UserDataOverridden condition.
 	UserData *corev1.SecretReference `json:"userData,omitempty"`
 
 	// HostSelector specifies matching criteria for labels on BareMetalHosts.
 	// This is used to limit the set of BareMetalHost objects considered for
 	// claiming for a BaremetalMachine.
 	HostSelector HostSelector `json:"hostSelector,omitempty"`
+
+	// RootDeviceHints selects the disk the image is written to on hosts
+	// with multiple disks. They are passed to the BareMetalHost, as JSON, in
+	// its root-device-hints annotation.
+	// +optional
+	RootDeviceHints *RootDeviceHints `json:"rootDeviceHints,omitempty"`
+
+	// AutomatedCleaningMode selects whether the disks of the host are
+	// cleaned between provisions. Defaults to "metadata". It is passed to the
+	// BareMetalHost in its automated-cleaning-mode annotation.
+	// +kubebuilder:validation:Enum=metadata;disabled
+	// +optional
+	AutomatedCleaningMode AutomatedCleaningMode `json:"automatedCleaningMode,omitempty"`
+
+	// BootstrapFormat is the format of the data referenced by UserData.
+	// Defaults to "cloud-init".
+	// +kubebuilder:validation:Enum=cloud-init;ignition
+	// +optional
+	BootstrapFormat BootstrapFormat `json:"bootstrapFormat,omitempty"`
+
+	// FailureDomain restricts the hosts considered for claiming to the ones
+	// in this failure domain, e.g. a rack. Defaults to the failure domain of
+	// the Machine.
+	// +optional
+	FailureDomain *string `json:"failureDomain,omitempty"`
+
+	// NetworkData references the Secret that holds the network configuration
+	// of the host, in the OpenStack format, under the networkData key. When
+	// set, the machine is only Provisioned once the host reports an IP
+	// address on the NICs whose MAC address is given in its links. The
+	// Namespace is optional; it will default to the BaremetalMachine's
+	// namespace if not specified.
+	// +optional
+	NetworkData *corev1.SecretReference `json:"networkData,omitempty"`
+
+	// MetaData references the Secret that holds the metadata of the host, in
+	// the OpenStack format, under the metaData key. It is written to the
+	// config drive next to the user data. The Namespace is optional; it will
+	// default to the BaremetalMachine's namespace if not specified.
+	// +optional
+	MetaData *corev1.SecretReference `json:"metaData,omitempty"`
+
+	// DeprovisionTimeout is how long the host is given to deprovision when
+	// the BareMetalMachine is deleted. Once exceeded, the machine is set
+	// Failed. No limit applies if unset.
+	// +optional
+	DeprovisionTimeout *metav1.Duration `json:"deprovisionTimeout,omitempty"`
+
+	// PreferCachedImage makes the host download the image from the image
+	// cache it advertises, if any, instead of the Image URL.
+	// +optional
+	PreferCachedImage bool `json:"preferCachedImage,omitempty"`
+
+	// PowerManagementPolicy selects whether the provider powers the host on
+	// and off, or leaves it to the operators. Defaults to "automatic".
+	// +kubebuilder:validation:Enum=automatic;manual
+	// +optional
+	PowerManagementPolicy PowerManagementPolicy `json:"powerManagementPolicy,omitempty"`
+
+	// Firmware holds the firmware (BIOS) settings applied to the host, e.g.
+	// for workloads that need virtualization or SR-IOV.
+	// +optional
+	Firmware *Firmware `json:"firmware,omitempty"`
+
+	// ProvisioningTimeout is how long the host is given to provision. Once
+	// exceeded, the machine is set Failed. Defaults to
+	// DefaultProvisioningTimeout, no limit applies if zero.
+	// +optional
+	ProvisioningTimeout *metav1.Duration `json:"provisioningTimeout,omitempty"`
+
+	// MinCPUs is the minimum number of CPUs of the host. Hosts with fewer
+	// CPUs, or not inspected yet, are not claimed. No minimum applies if zero.
+	// +optional
+	MinCPUs int `json:"minCPUs,omitempty"`
+
+	// MinMemoryMiB is the minimum memory of the host, in MiB. Hosts with less
+	// memory, or not inspected yet, are not claimed. No minimum applies if
+	// zero.
+	// +optional
+	MinMemoryMiB int `json:"minMemoryMiB,omitempty"`
+
+	// MaxProvisioningRetries is the number of times the provisioning of the
+	// host is retried, with an exponential backoff, after it failed. The
+	// machine is set Failed once they are exhausted. If zero, there is no
+	// retry and the machine waits for the host, within the
+	// ProvisioningTimeout.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxProvisioningRetries int `json:"maxProvisioningRetries,omitempty"`
+
+	// DeployKernelURL is the URL of the kernel of a custom deploy (IPA)
+	// image booted to provision the host. It must be given with
+	// DeployRamdiskURL. The default deploy image is used if unset.
+	// +optional
+	DeployKernelURL string `json:"deployKernelURL,omitempty"`
+
+	// DeployRamdiskURL is the URL of the ramdisk of a custom deploy (IPA)
+	// image booted to provision the host. It must be given with
+	// DeployKernelURL. The default deploy image is used if unset.
+	// +optional
+	DeployRamdiskURL string `json:"deployRamdiskURL,omitempty"`
+
+	// BootMode is the mode the host boots in, UEFI, legacy or UEFISecureBoot.
+	// Defaults to "UEFI". Secure boot requires a host supporting it.
+	// +kubebuilder:validation:Enum=UEFI;legacy;UEFISecureBoot
+	// +optional
+	BootMode BootMode `json:"bootMode,omitempty"`
 }
 
 // IsValid returns an error if the object is not valid, otherwise nil. The
-// string representation of the error is suitable for human consumption.
+// string representation of the error is suitable for human consumption, and
+// names the paths of the missing fields.
 func (s *BareMetalMachineSpec) IsValid() error {
-	missing := []string{}
+	var allErrs field.ErrorList
+	imagePath := field.NewPath("spec", "image")
 	if s.Image.URL == "" {
-		missing = append(missing, "Image.URL")
+		allErrs = append(allErrs, field.Required(imagePath.Child("url"), ""))
 	}
 	if s.Image.Checksum == "" {
-		missing = append(missing, "Image.Checksum")
-	}
-	if len(missing) > 0 {
-		return fmt.Errorf("Missing fields from ProviderSpec: %v", missing)
+		allErrs = append(allErrs, field.Required(imagePath.Child("checksum"), ""))
 	}
-	return nil
+	return allErrs.ToAggregate()
+}
+
+// HardwareDetails summarizes the inventory of a BareMetalHost.
+type HardwareDetails struct {
+	// CPUCount is the number of CPUs of the host.
+	CPUCount int `json:"cpuCount"`
+
+	// RAMMebibytes is the memory of the host, in MiB.
+	RAMMebibytes int `json:"ramMebibytes"`
+
+	// NICCount is the number of NICs of the host.
+	NICCount int `json:"nicCount"`
+}
+
+// RemediationStatus holds the progress of the remediation of a host.
+type RemediationStatus struct {
+	// RebootCount is the number of reboots requested since the remediation
+	// started.
+	// +optional
+	RebootCount int `json:"rebootCount,omitempty"`
+
+	// LastRebootTime is when the last reboot was requested.
+	// +optional
+	LastRebootTime *metav1.Time `json:"lastRebootTime,omitempty"`
+}
+
+// ProvisioningRetryStatus holds the progress of the retries of the
+// provisioning of a host.
+type ProvisioningRetryStatus struct {
+	// Attempts is the number of retries of the provisioning so far.
+	// +optional
+	Attempts int `json:"attempts,omitempty"`
+
+	// NextRetryTime is when the provisioning is retried, while a retry is
+	// pending.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+}
+
+// RebootStatus records the last reboot of the host requested for the
+// BareMetalMachine.
+type RebootStatus struct {
+	// Reason tells why the reboot was requested.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// RequestTime is when the reboot was requested.
+	// +optional
+	RequestTime *metav1.Time `json:"requestTime,omitempty"`
 }
 
 // BareMetalMachineStatus defines the observed state of BareMetalMachine
@@ -117,6 +286,61 @@ type BareMetalMachineStatus struct {
 	// +optional
 	Addresses capi.MachineAddresses `json:"addresses,omitempty"`
 
+	// FailureDomain is the failure domain of the associated BareMetalHost.
+	// +optional
+	FailureDomain *string `json:"failureDomain,omitempty"`
+
+	// HostName is the name of the BareMetalHost claimed by the
+	// BareMetalMachine. It is cleared when the host is released.
+	// +optional
+	HostName *string `json:"hostName,omitempty"`
+
+	// PoweredOn reflects the power state of the associated BareMetalHost.
+	// It is unset while the power state of the host is unknown.
+	// +optional
+	PoweredOn *bool `json:"poweredOn,omitempty"`
+
+	// Conditions defines the current state of the BareMetalMachine.
+	// +optional
+	Conditions Conditions `json:"conditions,omitempty"`
+
+	// Remediation holds the progress of the remediation of the host, while
+	// one is requested.
+	// +optional
+	Remediation *RemediationStatus `json:"remediation,omitempty"`
+
+	// LastReboot records the last reboot of the host requested for the
+	// BareMetalMachine.
+	// +optional
+	LastReboot *RebootStatus `json:"lastReboot,omitempty"`
+
+	// ProvisioningRetry holds the progress of the retries of the
+	// provisioning of the host, once it failed.
+	// +optional
+	ProvisioningRetry *ProvisioningRetryStatus `json:"provisioningRetry,omitempty"`
+
+	// DeprovisionStartTime is when the host started deprovisioning, while
+	// the BareMetalMachine is deleted.
+	// +optional
+	DeprovisionStartTime *metav1.Time `json:"deprovisionStartTime,omitempty"`
+
+	// ImageDownloadProgress is the percentage of the image downloaded by the
+	// host, while it reports one during provisioning.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ImageDownloadProgress *int `json:"imageDownloadProgress,omitempty"`
+
+	// HardwareDetails summarizes the inventory of the associated
+	// BareMetalHost, once the host reported it.
+	// +optional
+	HardwareDetails *HardwareDetails `json:"hardwareDetails,omitempty"`
+
+	// ProvisioningStartTime is when the host started provisioning. It is
+	// cleared once the host is provisioned.
+	// +optional
+	ProvisioningStartTime *metav1.Time `json:"provisioningStartTime,omitempty"`
+
 	// Phase represents the current phase of machine actuation.
 	// E.g. Pending, Running, Terminating, Failed etc.
 	// +optional
@@ -138,6 +362,7 @@ type BareMetalMachineStatus struct {
 // +kubebuilder:printcolumn:name="ProviderID",type="string",JSONPath=".spec.providerID",description="Provider ID"
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="BaremetalMachine is Ready"
 // +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this BMMachine belongs"
+// +kubebuilder:printcolumn:name="Host",type="string",JSONPath=".status.hostName",description="BareMetalHost claimed by the BareMetalMachine"
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="BaremetalMachine current phase"
 
 // BareMetalMachine is the Schema for the baremetalmachines API
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/onsi/gomega"
@@ -161,6 +162,46 @@ func TestSpecIsValid(t *testing.T) {
 	}
 }
 
+func TestBareMetalMachineSpecDeepCopyPowerManagementPolicy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	spec := &BareMetalMachineSpec{PowerManagementPolicy: PowerManagementManual}
+
+	copied := spec.DeepCopy()
+	g.Expect(copied.PowerManagementPolicy).To(gomega.Equal(PowerManagementManual))
+
+	copied.PowerManagementPolicy = PowerManagementAutomatic
+	g.Expect(spec.PowerManagementPolicy).To(gomega.Equal(PowerManagementManual))
+}
+
+func TestFirmwareRoundTrip(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	enabled := true
+	disabled := false
+	spec := &BareMetalMachineSpec{Firmware: &Firmware{
+		VirtualizationEnabled: &enabled,
+		SriovEnabled:          &disabled,
+	}}
+
+	data, err := json.Marshal(spec.Firmware)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(string(data)).To(gomega.Equal(
+		`{"virtualizationEnabled":true,"sriovEnabled":false}`,
+	))
+
+	decoded := &Firmware{}
+	g.Expect(json.Unmarshal(data, decoded)).To(gomega.Succeed())
+	g.Expect(decoded).To(gomega.Equal(spec.Firmware))
+
+	copied := spec.DeepCopy()
+	g.Expect(copied.Firmware).To(gomega.Equal(spec.Firmware))
+	*copied.Firmware.VirtualizationEnabled = false
+	g.Expect(*spec.Firmware.VirtualizationEnabled).To(gomega.BeTrue())
+
+	data, err = json.Marshal(&BareMetalMachineSpec{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(string(data)).NotTo(gomega.ContainSubstring("firmware"))
+}
+
 func TestStorageBareMetalMachineSpec(t *testing.T) {
 	key := types.NamespacedName{
 		Name:      "foo",
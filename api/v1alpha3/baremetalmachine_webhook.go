@@ -14,8 +14,18 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/metal3-io/cluster-api-provider-baremetal/featuregate"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -33,16 +43,93 @@ func (c *BareMetalMachine) SetupWebhookWithManager(mgr ctrl.Manager) error {
 var _ webhook.Defaulter = &BareMetalMachine{}
 var _ webhook.Validator = &BareMetalMachine{}
 
+// Default sets the unset modes, formats, policy and timeout of the spec, and
+// records them in the DefaultedFieldsAnnotation.
 func (c *BareMetalMachine) Default() {
+	defaulted := []string{}
+	if c.Spec.AutomatedCleaningMode == "" {
+		c.Spec.AutomatedCleaningMode = CleaningModeMetadata
+		defaulted = append(defaulted, "spec.automatedCleaningMode")
+	}
+	if c.Spec.BootMode == "" {
+		c.Spec.BootMode = BootModeUEFI
+		defaulted = append(defaulted, "spec.bootMode")
+	}
+	if c.Spec.BootstrapFormat == "" {
+		c.Spec.BootstrapFormat = BootstrapFormatCloudInit
+		defaulted = append(defaulted, "spec.bootstrapFormat")
+	}
+	if c.Spec.Image.OSType == "" {
+		c.Spec.Image.OSType = OSTypeLinux
+		defaulted = append(defaulted, "spec.image.osType")
+	}
+	if c.Spec.PowerManagementPolicy == "" {
+		c.Spec.PowerManagementPolicy = PowerManagementAutomatic
+		defaulted = append(defaulted, "spec.powerManagementPolicy")
+	}
+	if c.Spec.ProvisioningTimeout == nil {
+		c.Spec.ProvisioningTimeout = &metav1.Duration{
+			Duration: DefaultProvisioningTimeout,
+		}
+		defaulted = append(defaulted, "spec.provisioningTimeout")
+	}
+	recordDefaultedFields(&c.ObjectMeta, defaulted)
 }
 
+// HostnamePrefix is prepended to the BareMetalMachine name to build the
+// hostname of the host. It is accounted for when validating the name length.
+var HostnamePrefix = ""
+
+// DefaultProvisioningTimeout is the ProvisioningTimeout set on the
+// BareMetalMachines that do not give one. It is generous, since provisioning
+// includes the image download and the inspection of the host.
+var DefaultProvisioningTimeout = 2 * time.Hour
+
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (c *BareMetalMachine) ValidateCreate() error {
-	return c.validate()
+	if err := c.validate(); err != nil {
+		return err
+	}
+	// Only checked on creation, the HostAnnotation is set on update once a
+	// host matching the HostSelector is claimed
+	if allErrs := validateHostPinning(c.Annotations, c.Spec.HostSelector,
+		field.NewPath("spec", "hostSelector"),
+	); len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("BareMetalMachine").GroupKind(), c.Name, allErrs)
+	}
+	// Disabled by default, since they perform network I/O from the webhook
+	if featuregate.Enabled(featuregate.ImageURLDenyList) {
+		if err := c.validateImageNetwork(); err != nil {
+			return err
+		}
+	}
+	if featuregate.Enabled(featuregate.ImageReachabilityCheck) {
+		return c.validateImageReachability()
+	}
+	return nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+// On top of the checks done on creation, it rejects clearing the Image URL or
+// Checksum, and changing the RootDeviceHints, of a BareMetalMachine that is
+// provisioned, i.e. has a ProviderID.
 func (c *BareMetalMachine) ValidateUpdate(old runtime.Object) error {
+	oldMachine, ok := old.(*BareMetalMachine)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf(
+			"expected a BareMetalMachine but got a %T", old,
+		))
+	}
+	// Checked first, as validate only reports the cleared fields as required
+	allErrs := validateImageKept(oldMachine.Spec, c.Spec,
+		field.NewPath("spec", "image"),
+	)
+	allErrs = append(allErrs, validateRootDeviceHintsKept(oldMachine.Spec,
+		c.Spec, field.NewPath("spec", "rootDeviceHints"),
+	)...)
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("BareMetalMachine").GroupKind(), c.Name, allErrs)
+	}
 	return c.validate()
 }
 
@@ -52,28 +139,631 @@ func (c *BareMetalMachine) ValidateDelete() error {
 }
 
 func (c *BareMetalMachine) validate() error {
+	allErrs := validateHostnameLength(c.Name, field.NewPath("metadata", "name"))
+	allErrs = append(allErrs, validateProviderIDFormat(
+		c.Annotations, field.NewPath("metadata", "annotations"),
+	)...)
+	allErrs = append(allErrs, c.Spec.validate(c.Namespace,
+		field.NewPath("spec"),
+	)...)
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("BareMetalMachine").GroupKind(), c.Name, allErrs)
+}
+
+// validate returns the errors found in the spec of an object of namespace,
+// with their path under fldPath. It is shared by the BareMetalMachine and
+// BareMetalMachineTemplate webhooks.
+func (s *BareMetalMachineSpec) validate(namespace string, fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
-	if len(c.Spec.Image.URL) == 0 {
+	imagePath := fldPath.Child("image")
+
+	if len(s.Image.URL) == 0 {
+		allErrs = append(allErrs, field.Required(imagePath.Child("url"), ""))
+	}
+
+	allErrs = append(allErrs, validateImageURL(
+		s.Image.URL, imagePath.Child("url"),
+	)...)
+
+	if len(s.Image.Checksum) == 0 {
+		allErrs = append(allErrs, field.Required(imagePath.Child("checksum"), ""))
+	}
+
+	allErrs = append(allErrs, validateChecksumType(
+		s.Image, imagePath.Child("checksumType"),
+	)...)
+
+	allErrs = append(allErrs, validateChecksumDigest(
+		s.Image, imagePath.Child("checksum"),
+	)...)
+
+	allErrs = append(allErrs, validateOSType(
+		s.Image.OSType, imagePath.Child("osType"),
+	)...)
+
+	allErrs = append(allErrs, validateImageSignature(s.Image, namespace,
+		imagePath,
+	)...)
+
+	allErrs = append(allErrs, validateRootDeviceHints(
+		s.RootDeviceHints, fldPath.Child("rootDeviceHints"),
+	)...)
+
+	allErrs = append(allErrs, validateAutomatedCleaningMode(
+		s.AutomatedCleaningMode, fldPath.Child("automatedCleaningMode"),
+	)...)
+
+	allErrs = append(allErrs, validateBootstrapFormat(
+		s.BootstrapFormat, fldPath.Child("bootstrapFormat"),
+	)...)
+
+	allErrs = append(allErrs, validateBootMode(
+		s.BootMode, fldPath.Child("bootMode"),
+	)...)
+
+	allErrs = append(allErrs, validatePowerManagementPolicy(
+		s.PowerManagementPolicy, fldPath.Child("powerManagementPolicy"),
+	)...)
+
+	allErrs = append(allErrs, validateFailureDomain(
+		s.FailureDomain, fldPath.Child("failureDomain"),
+	)...)
+
+	if s.DeprovisionTimeout != nil && s.DeprovisionTimeout.Duration < 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath.Child("deprovisionTimeout"),
+				s.DeprovisionTimeout.Duration.String(),
+				"must not be negative",
+			),
+		)
+	}
+
+	if s.ProvisioningTimeout != nil && s.ProvisioningTimeout.Duration < 0 {
 		allErrs = append(
 			allErrs,
 			field.Invalid(
-				field.NewPath("spec", "Image", "URL"),
+				fldPath.Child("provisioningTimeout"),
+				s.ProvisioningTimeout.Duration.String(),
+				"must not be negative",
+			),
+		)
+	}
+
+	if s.MinCPUs < 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath.Child("minCPUs"),
+				s.MinCPUs,
+				"must not be negative",
+			),
+		)
+	}
+
+	if s.MinMemoryMiB < 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath.Child("minMemoryMiB"),
+				s.MinMemoryMiB,
+				"must not be negative",
+			),
+		)
+	}
+
+	if s.MaxProvisioningRetries < 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath.Child("maxProvisioningRetries"),
+				s.MaxProvisioningRetries,
+				"must not be negative",
+			),
+		)
+	}
+
+	allErrs = append(allErrs, validateSecretReference(
+		s.MetaData, fldPath.Child("metaData"),
+	)...)
+
+	allErrs = append(allErrs, validateDeployImage(
+		s.DeployKernelURL, s.DeployRamdiskURL, fldPath,
+	)...)
+
+	return allErrs
+}
+
+// validateHostnameLength checks that the hostname derived from the name, with
+// HostnamePrefix prepended, fits in a DNS label.
+func validateHostnameLength(name string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(HostnamePrefix)+len(name) > validation.DNS1123LabelMaxLength {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath,
+				name,
+				fmt.Sprintf("must be no more than %d characters, including the hostname prefix %q",
+					validation.DNS1123LabelMaxLength, HostnamePrefix,
+				),
+			),
+		)
+	}
+	return allErrs
+}
+
+// validateImageURL checks that the image URL parses once normalized, and that
+// tftp URLs give both a host and a path.
+func validateImageURL(rawURL string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if rawURL == "" {
+		return allErrs
+	}
+
+	u, err := url.Parse(NormalizeImageURL(rawURL))
+	if err != nil {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath,
+				rawURL,
+				err.Error(),
+			),
+		)
+		return allErrs
+	}
+
+	if u.Scheme == ImageURLSchemeTFTP && (u.Host == "" || strings.Trim(u.Path, "/") == "") {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath,
+				rawURL,
+				"tftp URL requires a host and a path",
+			),
+		)
+	}
+
+	return allErrs
+}
+
+// validateChecksumType checks that the checksum type is one of the known
+// ones, and that it is given when the checksum is a URL. It may be omitted for
+// an inline digest.
+func validateChecksumType(image Image, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch image.ChecksumType {
+	case "":
+		if u, err := url.Parse(image.Checksum); err == nil && u.Scheme != "" {
+			allErrs = append(
+				allErrs,
+				field.Required(
+					fldPath,
+					"is required when Checksum is a URL",
+				),
+			)
+		}
+	case ChecksumTypeMD5, ChecksumTypeSHA256, ChecksumTypeSHA512:
+	default:
+		allErrs = append(
+			allErrs,
+			field.NotSupported(
+				fldPath,
+				image.ChecksumType,
+				[]string{
+					string(ChecksumTypeMD5), string(ChecksumTypeSHA256),
+					string(ChecksumTypeSHA512),
+				},
+			),
+		)
+	}
+	return allErrs
+}
+
+// checksumDigestLengths are the lengths, in hexadecimal characters, of the
+// digests of each checksum type.
+var checksumDigestLengths = map[ChecksumType]int{
+	ChecksumTypeMD5:    32,
+	ChecksumTypeSHA256: 64,
+	ChecksumTypeSHA512: 128,
+}
+
+// validateChecksumDigest checks that an inline digest is lowercase hexadecimal
+// and of the length of its checksum type. Checksum URLs and inline digests
+// without a type are not checked.
+func validateChecksumDigest(image Image, fldPath *field.Path) field.ErrorList {
+	length, ok := checksumDigestLengths[image.ChecksumType]
+	if !ok || image.Checksum == "" {
+		return nil
+	}
+	if u, err := url.Parse(image.Checksum); err == nil && u.Scheme != "" {
+		return nil
+	}
+
+	for _, r := range image.Checksum {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return field.ErrorList{field.Invalid(fldPath, image.Checksum,
+				"must be a lowercase hexadecimal digest",
+			)}
+		}
+	}
+	if len(image.Checksum) != length {
+		return field.ErrorList{field.Invalid(fldPath, image.Checksum,
+			fmt.Sprintf("must be %d characters long for a %s digest",
+				length, image.ChecksumType,
+			),
+		)}
+	}
+	return nil
+}
+
+// validateDeployImage checks that DeployKernelURL and DeployRamdiskURL are
+// given together, and that both are http(s) URLs.
+func validateDeployImage(kernelURL, ramdiskURL string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	kernelPath := fldPath.Child("deployKernelURL")
+	ramdiskPath := fldPath.Child("deployRamdiskURL")
+
+	switch {
+	case kernelURL == "" && ramdiskURL == "":
+		return allErrs
+	case kernelURL == "":
+		allErrs = append(allErrs,
+			field.Required(kernelPath, "is required with deployRamdiskURL"),
+		)
+	case ramdiskURL == "":
+		allErrs = append(allErrs,
+			field.Required(ramdiskPath, "is required with deployKernelURL"),
+		)
+	}
+
+	for _, deployURL := range []struct {
+		value string
+		path  *field.Path
+	}{
+		{kernelURL, kernelPath},
+		{ramdiskURL, ramdiskPath},
+	} {
+		if deployURL.value == "" {
+			continue
+		}
+		u, err := url.Parse(deployURL.value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					deployURL.path,
+					deployURL.value,
+					"must be an http(s) URL",
+				),
+			)
+		}
+	}
+
+	return allErrs
+}
+
+// validateImageSignature checks that SignatureURL and SignatureKeyRef are
+// given together, that the signature is served over http(s) and that the key
+// reference names a Secret of namespace.
+func validateImageSignature(image Image, namespace string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	urlPath := fldPath.Child("signatureURL")
+	keyPath := fldPath.Child("signatureKeyRef")
+
+	switch {
+	case image.SignatureURL == "" && image.SignatureKeyRef == nil:
+		return allErrs
+	case image.SignatureURL == "":
+		allErrs = append(allErrs,
+			field.Required(urlPath, "is required with signatureKeyRef"),
+		)
+	case image.SignatureKeyRef == nil:
+		allErrs = append(allErrs,
+			field.Required(keyPath, "is required with signatureURL"),
+		)
+	}
+
+	if image.SignatureURL != "" {
+		u, err := url.Parse(image.SignatureURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					urlPath,
+					image.SignatureURL,
+					"must be an http(s) URL",
+				),
+			)
+		}
+	}
+
+	allErrs = append(allErrs, validateSecretReference(
+		image.SignatureKeyRef, keyPath,
+	)...)
+
+	// The keys trusted by a machine are the ones of its own namespace
+	if image.SignatureKeyRef != nil && image.SignatureKeyRef.Namespace != "" &&
+		image.SignatureKeyRef.Namespace != namespace {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				keyPath.Child("namespace"),
+				image.SignatureKeyRef.Namespace,
+				"must be the namespace of the BareMetalMachine",
+			),
+		)
+	}
+
+	return allErrs
+}
+
+// validateSecretReference checks that ref, if set, names a Secret, and that
+// its namespace, if set, is a valid namespace name.
+func validateSecretReference(ref *corev1.SecretReference, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if ref == nil {
+		return allErrs
+	}
+
+	if ref.Name == "" {
+		allErrs = append(allErrs,
+			field.Required(fldPath.Child("name"), "must name a Secret"),
+		)
+	} else if errs := validation.IsDNS1123Subdomain(ref.Name); len(errs) > 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath.Child("name"),
+				ref.Name,
+				fmt.Sprintf("must be a Secret name: %v", errs),
+			),
+		)
+	}
+
+	if ref.Namespace != "" {
+		if errs := validation.IsDNS1123Label(ref.Namespace); len(errs) > 0 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					fldPath.Child("namespace"),
+					ref.Namespace,
+					fmt.Sprintf("must be a namespace name: %v", errs),
+				),
+			)
+		}
+	}
+
+	return allErrs
+}
+
+// validateRootDeviceHints checks that at least one hint is given, if the hints
+// are set, and that the minimum size is not negative.
+func validateRootDeviceHints(hints *RootDeviceHints, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if hints == nil {
+		return allErrs
+	}
+
+	if hints.IsEmpty() {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath,
+				hints,
+				"at least one hint is required",
+			),
+		)
+	}
+
+	if hints.MinSizeGigabytes != nil && *hints.MinSizeGigabytes <= 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath.Child("minSizeGigabytes"),
+				*hints.MinSizeGigabytes,
+				"must be positive",
+			),
+		)
+	}
+
+	return allErrs
+}
+
+// validateAutomatedCleaningMode checks that the mode is one of the known ones.
+// An empty mode is accepted, it is defaulted on the BareMetalMachine.
+func validateAutomatedCleaningMode(mode AutomatedCleaningMode, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch mode {
+	case "", CleaningModeMetadata, CleaningModeDisabled:
+	default:
+		allErrs = append(
+			allErrs,
+			field.NotSupported(
+				fldPath,
+				mode,
+				[]string{string(CleaningModeMetadata), string(CleaningModeDisabled)},
+			),
+		)
+	}
+	return allErrs
+}
+
+// validatePowerManagementPolicy checks that the policy is one of the known
+// ones. An empty policy is accepted, it is defaulted on the BareMetalMachine.
+func validatePowerManagementPolicy(policy PowerManagementPolicy, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch policy {
+	case "", PowerManagementAutomatic, PowerManagementManual:
+	default:
+		allErrs = append(
+			allErrs,
+			field.NotSupported(
+				fldPath,
+				policy,
+				[]string{string(PowerManagementAutomatic), string(PowerManagementManual)},
+			),
+		)
+	}
+	return allErrs
+}
+
+// validateBootMode checks that the boot mode is one of the known ones. An
+// empty mode is accepted, it is defaulted on the BareMetalMachine.
+func validateBootMode(mode BootMode, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch mode {
+	case "", BootModeUEFI, BootModeLegacy, BootModeUEFISecureBoot:
+	default:
+		allErrs = append(
+			allErrs,
+			field.NotSupported(
+				fldPath,
+				mode,
+				[]string{
+					string(BootModeUEFI), string(BootModeLegacy),
+					string(BootModeUEFISecureBoot),
+				},
+			),
+		)
+	}
+	return allErrs
+}
+
+// validateProviderIDFormat checks that the ProviderIDFormatAnnotation, if
+// set, holds one of the known formats.
+func validateProviderIDFormat(annotations map[string]string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	format, ok := annotations[ProviderIDFormatAnnotation]
+	if !ok {
+		return allErrs
+	}
+	switch ProviderIDFormat(format) {
+	case ProviderIDFormatLegacy, ProviderIDFormatCurrent:
+	default:
+		allErrs = append(
+			allErrs,
+			field.NotSupported(
+				fldPath.Key(ProviderIDFormatAnnotation),
+				format,
+				[]string{string(ProviderIDFormatLegacy), string(ProviderIDFormatCurrent)},
+			),
+		)
+	}
+	return allErrs
+}
+
+// validateHostPinning checks that a BareMetalMachine pinned to a host with
+// the HostAnnotation does not also give a HostSelector, which would be
+// ignored.
+func validateHostPinning(annotations map[string]string, selector HostSelector,
+	fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if _, ok := annotations[HostAnnotation]; !ok {
+		return allErrs
+	}
+	if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		return allErrs
+	}
+	allErrs = append(
+		allErrs,
+		field.Forbidden(
+			fldPath,
+			fmt.Sprintf("must not be set when the %s annotation pins the BareMetalMachine to a host, the selector would be ignored: remove one of them",
+				HostAnnotation,
+			),
+		),
+	)
+	return allErrs
+}
+
+// validateOSType checks that the OS type is one of the known ones. An empty
+// type is accepted, it is defaulted on the BareMetalMachine.
+func validateOSType(osType OSType, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch osType {
+	case "", OSTypeLinux, OSTypeWindows:
+	default:
+		allErrs = append(
+			allErrs,
+			field.NotSupported(
+				fldPath,
+				osType,
+				[]string{string(OSTypeLinux), string(OSTypeWindows)},
+			),
+		)
+	}
+	return allErrs
+}
+
+// validateBootstrapFormat checks that the format is one of the known ones.
+// An empty format is accepted, it is defaulted on the BareMetalMachine.
+func validateBootstrapFormat(format BootstrapFormat, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch format {
+	case "", BootstrapFormatCloudInit, BootstrapFormatIgnition:
+	default:
+		allErrs = append(
+			allErrs,
+			field.NotSupported(
+				fldPath,
+				format,
+				[]string{string(BootstrapFormatCloudInit), string(BootstrapFormatIgnition)},
+			),
+		)
+	}
+	return allErrs
+}
+
+// validateFailureDomain checks that the failure domain, if set, is a DNS
+// label.
+func validateFailureDomain(failureDomain *string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if failureDomain == nil {
+		return allErrs
+	}
+
+	for _, msg := range validation.IsDNS1123Label(*failureDomain) {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				fldPath,
+				*failureDomain,
+				msg,
+			),
+		)
+	}
+	return allErrs
+}
+
+// validateImageReachability fails if the image or its checksum return 404.
+func (c *BareMetalMachine) validateImageReachability() error {
+	var allErrs field.ErrorList
+	if imageReachability.isNotFound(c.Spec.Image.URL) {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				field.NewPath("spec", "image", "url"),
 				c.Spec.Image.URL,
-				"is required",
+				"is not found",
 			),
 		)
 	}
 
-	if len(c.Spec.Image.Checksum) == 0 {
+	if imageReachability.isNotFound(c.Spec.Image.Checksum) {
 		allErrs = append(
 			allErrs,
 			field.Invalid(
-				field.NewPath("spec", "Image", "Checksum"),
+				field.NewPath("spec", "image", "checksum"),
 				c.Spec.Image.Checksum,
-				"is required",
+				"is not found",
 			),
 		)
-
 	}
 
 	if len(allErrs) == 0 {
@@ -81,3 +771,59 @@ func (c *BareMetalMachine) validate() error {
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("BareMetalMachine").GroupKind(), c.Name, allErrs)
 }
+
+// validateImageNetwork fails if the image URL host resolves into one of the
+// DeniedImageNetworks.
+func (c *BareMetalMachine) validateImageNetwork() error {
+	network := deniedImageNetwork(c.Spec.Image.URL)
+	if network == nil {
+		return nil
+	}
+	allErrs := field.ErrorList{
+		field.Invalid(
+			field.NewPath("spec", "image", "url"),
+			c.Spec.Image.URL,
+			fmt.Sprintf("host resolves into the denied network %s", network),
+		),
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("BareMetalMachine").GroupKind(), c.Name, allErrs)
+}
+
+// validateImageKept returns an error for each of the Image URL and Checksum
+// set in oldSpec and cleared in newSpec, if oldSpec has a ProviderID. The
+// image can be changed freely until the machine is provisioned.
+func validateImageKept(oldSpec, newSpec BareMetalMachineSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if oldSpec.ProviderID == nil || *oldSpec.ProviderID == "" {
+		return allErrs
+	}
+
+	if oldSpec.Image.URL != "" && newSpec.Image.URL == "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("url"),
+			"cannot be cleared once the BareMetalMachine is provisioned",
+		))
+	}
+	if oldSpec.Image.Checksum != "" && newSpec.Image.Checksum == "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("checksum"),
+			"cannot be cleared once the BareMetalMachine is provisioned",
+		))
+	}
+	return allErrs
+}
+
+// validateRootDeviceHintsKept returns an error if the RootDeviceHints differ
+// between oldSpec and newSpec, and oldSpec has a ProviderID: the disk of a
+// provisioned host only changes when it is reprovisioned.
+func validateRootDeviceHintsKept(oldSpec, newSpec BareMetalMachineSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if oldSpec.ProviderID == nil || *oldSpec.ProviderID == "" {
+		return allErrs
+	}
+
+	if !apiequality.Semantic.DeepEqual(oldSpec.RootDeviceHints, newSpec.RootDeviceHints) {
+		allErrs = append(allErrs, field.Forbidden(fldPath,
+			"cannot be changed once the BareMetalMachine is provisioned",
+		))
+	}
+	return allErrs
+}
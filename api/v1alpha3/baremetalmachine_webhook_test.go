@@ -14,14 +14,25 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/metal3-io/cluster-api-provider-baremetal/featuregate"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 )
 
 func TestBareMetalMachineDefault(t *testing.T) {
-	// No-op because we do not default anything in BMM yet
+	g := NewWithT(t)
 	c := &BareMetalMachine{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "fooboo",
@@ -29,6 +40,64 @@ func TestBareMetalMachineDefault(t *testing.T) {
 		Spec: BareMetalMachineSpec{},
 	}
 	c.Default()
+
+	g.Expect(c.Spec.AutomatedCleaningMode).To(Equal(CleaningModeMetadata))
+	g.Expect(c.Spec.BootstrapFormat).To(Equal(BootstrapFormatCloudInit))
+	g.Expect(c.Spec.BootMode).To(Equal(BootModeUEFI))
+	g.Expect(c.Spec.PowerManagementPolicy).To(Equal(PowerManagementAutomatic))
+	g.Expect(c.Spec.Image.OSType).To(Equal(OSTypeLinux))
+	g.Expect(c.Spec.ProvisioningTimeout).To(Equal(
+		&metav1.Duration{Duration: DefaultProvisioningTimeout},
+	))
+	g.Expect(DefaultedFields(c)).To(ConsistOf(
+		"spec.automatedCleaningMode", "spec.bootMode", "spec.bootstrapFormat",
+		"spec.image.osType", "spec.powerManagementPolicy",
+		"spec.provisioningTimeout",
+	))
+
+	c.Spec.AutomatedCleaningMode = CleaningModeDisabled
+	c.Spec.BootMode = BootModeLegacy
+	c.Spec.PowerManagementPolicy = PowerManagementManual
+	c.Spec.Image.OSType = OSTypeWindows
+	c.Spec.ProvisioningTimeout = &metav1.Duration{}
+	c.Default()
+
+	g.Expect(c.Spec.AutomatedCleaningMode).To(Equal(CleaningModeDisabled))
+	g.Expect(c.Spec.BootMode).To(Equal(BootModeLegacy))
+	g.Expect(c.Spec.PowerManagementPolicy).To(Equal(PowerManagementManual))
+	g.Expect(c.Spec.Image.OSType).To(Equal(OSTypeWindows))
+	g.Expect(c.Spec.ProvisioningTimeout).To(Equal(&metav1.Duration{}))
+}
+
+func TestBareMetalMachineDefaultedFields(t *testing.T) {
+	g := NewWithT(t)
+	c := &BareMetalMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "fooboo",
+		},
+		Spec: BareMetalMachineSpec{
+			Image:                 Image{OSType: OSTypeLinux},
+			AutomatedCleaningMode: CleaningModeDisabled,
+			BootMode:              BootModeUEFI,
+			BootstrapFormat:       BootstrapFormatIgnition,
+			PowerManagementPolicy: PowerManagementManual,
+			ProvisioningTimeout:   &metav1.Duration{},
+		},
+	}
+
+	// Nothing is defaulted, nothing is recorded
+	c.Default()
+	g.Expect(c.Annotations).NotTo(HaveKey(DefaultedFieldsAnnotation))
+
+	// The fields defaulted earlier are kept on update
+	c.Annotations = map[string]string{
+		DefaultedFieldsAnnotation: "spec.provisioningTimeout",
+	}
+	c.Spec.BootstrapFormat = ""
+	c.Default()
+	g.Expect(c.Annotations[DefaultedFieldsAnnotation]).To(Equal(
+		"spec.bootstrapFormat,spec.provisioningTimeout",
+	))
 }
 
 func TestBareMetalMachineValidation(t *testing.T) {
@@ -38,8 +107,9 @@ func TestBareMetalMachineValidation(t *testing.T) {
 		},
 		Spec: BareMetalMachineSpec{
 			Image: Image{
-				URL:      "http://abc.com/image",
-				Checksum: "http://abc.com/image.md5sum",
+				URL:          "http://abc.com/image",
+				Checksum:     "http://abc.com/image.md5sum",
+				ChecksumType: ChecksumTypeMD5,
 			},
 		},
 	}
@@ -49,11 +119,281 @@ func TestBareMetalMachineValidation(t *testing.T) {
 	invalidChecksum := valid.DeepCopy()
 	invalidChecksum.Spec.Image.Checksum = ""
 
+	tftpURL := valid.DeepCopy()
+	tftpURL.Spec.Image.URL = "tftp://172.22.0.1/images/disk.img"
+
+	bareTFTPURL := valid.DeepCopy()
+	bareTFTPURL.Spec.Image.URL = "172.22.0.1:/images/disk.img"
+
+	tftpURLWithoutPath := valid.DeepCopy()
+	tftpURLWithoutPath.Spec.Image.URL = "tftp://172.22.0.1/"
+
+	emptyHints := valid.DeepCopy()
+	emptyHints.Spec.RootDeviceHints = &RootDeviceHints{}
+
+	legacyProviderID := valid.DeepCopy()
+	legacyProviderID.Annotations = map[string]string{
+		ProviderIDFormatAnnotation: string(ProviderIDFormatLegacy),
+	}
+
+	windowsImage := valid.DeepCopy()
+	windowsImage.Spec.Image.OSType = OSTypeWindows
+
+	invalidOSType := valid.DeepCopy()
+	invalidOSType.Spec.Image.OSType = "macos"
+
+	withMinimums := valid.DeepCopy()
+	withMinimums.Spec.MinCPUs = 16
+	withMinimums.Spec.MinMemoryMiB = 65536
+
+	negativeSizeHints := valid.DeepCopy()
+	negativeSizeHints.Spec.RootDeviceHints = &RootDeviceHints{
+		DeviceName:       "/dev/sda",
+		MinSizeGigabytes: intPtr(-1),
+	}
+
+	zeroSizeHints := valid.DeepCopy()
+	zeroSizeHints.Spec.RootDeviceHints = &RootDeviceHints{
+		DeviceName:       "/dev/sda",
+		MinSizeGigabytes: intPtr(0),
+	}
+
+	validHints := valid.DeepCopy()
+	validHints.Spec.RootDeviceHints = &RootDeviceHints{
+		DeviceName:       "/dev/sda",
+		MinSizeGigabytes: intPtr(100),
+	}
+
+	rotationalHint := valid.DeepCopy()
+	rotationalHint.Spec.RootDeviceHints = &RootDeviceHints{
+		Rotational: pointer.BoolPtr(false),
+	}
+
+	cleaningDisabled := valid.DeepCopy()
+	cleaningDisabled.Spec.AutomatedCleaningMode = CleaningModeDisabled
+
+	invalidCleaning := valid.DeepCopy()
+	invalidCleaning.Spec.AutomatedCleaningMode = "full"
+
+	missingChecksumType := valid.DeepCopy()
+	missingChecksumType.Spec.Image.ChecksumType = ""
+
+	inlineChecksum := valid.DeepCopy()
+	inlineChecksum.Spec.Image.Checksum = "97830b21ed272a3d854615beb54cf004"
+	inlineChecksum.Spec.Image.ChecksumType = ""
+
+	invalidChecksumType := valid.DeepCopy()
+	invalidChecksumType.Spec.Image.ChecksumType = "crc32"
+
+	digest := func(checksumType ChecksumType, checksum string) *BareMetalMachine {
+		c := valid.DeepCopy()
+		c.Spec.Image.Checksum = checksum
+		c.Spec.Image.ChecksumType = checksumType
+		return c
+	}
+	md5Digest := strings.Repeat("0123456789abcdef", 2)
+	sha256Digest := strings.Repeat("0123456789abcdef", 4)
+	sha512Digest := strings.Repeat("0123456789abcdef", 8)
+
+	validFailureDomain := valid.DeepCopy()
+	validFailureDomain.Spec.FailureDomain = pointer.StringPtr("rack-1")
+
+	emptyFailureDomain := valid.DeepCopy()
+	emptyFailureDomain.Spec.FailureDomain = pointer.StringPtr("")
+
+	invalidFailureDomain := valid.DeepCopy()
+	invalidFailureDomain.Spec.FailureDomain = pointer.StringPtr("Rack_1")
+
+	cloudInitFormat := valid.DeepCopy()
+	cloudInitFormat.Spec.BootstrapFormat = BootstrapFormatCloudInit
+
+	ignitionFormat := valid.DeepCopy()
+	ignitionFormat.Spec.BootstrapFormat = BootstrapFormatIgnition
+
+	invalidFormat := valid.DeepCopy()
+	invalidFormat.Spec.BootstrapFormat = "cloud-config"
+
+	manualPower := valid.DeepCopy()
+	manualPower.Spec.PowerManagementPolicy = PowerManagementManual
+
+	invalidPower := valid.DeepCopy()
+	invalidPower.Spec.PowerManagementPolicy = "external"
+
 	tests := []struct {
 		name      string
 		expectErr bool
 		c         *BareMetalMachine
 	}{
+		{
+			name:      "should return error when checksum URL without type",
+			expectErr: true,
+			c:         missingChecksumType,
+		},
+		{
+			name:      "should succeed when inline checksum without type",
+			expectErr: false,
+			c:         inlineChecksum,
+		},
+		{
+			name:      "should return error when checksum type unknown",
+			expectErr: true,
+			c:         invalidChecksumType,
+		},
+		{
+			name:      "should succeed when md5 digest valid",
+			expectErr: false,
+			c:         digest(ChecksumTypeMD5, md5Digest),
+		},
+		{
+			name:      "should return error when md5 digest has a sha256 length",
+			expectErr: true,
+			c:         digest(ChecksumTypeMD5, sha256Digest),
+		},
+		{
+			name:      "should return error when md5 digest uppercase",
+			expectErr: true,
+			c:         digest(ChecksumTypeMD5, strings.ToUpper(md5Digest)),
+		},
+		{
+			name:      "should succeed when sha256 digest valid",
+			expectErr: false,
+			c:         digest(ChecksumTypeSHA256, sha256Digest),
+		},
+		{
+			name:      "should return error when sha256 digest too short",
+			expectErr: true,
+			c:         digest(ChecksumTypeSHA256, md5Digest),
+		},
+		{
+			name:      "should return error when sha256 digest not hexadecimal",
+			expectErr: true,
+			c:         digest(ChecksumTypeSHA256, strings.Repeat("g", 64)),
+		},
+		{
+			name:      "should succeed when sha512 digest valid",
+			expectErr: false,
+			c:         digest(ChecksumTypeSHA512, sha512Digest),
+		},
+		{
+			name:      "should return error when sha512 digest has a sha256 length",
+			expectErr: true,
+			c:         digest(ChecksumTypeSHA512, sha256Digest),
+		},
+		{
+			name:      "should return error when sha512 digest uppercase",
+			expectErr: true,
+			c:         digest(ChecksumTypeSHA512, strings.ToUpper(sha512Digest)),
+		},
+		{
+			name:      "should succeed when failure domain valid",
+			expectErr: false,
+			c:         validFailureDomain,
+		},
+		{
+			name:      "should return error when failure domain empty",
+			expectErr: true,
+			c:         emptyFailureDomain,
+		},
+		{
+			name:      "should return error when failure domain not a DNS label",
+			expectErr: true,
+			c:         invalidFailureDomain,
+		},
+		{
+			name:      "should succeed when bootstrap format cloud-init",
+			expectErr: false,
+			c:         cloudInitFormat,
+		},
+		{
+			name:      "should succeed when bootstrap format ignition",
+			expectErr: false,
+			c:         ignitionFormat,
+		},
+		{
+			name:      "should return error when bootstrap format invalid",
+			expectErr: true,
+			c:         invalidFormat,
+		},
+		{
+			name:      "should succeed when power management manual",
+			expectErr: false,
+			c:         manualPower,
+		},
+		{
+			name:      "should return error when power management policy invalid",
+			expectErr: true,
+			c:         invalidPower,
+		},
+		{
+			name:      "should succeed when cleaning mode disabled",
+			expectErr: false,
+			c:         cleaningDisabled,
+		},
+		{
+			name:      "should return error when cleaning mode invalid",
+			expectErr: true,
+			c:         invalidCleaning,
+		},
+		{
+			name:      "should return error when root device hints empty",
+			expectErr: true,
+			c:         emptyHints,
+		},
+		{
+			name:      "should return error when root device min size negative",
+			expectErr: true,
+			c:         negativeSizeHints,
+		},
+		{
+			name:      "should succeed with the legacy provider ID format",
+			expectErr: false,
+			c:         legacyProviderID,
+		},
+		{
+			name:      "should succeed with a windows image",
+			expectErr: false,
+			c:         windowsImage,
+		},
+		{
+			name:      "should return error with an unknown OS type",
+			expectErr: true,
+			c:         invalidOSType,
+		},
+		{
+			name:      "should succeed when capacity minimums set",
+			expectErr: false,
+			c:         withMinimums,
+		},
+		{
+			name:      "should return error when root device min size zero",
+			expectErr: true,
+			c:         zeroSizeHints,
+		},
+		{
+			name:      "should succeed when root device hints valid",
+			expectErr: false,
+			c:         validHints,
+		},
+		{
+			name:      "should succeed when only rotational hint given",
+			expectErr: false,
+			c:         rotationalHint,
+		},
+		{
+			name:      "should succeed when url uses tftp",
+			expectErr: false,
+			c:         tftpURL,
+		},
+		{
+			name:      "should succeed when url is a bare tftp path",
+			expectErr: false,
+			c:         bareTFTPURL,
+		},
+		{
+			name:      "should return error when tftp url has no path",
+			expectErr: true,
+			c:         tftpURLWithoutPath,
+		},
 		{
 			name:      "should return error when url empty",
 			expectErr: true,
@@ -77,11 +417,954 @@ func TestBareMetalMachineValidation(t *testing.T) {
 
 			if tt.expectErr {
 				g.Expect(tt.c.ValidateCreate()).NotTo(Succeed())
-				g.Expect(tt.c.ValidateUpdate(nil)).NotTo(Succeed())
+				g.Expect(tt.c.ValidateUpdate(tt.c)).NotTo(Succeed())
 			} else {
 				g.Expect(tt.c.ValidateCreate()).To(Succeed())
-				g.Expect(tt.c.ValidateUpdate(nil)).To(Succeed())
+				g.Expect(tt.c.ValidateUpdate(tt.c)).To(Succeed())
+			}
+		})
+	}
+}
+
+// invalidFields returns the paths of the fields rejected by an Invalid error.
+func invalidFields(err error) []string {
+	statusErr, ok := err.(*apierrors.StatusError)
+	if !ok || statusErr.ErrStatus.Details == nil {
+		return nil
+	}
+	fields := []string{}
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+	return fields
+}
+
+func TestBareMetalMachineValidationFieldPaths(t *testing.T) {
+	valid := &BareMetalMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+		},
+		Spec: BareMetalMachineSpec{
+			Image: Image{
+				URL:          "http://abc.com/image",
+				Checksum:     "http://abc.com/image.md5sum",
+				ChecksumType: ChecksumTypeMD5,
+			},
+		},
+	}
+	missingURL := valid.DeepCopy()
+	missingURL.Spec.Image.URL = ""
+
+	invalidURL := valid.DeepCopy()
+	invalidURL.Spec.Image.URL = "tftp://172.22.0.1/"
+
+	missingChecksum := valid.DeepCopy()
+	missingChecksum.Spec.Image.Checksum = ""
+
+	invalidChecksumType := valid.DeepCopy()
+	invalidChecksumType.Spec.Image.ChecksumType = "crc32"
+
+	invalidDigest := valid.DeepCopy()
+	invalidDigest.Spec.Image.Checksum = "97830B21ED272A3D854615BEB54CF004"
+
+	negativeSizeHints := valid.DeepCopy()
+	negativeSizeHints.Spec.RootDeviceHints = &RootDeviceHints{
+		DeviceName:       "/dev/sda",
+		MinSizeGigabytes: intPtr(-1),
+	}
+
+	zeroSizeHints := valid.DeepCopy()
+	zeroSizeHints.Spec.RootDeviceHints = &RootDeviceHints{
+		DeviceName:       "/dev/sda",
+		MinSizeGigabytes: intPtr(0),
+	}
+
+	invalidCleaning := valid.DeepCopy()
+	invalidCleaning.Spec.AutomatedCleaningMode = "full"
+
+	invalidFormat := valid.DeepCopy()
+	invalidFormat.Spec.BootstrapFormat = "cloud-config"
+
+	invalidFailureDomain := valid.DeepCopy()
+	invalidFailureDomain.Spec.FailureDomain = pointer.StringPtr("Rack_1")
+
+	invalidPower := valid.DeepCopy()
+	invalidPower.Spec.PowerManagementPolicy = "external"
+
+	negativeTimeout := valid.DeepCopy()
+	negativeTimeout.Spec.DeprovisionTimeout = &metav1.Duration{Duration: -time.Minute}
+
+	negativeProvisioningTimeout := valid.DeepCopy()
+	negativeProvisioningTimeout.Spec.ProvisioningTimeout = &metav1.Duration{Duration: -time.Minute}
+
+	negativeMinCPUs := valid.DeepCopy()
+	negativeMinCPUs.Spec.MinCPUs = -1
+
+	negativeMinMemory := valid.DeepCopy()
+	negativeMinMemory.Spec.MinMemoryMiB = -1
+
+	negativeRetries := valid.DeepCopy()
+	negativeRetries.Spec.MaxProvisioningRetries = -1
+
+	longName := valid.DeepCopy()
+	longName.Name = strings.Repeat("a", 64)
+
+	invalidOSType := valid.DeepCopy()
+	invalidOSType.Spec.Image.OSType = "macos"
+
+	invalidProviderIDFormat := valid.DeepCopy()
+	invalidProviderIDFormat.Annotations = map[string]string{
+		ProviderIDFormatAnnotation: "v2",
+	}
+
+	tests := []struct {
+		name  string
+		field string
+		c     *BareMetalMachine
+	}{
+		{name: "missing url", field: "spec.image.url", c: missingURL},
+		{name: "invalid url", field: "spec.image.url", c: invalidURL},
+		{name: "missing checksum", field: "spec.image.checksum", c: missingChecksum},
+		{name: "invalid checksum type", field: "spec.image.checksumType", c: invalidChecksumType},
+		{name: "invalid checksum digest", field: "spec.image.checksum", c: invalidDigest},
+		{name: "negative root device size", field: "spec.rootDeviceHints.minSizeGigabytes", c: negativeSizeHints},
+		{name: "zero root device size", field: "spec.rootDeviceHints.minSizeGigabytes", c: zeroSizeHints},
+		{name: "invalid cleaning mode", field: "spec.automatedCleaningMode", c: invalidCleaning},
+		{name: "invalid bootstrap format", field: "spec.bootstrapFormat", c: invalidFormat},
+		{name: "invalid OS type", field: "spec.image.osType", c: invalidOSType},
+		{name: "invalid failure domain", field: "spec.failureDomain", c: invalidFailureDomain},
+		{name: "invalid power management policy", field: "spec.powerManagementPolicy", c: invalidPower},
+		{name: "negative deprovision timeout", field: "spec.deprovisionTimeout", c: negativeTimeout},
+		{name: "negative provisioning timeout", field: "spec.provisioningTimeout", c: negativeProvisioningTimeout},
+		{name: "negative minimum CPUs", field: "spec.minCPUs", c: negativeMinCPUs},
+		{name: "negative minimum memory", field: "spec.minMemoryMiB", c: negativeMinMemory},
+		{name: "negative provisioning retries", field: "spec.maxProvisioningRetries", c: negativeRetries},
+		{name: "name too long", field: "metadata.name", c: longName},
+		{
+			name:  "invalid provider ID format",
+			field: "metadata.annotations[" + ProviderIDFormatAnnotation + "]",
+			c:     invalidProviderIDFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := tt.c.ValidateCreate()
+			g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			g.Expect(invalidFields(err)).To(ConsistOf(tt.field))
+		})
+	}
+}
+
+func TestBareMetalMachineNameLength(t *testing.T) {
+	defer func(prefix string) {
+		HostnamePrefix = prefix
+	}(HostnamePrefix)
+
+	valid := &BareMetalMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+		},
+		Spec: BareMetalMachineSpec{
+			Image: Image{
+				URL:      "http://abc.com/image",
+				Checksum: "97830b21ed272a3d854615beb54cf004",
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		prefix    string
+		length    int
+		expectErr bool
+	}{
+		{
+			name:      "should succeed when name at the limit without prefix",
+			prefix:    "",
+			length:    63,
+			expectErr: false,
+		},
+		{
+			name:      "should return error when name over the limit without prefix",
+			prefix:    "",
+			length:    64,
+			expectErr: true,
+		},
+		{
+			name:      "should succeed when name and prefix at the limit",
+			prefix:    "cluster-a-",
+			length:    53,
+			expectErr: false,
+		},
+		{
+			name:      "should return error when name and prefix over the limit",
+			prefix:    "cluster-a-",
+			length:    54,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			HostnamePrefix = tt.prefix
+			c := valid.DeepCopy()
+			c.Name = strings.Repeat("a", tt.length)
+
+			if tt.expectErr {
+				err := c.ValidateCreate()
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("63 characters"))
+			} else {
+				g.Expect(c.ValidateCreate()).To(Succeed())
 			}
 		})
 	}
 }
+
+func TestBareMetalMachineImageSignature(t *testing.T) {
+	keyRef := &corev1.SecretReference{Name: "image-signing-key"}
+
+	tests := []struct {
+		name            string
+		signatureURL    string
+		signatureKeyRef *corev1.SecretReference
+		fields          []string
+	}{
+		{
+			name: "should succeed when neither is set",
+		},
+		{
+			name:            "should succeed when both are set",
+			signatureURL:    "http://abc.com/image.md5sum.asc",
+			signatureKeyRef: keyRef,
+		},
+		{
+			name:         "should return error when only the URL is set",
+			signatureURL: "http://abc.com/image.md5sum.asc",
+			fields:       []string{"spec.image.signatureKeyRef"},
+		},
+		{
+			name:            "should return error when only the key is set",
+			signatureKeyRef: keyRef,
+			fields:          []string{"spec.image.signatureURL"},
+		},
+		{
+			name:            "should return error when the key names no Secret",
+			signatureURL:    "http://abc.com/image.md5sum.asc",
+			signatureKeyRef: &corev1.SecretReference{Namespace: "foo"},
+			fields:          []string{"spec.image.signatureKeyRef.name"},
+		},
+		{
+			name:         "should succeed with a key in the namespace of the machine",
+			signatureURL: "http://abc.com/image.md5sum.asc",
+			signatureKeyRef: &corev1.SecretReference{
+				Name: "image-signing-key", Namespace: "foo",
+			},
+		},
+		{
+			name:         "should return error with a key in another namespace",
+			signatureURL: "http://abc.com/image.md5sum.asc",
+			signatureKeyRef: &corev1.SecretReference{
+				Name: "image-signing-key", Namespace: "kube-system",
+			},
+			fields: []string{"spec.image.signatureKeyRef.namespace"},
+		},
+		{
+			name:            "should return error when the URL is not http(s)",
+			signatureURL:    "abc.com/image.md5sum.asc",
+			signatureKeyRef: keyRef,
+			fields:          []string{"spec.image.signatureURL"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := &BareMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: BareMetalMachineSpec{
+					Image: Image{
+						URL:             "http://abc.com/image",
+						Checksum:        "97830b21ed272a3d854615beb54cf004",
+						SignatureURL:    tt.signatureURL,
+						SignatureKeyRef: tt.signatureKeyRef.DeepCopy(),
+					},
+				},
+			}
+			c.Default()
+			g.Expect(c.Spec.Image.SignatureURL).To(Equal(tt.signatureURL))
+			g.Expect(c.Spec.Image.SignatureKeyRef).To(Equal(tt.signatureKeyRef))
+
+			err := c.ValidateCreate()
+			if len(tt.fields) == 0 {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			g.Expect(invalidFields(err)).To(ConsistOf(tt.fields))
+		})
+	}
+}
+
+func TestBareMetalMachineHostPinning(t *testing.T) {
+	selector := HostSelector{MatchLabels: map[string]string{"rack": "r1"}}
+	pinned := map[string]string{HostAnnotation: "foo/host-0"}
+
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		selector        HostSelector
+		expectCreateErr bool
+	}{
+		{
+			name:     "should succeed with a HostSelector only",
+			selector: selector,
+		},
+		{
+			name:        "should succeed with a pinned host only",
+			annotations: pinned,
+		},
+		{
+			name:            "should return error with both",
+			annotations:     pinned,
+			selector:        selector,
+			expectCreateErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := &BareMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "foo",
+					Annotations: tt.annotations,
+				},
+				Spec: BareMetalMachineSpec{
+					Image: Image{
+						URL:      "http://abc.com/image",
+						Checksum: "97830b21ed272a3d854615beb54cf004",
+					},
+					HostSelector: tt.selector,
+				},
+			}
+			c.Default()
+
+			// The annotation is set on update once a host is claimed
+			g.Expect(c.ValidateUpdate(c)).To(Succeed())
+
+			err := c.ValidateCreate()
+			if !tt.expectCreateErr {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			g.Expect(invalidFields(err)).To(ConsistOf("spec.hostSelector"))
+			g.Expect(err.Error()).To(ContainSubstring(HostAnnotation))
+		})
+	}
+}
+
+func TestBareMetalMachineDeployImage(t *testing.T) {
+	tests := []struct {
+		name             string
+		deployKernelURL  string
+		deployRamdiskURL string
+		fields           []string
+	}{
+		{
+			name: "should succeed when neither is set",
+		},
+		{
+			name:             "should succeed when both are set",
+			deployKernelURL:  "http://abc.com/ipa.kernel",
+			deployRamdiskURL: "https://abc.com/ipa.initramfs",
+		},
+		{
+			name:            "should return error when only the kernel is set",
+			deployKernelURL: "http://abc.com/ipa.kernel",
+			fields:          []string{"spec.deployRamdiskURL"},
+		},
+		{
+			name:             "should return error when only the ramdisk is set",
+			deployRamdiskURL: "http://abc.com/ipa.initramfs",
+			fields:           []string{"spec.deployKernelURL"},
+		},
+		{
+			name:             "should return error when a URL is not http(s)",
+			deployKernelURL:  "abc.com/ipa.kernel",
+			deployRamdiskURL: "ftp://abc.com/ipa.initramfs",
+			fields:           []string{"spec.deployKernelURL", "spec.deployRamdiskURL"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := &BareMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: BareMetalMachineSpec{
+					Image: Image{
+						URL:      "http://abc.com/image",
+						Checksum: "97830b21ed272a3d854615beb54cf004",
+					},
+					DeployKernelURL:  tt.deployKernelURL,
+					DeployRamdiskURL: tt.deployRamdiskURL,
+				},
+			}
+			c.Default()
+
+			err := c.ValidateCreate()
+			if len(tt.fields) == 0 {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			g.Expect(invalidFields(err)).To(ConsistOf(tt.fields))
+		})
+	}
+}
+
+func TestBareMetalMachineBootMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		bootMode  BootMode
+		expectErr bool
+	}{
+		{
+			name:     "should succeed with UEFI",
+			bootMode: BootModeUEFI,
+		},
+		{
+			name:     "should succeed with legacy",
+			bootMode: BootModeLegacy,
+		},
+		{
+			name:     "should succeed with UEFI secure boot",
+			bootMode: BootModeUEFISecureBoot,
+		},
+		{
+			name:      "should return error with an unknown mode",
+			bootMode:  "uefi",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := &BareMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: BareMetalMachineSpec{
+					Image: Image{
+						URL:      "http://abc.com/image",
+						Checksum: "97830b21ed272a3d854615beb54cf004",
+					},
+					BootMode: tt.bootMode,
+				},
+			}
+			c.Default()
+			g.Expect(c.Spec.BootMode).To(Equal(tt.bootMode))
+
+			err := c.ValidateCreate()
+			if !tt.expectErr {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			g.Expect(invalidFields(err)).To(ConsistOf("spec.bootMode"))
+		})
+	}
+}
+
+func TestBareMetalMachineMetaData(t *testing.T) {
+	tests := []struct {
+		name     string
+		metaData *corev1.SecretReference
+		fields   []string
+	}{
+		{
+			name: "should succeed when not set",
+		},
+		{
+			name:     "should succeed without a namespace",
+			metaData: &corev1.SecretReference{Name: "meta-data"},
+		},
+		{
+			name: "should succeed with a namespace",
+			metaData: &corev1.SecretReference{
+				Name: "meta-data", Namespace: "otherns",
+			},
+		},
+		{
+			name:     "should return error when the name is missing",
+			metaData: &corev1.SecretReference{Namespace: "otherns"},
+			fields:   []string{"spec.metaData.name"},
+		},
+		{
+			name:     "should return error when the name is invalid",
+			metaData: &corev1.SecretReference{Name: "Meta_Data"},
+			fields:   []string{"spec.metaData.name"},
+		},
+		{
+			name: "should return error when the namespace is invalid",
+			metaData: &corev1.SecretReference{
+				Name: "meta-data", Namespace: "other.ns",
+			},
+			fields: []string{"spec.metaData.namespace"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := &BareMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: BareMetalMachineSpec{
+					Image: Image{
+						URL:      "http://abc.com/image",
+						Checksum: "97830b21ed272a3d854615beb54cf004",
+					},
+					MetaData: tt.metaData,
+				},
+			}
+			c.Default()
+
+			err := c.ValidateCreate()
+			if len(tt.fields) == 0 {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(c.ValidateUpdate(c)).To(Succeed())
+				return
+			}
+			g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			g.Expect(invalidFields(err)).To(ConsistOf(tt.fields))
+			g.Expect(c.ValidateUpdate(c)).NotTo(Succeed())
+		})
+	}
+}
+
+func TestBareMetalMachineImageUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		providerID  *string
+		url         string
+		checksum    string
+		fields      []string
+		expectCause metav1.CauseType
+	}{
+		{
+			name:       "should succeed when changing the image of a provisioned machine",
+			providerID: pointer.StringPtr("metal3://abc"),
+			url:        "http://abc.com/other-image",
+			checksum:   "97830b21ed272a3d854615beb54cf004",
+		},
+		{
+			name:        "should return error when clearing the url of a provisioned machine",
+			providerID:  pointer.StringPtr("metal3://abc"),
+			checksum:    "97830b21ed272a3d854615beb54cf004",
+			fields:      []string{"spec.image.url"},
+			expectCause: metav1.CauseType(field.ErrorTypeForbidden),
+		},
+		{
+			name:        "should return error when clearing the checksum of a provisioned machine",
+			providerID:  pointer.StringPtr("metal3://abc"),
+			url:         "http://abc.com/image",
+			fields:      []string{"spec.image.checksum"},
+			expectCause: metav1.CauseType(field.ErrorTypeForbidden),
+		},
+		{
+			name:        "should only require the url of an unprovisioned machine",
+			checksum:    "97830b21ed272a3d854615beb54cf004",
+			fields:      []string{"spec.image.url"},
+			expectCause: metav1.CauseTypeFieldValueRequired,
+		},
+		{
+			name:        "should only require the url when the providerID is empty",
+			providerID:  pointer.StringPtr(""),
+			checksum:    "97830b21ed272a3d854615beb54cf004",
+			fields:      []string{"spec.image.url"},
+			expectCause: metav1.CauseTypeFieldValueRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			old := &BareMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: BareMetalMachineSpec{
+					ProviderID: tt.providerID,
+					Image: Image{
+						URL:      "http://abc.com/image",
+						Checksum: "97830b21ed272a3d854615beb54cf004",
+					},
+				},
+			}
+			old.Default()
+			c := old.DeepCopy()
+			c.Spec.Image.URL = tt.url
+			c.Spec.Image.Checksum = tt.checksum
+
+			err := c.ValidateUpdate(old)
+			if len(tt.fields) == 0 {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			g.Expect(invalidFields(err)).To(ConsistOf(tt.fields))
+			causes := err.(*apierrors.StatusError).ErrStatus.Details.Causes
+			g.Expect(causes[0].Type).To(Equal(tt.expectCause))
+		})
+	}
+}
+
+func TestBareMetalMachineRootDeviceHintsUpdate(t *testing.T) {
+	tests := []struct {
+		name       string
+		providerID *string
+		hints      *RootDeviceHints
+		expectErr  bool
+	}{
+		{
+			name:       "should succeed when keeping the hints of a provisioned machine",
+			providerID: pointer.StringPtr("metal3://abc"),
+			hints:      &RootDeviceHints{DeviceName: "/dev/sda"},
+		},
+		{
+			name:       "should return error when changing the hints of a provisioned machine",
+			providerID: pointer.StringPtr("metal3://abc"),
+			hints:      &RootDeviceHints{DeviceName: "/dev/sdb"},
+			expectErr:  true,
+		},
+		{
+			name:       "should return error when clearing the hints of a provisioned machine",
+			providerID: pointer.StringPtr("metal3://abc"),
+			expectErr:  true,
+		},
+		{
+			name:  "should succeed when changing the hints of an unprovisioned machine",
+			hints: &RootDeviceHints{DeviceName: "/dev/sdb"},
+		},
+		{
+			name:       "should succeed when changing the hints with an empty providerID",
+			providerID: pointer.StringPtr(""),
+			hints:      &RootDeviceHints{SerialNumber: "abc123"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			old := &BareMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: BareMetalMachineSpec{
+					ProviderID: tt.providerID,
+					Image: Image{
+						URL:      "http://abc.com/image",
+						Checksum: "97830b21ed272a3d854615beb54cf004",
+					},
+					RootDeviceHints: &RootDeviceHints{DeviceName: "/dev/sda"},
+				},
+			}
+			old.Default()
+			c := old.DeepCopy()
+			c.Spec.RootDeviceHints = tt.hints
+
+			err := c.ValidateUpdate(old)
+			if !tt.expectErr {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			g.Expect(invalidFields(err)).To(ConsistOf("spec.rootDeviceHints"))
+			causes := err.(*apierrors.StatusError).ErrStatus.Details.Causes
+			g.Expect(causes[0].Type).To(Equal(metav1.CauseType(field.ErrorTypeForbidden)))
+		})
+	}
+}
+
+func TestBareMetalMachineImageReachability(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if r.URL.Path == "/missing" {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		},
+	))
+	defer server.Close()
+
+	defer func(enabled bool, checker *urlChecker) {
+		_ = featuregate.Gates.SetFromMap(map[string]bool{
+			string(featuregate.ImageReachabilityCheck): enabled,
+		})
+		imageReachability = checker
+	}(featuregate.Enabled(featuregate.ImageReachabilityCheck), imageReachability)
+	imageReachability = newURLChecker(server.Client(), time.Minute)
+
+	valid := &BareMetalMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+		},
+		Spec: BareMetalMachineSpec{
+			Image: Image{
+				URL:          server.URL + "/image",
+				Checksum:     server.URL + "/image.md5sum",
+				ChecksumType: ChecksumTypeMD5,
+			},
+		},
+	}
+	missingURL := valid.DeepCopy()
+	missingURL.Spec.Image.URL = server.URL + "/missing"
+
+	missingChecksum := valid.DeepCopy()
+	missingChecksum.Spec.Image.Checksum = server.URL + "/missing"
+
+	tests := []struct {
+		name      string
+		enabled   bool
+		expectErr bool
+		c         *BareMetalMachine
+	}{
+		{
+			name:      "should succeed when gate disabled and url missing",
+			enabled:   false,
+			expectErr: false,
+			c:         missingURL,
+		},
+		{
+			name:      "should return error when url missing",
+			enabled:   true,
+			expectErr: true,
+			c:         missingURL,
+		},
+		{
+			name:      "should return error when checksum missing",
+			enabled:   true,
+			expectErr: true,
+			c:         missingChecksum,
+		},
+		{
+			name:      "should succeed when image reachable",
+			enabled:   true,
+			expectErr: false,
+			c:         valid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(featuregate.Gates.SetFromMap(map[string]bool{
+				string(featuregate.ImageReachabilityCheck): tt.enabled,
+			})).To(Succeed())
+
+			if tt.expectErr {
+				g.Expect(tt.c.ValidateCreate()).NotTo(Succeed())
+			} else {
+				g.Expect(tt.c.ValidateCreate()).To(Succeed())
+			}
+			// Updates never hit the network
+			g.Expect(tt.c.ValidateUpdate(tt.c)).To(Succeed())
+		})
+	}
+
+	// Each of the three URLs was only requested once
+	NewWithT(t).Expect(requests).To(Equal(3))
+}
+
+func TestBareMetalMachineImageNetwork(t *testing.T) {
+	defer func(enabled bool, networks string,
+		lookup func(string) ([]net.IP, error)) {
+		_ = featuregate.Gates.SetFromMap(map[string]bool{
+			string(featuregate.ImageURLDenyList): enabled,
+		})
+		_ = DeniedImageNetworks.Set(networks)
+		lookupImageHost = lookup
+	}(featuregate.Enabled(featuregate.ImageURLDenyList),
+		DeniedImageNetworks.String(), lookupImageHost,
+	)
+	NewWithT(t).Expect(DeniedImageNetworks.Set("10.96.0.0/12, 192.168.0.0/16")).To(Succeed())
+	lookupImageHost = func(host string) ([]net.IP, error) {
+		switch host {
+		case "kubernetes.default.svc":
+			return []net.IP{net.ParseIP("10.96.0.1")}, nil
+		case "images.example.com":
+			return []net.IP{net.ParseIP("172.22.0.1")}, nil
+		}
+		return nil, fmt.Errorf("no such host %s", host)
+	}
+
+	newMachine := func(url string) *BareMetalMachine {
+		return &BareMetalMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+			},
+			Spec: BareMetalMachineSpec{
+				Image: Image{
+					URL:      url,
+					Checksum: "abc",
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		enabled   bool
+		expectErr bool
+		c         *BareMetalMachine
+	}{
+		{
+			name:      "should succeed when gate disabled and host denied",
+			enabled:   false,
+			expectErr: false,
+			c:         newMachine("http://kubernetes.default.svc/image"),
+		},
+		{
+			name:      "should return error when host resolves into a denied network",
+			enabled:   true,
+			expectErr: true,
+			c:         newMachine("http://kubernetes.default.svc/image"),
+		},
+		{
+			name:      "should return error when host is a denied address",
+			enabled:   true,
+			expectErr: true,
+			c:         newMachine("http://192.168.111.1:8080/image"),
+		},
+		{
+			name:      "should succeed when host resolves outside the denied networks",
+			enabled:   true,
+			expectErr: false,
+			c:         newMachine("http://images.example.com/image"),
+		},
+		{
+			name:      "should succeed when host does not resolve",
+			enabled:   true,
+			expectErr: false,
+			c:         newMachine("http://unknown.example.com/image"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(featuregate.Gates.SetFromMap(map[string]bool{
+				string(featuregate.ImageURLDenyList): tt.enabled,
+			})).To(Succeed())
+
+			err := tt.c.ValidateCreate()
+			if tt.expectErr {
+				g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+				g.Expect(invalidFields(err)).To(ConsistOf("spec.image.url"))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			// Updates never hit the network
+			g.Expect(tt.c.ValidateUpdate(tt.c)).To(Succeed())
+		})
+	}
+}
+
+func TestCIDRListSet(t *testing.T) {
+	g := NewWithT(t)
+	list := &CIDRList{}
+
+	g.Expect(list.Set("10.96.0.0/12,fd00::/8")).To(Succeed())
+	g.Expect(list.String()).To(Equal("10.96.0.0/12,fd00::/8"))
+	g.Expect(list.Contains(net.ParseIP("10.96.0.1"))).NotTo(BeNil())
+	g.Expect(list.Contains(net.ParseIP("fd00::1"))).NotTo(BeNil())
+	g.Expect(list.Contains(net.ParseIP("10.0.0.1"))).To(BeNil())
+
+	// An invalid CIDR leaves the list unchanged
+	g.Expect(list.Set("10.96.0.0/12,foo")).NotTo(Succeed())
+	g.Expect(list.String()).To(Equal("10.96.0.0/12,fd00::/8"))
+}
+
+func TestURLCheckerCacheExpiry(t *testing.T) {
+	g := NewWithT(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusNotFound)
+		},
+	))
+	defer server.Close()
+
+	now := time.Now()
+	checker := newURLChecker(server.Client(), time.Minute)
+	checker.now = func() time.Time { return now }
+
+	g.Expect(checker.isNotFound(server.URL)).To(BeTrue())
+	g.Expect(checker.isNotFound(server.URL)).To(BeTrue())
+	g.Expect(requests).To(Equal(1))
+
+	now = now.Add(2 * time.Minute)
+	g.Expect(checker.isNotFound(server.URL)).To(BeTrue())
+	g.Expect(requests).To(Equal(2))
+
+	// Not an http(s) URL, nothing is requested
+	g.Expect(checker.isNotFound("abcdef0123456789")).To(BeFalse())
+	g.Expect(requests).To(Equal(2))
+}
+
+func TestURLCheckerCacheSize(t *testing.T) {
+	g := NewWithT(t)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		},
+	))
+	defer server.Close()
+
+	now := time.Now()
+	checker := newURLChecker(server.Client(), time.Minute)
+	checker.maxEntries = 2
+	checker.now = func() time.Time { return now }
+
+	checker.isNotFound(server.URL + "/a")
+	now = now.Add(time.Second)
+	checker.isNotFound(server.URL + "/b")
+	g.Expect(checker.results).To(HaveLen(2))
+
+	// The cache is full, the result expiring first is dropped
+	checker.isNotFound(server.URL + "/c")
+	g.Expect(checker.results).To(HaveLen(2))
+	g.Expect(checker.results).NotTo(HaveKey(server.URL + "/a"))
+
+	// The expired results are dropped, even if never looked up again
+	now = now.Add(2 * time.Minute)
+	checker.isNotFound(server.URL + "/d")
+	g.Expect(checker.results).To(HaveLen(1))
+	g.Expect(checker.results).To(HaveKey(server.URL + "/d"))
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha3
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/selection"
 )
 
@@ -40,6 +41,12 @@ type HostSelector struct {
 	MatchExpressions []HostSelectorRequirement `json:"matchExpressions,omitempty"`
 }
 
+// HostAnnotation is the key for an annotation on a BareMetalMachine
+// referencing, as "namespace/name", the BareMetalHost it corresponds to. It
+// is set once a host is claimed, or beforehand to pin the BareMetalMachine
+// to a given host.
+const HostAnnotation = "metal3.io/BareMetalHost"
+
 type HostSelectorRequirement struct {
 	Key      string             `json:"key"`
 	Operator selection.Operator `json:"operator"`
@@ -53,4 +60,183 @@ type Image struct {
 
 	// Checksum is a md5sum value or a URL to retrieve one.
 	Checksum string `json:"checksum"`
+
+	// ChecksumType is the checksum algorithm. It is required when Checksum
+	// is a URL, and defaults to md5 for an inline digest.
+	// +kubebuilder:validation:Enum=md5;sha256;sha512
+	// +optional
+	ChecksumType ChecksumType `json:"checksumType,omitempty"`
+
+	// OSType is the operating system of the image, which selects how the host
+	// is bootstrapped. Defaults to "linux".
+	// +kubebuilder:validation:Enum=linux;windows
+	// +optional
+	OSType OSType `json:"osType,omitempty"`
+
+	// SignatureURL is a location of a detached OpenPGP signature of the
+	// checksum, or of the document Checksum points to. When set, the
+	// signature is verified with the keys of SignatureKeyRef before the host
+	// is provisioned.
+	// +optional
+	SignatureURL string `json:"signatureURL,omitempty"`
+
+	// SignatureKeyRef references the Secret holding, under its "key" key, the
+	// armored OpenPGP public keys trusted to sign the checksum. It is
+	// required with SignatureURL. The Namespace defaults to, and must be, the
+	// one of the BareMetalMachine.
+	// +optional
+	SignatureKeyRef *corev1.SecretReference `json:"signatureKeyRef,omitempty"`
+}
+
+// ChecksumType is the algorithm of an image checksum.
+type ChecksumType string
+
+const (
+	// ChecksumTypeMD5 is an md5 digest.
+	ChecksumTypeMD5 ChecksumType = "md5"
+	// ChecksumTypeSHA256 is a sha256 digest.
+	ChecksumTypeSHA256 ChecksumType = "sha256"
+	// ChecksumTypeSHA512 is a sha512 digest.
+	ChecksumTypeSHA512 ChecksumType = "sha512"
+)
+
+// OSType is the operating system of an image.
+type OSType string
+
+const (
+	// OSTypeLinux is for Linux images.
+	OSTypeLinux OSType = "linux"
+	// OSTypeWindows is for Windows images.
+	OSTypeWindows OSType = "windows"
+)
+
+// AutomatedCleaningMode is the type of cleaning done on the disks of a host
+// between provisions.
+type AutomatedCleaningMode string
+
+const (
+	// CleaningModeMetadata wipes the disk metadata before provisioning.
+	CleaningModeMetadata AutomatedCleaningMode = "metadata"
+	// CleaningModeDisabled skips the cleaning for faster provisioning.
+	CleaningModeDisabled AutomatedCleaningMode = "disabled"
+)
+
+// PowerManagementPolicy selects who manages the power state of a host.
+type PowerManagementPolicy string
+
+const (
+	// PowerManagementAutomatic has the provider power the host on once
+	// provisioned and off when it is released.
+	PowerManagementAutomatic PowerManagementPolicy = "automatic"
+	// PowerManagementManual leaves the power state of the host to the
+	// operators, the host is only provisioned.
+	PowerManagementManual PowerManagementPolicy = "manual"
+)
+
+// BootstrapFormat is the format of the bootstrap data given to a host.
+type BootstrapFormat string
+
+const (
+	// BootstrapFormatCloudInit is for cloud-init user data.
+	BootstrapFormatCloudInit BootstrapFormat = "cloud-init"
+	// BootstrapFormatIgnition is for ignition configs.
+	BootstrapFormatIgnition BootstrapFormat = "ignition"
+)
+
+// BootMode is the mode the host boots in.
+type BootMode string
+
+const (
+	// BootModeUEFI boots the host in UEFI mode.
+	BootModeUEFI BootMode = "UEFI"
+	// BootModeLegacy boots the host in legacy BIOS mode.
+	BootModeLegacy BootMode = "legacy"
+	// BootModeUEFISecureBoot boots the host in UEFI mode, with secure boot
+	// enabled. The host firmware must support it.
+	BootModeUEFISecureBoot BootMode = "UEFISecureBoot"
+)
+
+// ProviderIDFormatAnnotation is set on a BareMetalMachine to select the
+// format of the ProviderID computed for it, e.g. to keep the legacy one while
+// a cluster is migrated. The current format is used when it is not set.
+const ProviderIDFormatAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/provider-id-format"
+
+// ProviderIDFormat is the format of the ProviderID of a BareMetalMachine.
+type ProviderIDFormat string
+
+const (
+	// ProviderIDFormatLegacy is "baremetal:////<machinename>", with the name
+	// of the BareMetalMachine.
+	ProviderIDFormatLegacy ProviderIDFormat = "legacy"
+	// ProviderIDFormatCurrent is "metal3://<host UID>".
+	ProviderIDFormatCurrent ProviderIDFormat = "current"
+)
+
+// RootDeviceHints holds the hints for specifying the storage location for the
+// root filesystem for the image. A device must match all the hints given.
+type RootDeviceHints struct {
+	// DeviceName is a Linux device name like "/dev/vda". The hint must match
+	// the actual value exactly.
+	// +optional
+	DeviceName string `json:"deviceName,omitempty"`
+
+	// HCTL is a SCSI bus address like 0:0:0:0. The hint must match the actual
+	// value exactly.
+	// +optional
+	HCTL string `json:"hctl,omitempty"`
+
+	// Model is a vendor-specific device identifier. The hint can be a
+	// substring of the actual value.
+	// +optional
+	Model string `json:"model,omitempty"`
+
+	// Vendor is the name of the vendor or manufacturer of the device. The hint
+	// can be a substring of the actual value.
+	// +optional
+	Vendor string `json:"vendor,omitempty"`
+
+	// SerialNumber is the disk serial number. The hint must match the actual
+	// value exactly.
+	// +optional
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// MinSizeGigabytes is the minimum size of the device in Gigabytes. It
+	// must be positive when set.
+	// +optional
+	MinSizeGigabytes *int `json:"minSizeGigabytes,omitempty"`
+
+	// WWN is a unique storage identifier. The hint must match the actual value
+	// exactly.
+	// +optional
+	WWN string `json:"wwn,omitempty"`
+
+	// Rotational is true to select a rotational device, false to select a
+	// non-rotational device (SSD or NVMe).
+	// +optional
+	Rotational *bool `json:"rotational,omitempty"`
+}
+
+// IsEmpty returns true if no hint is given.
+func (h *RootDeviceHints) IsEmpty() bool {
+	return h.DeviceName == "" && h.HCTL == "" && h.Model == "" &&
+		h.Vendor == "" && h.SerialNumber == "" && h.MinSizeGigabytes == nil &&
+		h.WWN == "" && h.Rotational == nil
+}
+
+// Firmware holds the firmware (BIOS) settings of the host. A setting
+// left unset keeps the current value of the host.
+type Firmware struct {
+	// VirtualizationEnabled enables the hardware virtualization extensions
+	// (VT-x, AMD-V).
+	// +optional
+	VirtualizationEnabled *bool `json:"virtualizationEnabled,omitempty"`
+
+	// SimultaneousMultithreadingEnabled enables simultaneous multithreading,
+	// e.g. Hyper-Threading.
+	// +optional
+	SimultaneousMultithreadingEnabled *bool `json:"simultaneousMultithreadingEnabled,omitempty"`
+
+	// SriovEnabled enables SR-IOV support.
+	// +optional
+	SriovEnabled *bool `json:"sriovEnabled,omitempty"`
 }
@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionType is the type of a condition of a BareMetalMachine.
+type ConditionType string
+
+const (
+	// RemediationInProgressCondition is true while the provider remediates
+	// the host of the BareMetalMachine.
+	RemediationInProgressCondition ConditionType = "RemediationInProgress"
+	// NetworkConfiguredCondition is true once the host of a BareMetalMachine
+	// with NetworkData reports its NICs configured.
+	NetworkConfiguredCondition ConditionType = "NetworkConfigured"
+	// ReprovisioningCondition is true while the host of a BareMetalMachine is
+	// reprovisioned on request.
+	ReprovisioningCondition ConditionType = "Reprovisioning"
+	// WaitingForOwnerCondition is true while the BareMetalMachine has no
+	// owning Machine.
+	WaitingForOwnerCondition ConditionType = "WaitingForOwner"
+	// UserDataOverriddenCondition is true when the UserData set on the
+	// BareMetalMachine was replaced by the bootstrap data of its Machine.
+	UserDataOverriddenCondition ConditionType = "UserDataOverridden"
+	// ImageDownloadingCondition is true while the host of a BareMetalMachine
+	// reports downloading its image.
+	ImageDownloadingCondition ConditionType = "ImageDownloading"
+	// MaintenanceCondition is true while the host of a BareMetalMachine is
+	// paused for maintenance on request.
+	MaintenanceCondition ConditionType = "Maintenance"
+	// ProvisioningTimedOutCondition is true once the host of a
+	// BareMetalMachine did not provision within the ProvisioningTimeout.
+	ProvisioningTimedOutCondition ConditionType = "ProvisioningTimedOut"
+	// HostErrorCondition is true while the host of a BareMetalMachine
+	// reports an error. Its message is the one of the host.
+	HostErrorCondition ConditionType = "HostError"
+	// SecureBootRequiredCondition is true when the BareMetalMachine boots its
+	// host with UEFI secure boot, which the host firmware must support.
+	SecureBootRequiredCondition ConditionType = "SecureBootRequired"
+	// AssociatingCondition is set while the host of a BareMetalMachine
+	// registers, is inspected or waits to be provisioned.
+	AssociatingCondition ConditionType = "Associating"
+	// ProvisioningCondition is set while the host of a BareMetalMachine
+	// provisions, and after it failed to.
+	ProvisioningCondition ConditionType = "Provisioning"
+	// ProvisionedCondition is set once the host of a BareMetalMachine is
+	// provisioned.
+	ProvisionedCondition ConditionType = "Provisioned"
+	// DeprovisioningCondition is set while the host of a BareMetalMachine
+	// deprovisions or is deleted.
+	DeprovisioningCondition ConditionType = "Deprovisioning"
+)
+
+// ConditionSeverity classifies the reason of a condition whose status is
+// False, as in Cluster API.
+type ConditionSeverity string
+
+const (
+	// ConditionSeverityError tells that the condition requires an action
+	// from the user.
+	ConditionSeverityError ConditionSeverity = "Error"
+	// ConditionSeverityWarning tells that the condition may recover, but
+	// should be looked at.
+	ConditionSeverityWarning ConditionSeverity = "Warning"
+	// ConditionSeverityInfo tells that the condition is expected, e.g.
+	// while waiting.
+	ConditionSeverityInfo ConditionSeverity = "Info"
+	// ConditionSeverityNone is the severity of the conditions whose status
+	// is not False.
+	ConditionSeverityNone ConditionSeverity = ""
+)
+
+// Condition is an observation of the state of an object.
+type Condition struct {
+	// Type of the condition.
+	Type ConditionType `json:"type"`
+
+	// Status of the condition, one of True, False or Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+
+	// Severity classifies the reason of the condition, only when its status
+	// is False.
+	// +optional
+	Severity ConditionSeverity `json:"severity,omitempty"`
+
+	// LastTransitionTime is the last time the condition changed status.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason is a CamelCase reason for the last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable description of the condition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// Conditions is a list of conditions, with at most one per type.
+type Conditions []Condition
+
+// Get returns the condition of the given type, or nil if it is not set.
+func (c Conditions) Get(conditionType ConditionType) *Condition {
+	for i := range c {
+		if c[i].Type == conditionType {
+			return &c[i]
+		}
+	}
+	return nil
+}
+
+// IsTrue returns true if the condition of the given type is set to True.
+func (c Conditions) IsTrue(conditionType ConditionType) bool {
+	condition := c.Get(conditionType)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// Set adds or replaces the condition of the same type. The transition time
+// is only updated when the status changes.
+func (c *Conditions) Set(condition Condition) {
+	existing := c.Get(condition.Type)
+	if existing == nil {
+		if condition.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		*c = append(*c, condition)
+		return
+	}
+
+	if existing.Status != condition.Status {
+		existing.Status = condition.Status
+		existing.LastTransitionTime = condition.LastTransitionTime
+		if existing.LastTransitionTime.IsZero() {
+			existing.LastTransitionTime = metav1.Now()
+		}
+	}
+	existing.Severity = condition.Severity
+	existing.Reason = condition.Reason
+	existing.Message = condition.Message
+}
+
+// Remove removes the condition of the given type.
+func (c *Conditions) Remove(conditionType ConditionType) {
+	conditions := Conditions{}
+	for _, condition := range *c {
+		if condition.Type != conditionType {
+			conditions = append(conditions, condition)
+		}
+	}
+	*c = conditions
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// DefaultedFieldsAnnotation lists, comma-separated, the paths of the fields
+// that were set by the defaulting webhook rather than by the user, as in
+// "spec.bootstrapFormat,spec.powerManagementPolicy".
+const DefaultedFieldsAnnotation = "infrastructure.cluster.x-k8s.io/defaulted-fields"
+
+// recordDefaultedFields adds the paths to the DefaultedFieldsAnnotation of
+// the object. The paths recorded earlier are kept, since the fields are
+// already set when the object is defaulted again on update.
+func recordDefaultedFields(obj *metav1.ObjectMeta, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	recorded := sets.NewString(paths...)
+	if previous := obj.Annotations[DefaultedFieldsAnnotation]; previous != "" {
+		recorded.Insert(strings.Split(previous, ",")...)
+	}
+	if obj.Annotations == nil {
+		obj.Annotations = map[string]string{}
+	}
+	obj.Annotations[DefaultedFieldsAnnotation] = strings.Join(recorded.List(), ",")
+}
+
+// DefaultedFields returns the paths of the fields of the object that were
+// set by the defaulting webhook, as recorded in the DefaultedFieldsAnnotation.
+func DefaultedFields(obj metav1.Object) []string {
+	recorded := obj.GetAnnotations()[DefaultedFieldsAnnotation]
+	if recorded == "" {
+		return nil
+	}
+	return strings.Split(recorded, ",")
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// DeniedImageNetworks are the networks, e.g. the API and pod networks of the
+// management cluster, that the Image.URL host of a BareMetalMachine may not
+// resolve into when the ImageURLDenyList feature gate is enabled.
+var DeniedImageNetworks = &CIDRList{}
+
+// lookupImageHost resolves the Image.URL host. Replaced in tests.
+var lookupImageHost = net.LookupIP
+
+// CIDRList is a list of networks. It implements flag.Value, taking a
+// comma-separated list of CIDRs.
+// +kubebuilder:object:generate=false
+type CIDRList struct {
+	mu       sync.RWMutex
+	networks []*net.IPNet
+}
+
+// Set parses a comma-separated list of CIDRs and replaces the networks of
+// the list. It fails, without changing the list, if a CIDR is invalid.
+func (l *CIDRList) Set(value string) error {
+	networks := []*net.IPNet{}
+	for _, cidr := range strings.Split(value, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid CIDR %q", cidr)
+		}
+		networks = append(networks, network)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.networks = networks
+	return nil
+}
+
+// String returns the networks as a comma-separated list of CIDRs.
+func (l *CIDRList) String() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	cidrs := make([]string, 0, len(l.networks))
+	for _, network := range l.networks {
+		cidrs = append(cidrs, network.String())
+	}
+	return strings.Join(cidrs, ",")
+}
+
+// Contains returns the network of the list containing ip, or nil.
+func (l *CIDRList) Contains(ip net.IP) *net.IPNet {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, network := range l.networks {
+		if network.Contains(ip) {
+			return network
+		}
+	}
+	return nil
+}
+
+// deniedImageNetwork returns the network of DeniedImageNetworks that the host
+// of rawURL resolves into, or nil. Hosts that do not resolve are not
+// reported, as for the reachability check.
+func deniedImageNetwork(rawURL string) *net.IPNet {
+	u, err := url.Parse(NormalizeImageURL(rawURL))
+	if err != nil || u.Hostname() == "" {
+		return nil
+	}
+
+	ips := []net.IP{net.ParseIP(u.Hostname())}
+	if ips[0] == nil {
+		if ips, err = lookupImageHost(u.Hostname()); err != nil {
+			return nil
+		}
+	}
+	for _, ip := range ips {
+		if network := DeniedImageNetworks.Contains(ip); network != nil {
+			return network
+		}
+	}
+	return nil
+}
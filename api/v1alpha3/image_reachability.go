@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// imageReachabilityTimeout bounds each HEAD request.
+	imageReachabilityTimeout = 5 * time.Second
+	// imageReachabilityTTL is how long a result is cached for a given URL.
+	imageReachabilityTTL = time.Minute
+	// imageReachabilityMaxEntries bounds the number of cached results.
+	imageReachabilityMaxEntries = 1024
+)
+
+// imageReachability is the checker used by the BareMetalMachine webhook.
+var imageReachability = newURLChecker(
+	&http.Client{Timeout: imageReachabilityTimeout}, imageReachabilityTTL,
+)
+
+// urlCheckResult is a cached reachability result.
+// +kubebuilder:object:generate=false
+type urlCheckResult struct {
+	notFound bool
+	expires  time.Time
+}
+
+// urlChecker issues HEAD requests and caches the results per URL, up to
+// maxEntries results.
+// +kubebuilder:object:generate=false
+type urlChecker struct {
+	client     *http.Client
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	results map[string]urlCheckResult
+}
+
+func newURLChecker(client *http.Client, ttl time.Duration) *urlChecker {
+	return &urlChecker{
+		client:     client,
+		ttl:        ttl,
+		maxEntries: imageReachabilityMaxEntries,
+		now:        time.Now,
+		results:    map[string]urlCheckResult{},
+	}
+}
+
+// isNotFound returns true if a HEAD request on rawURL returns 404. Values that
+// are not http(s) URLs, and requests failing for other reasons, are not
+// reported, so that the webhook keeps working with unreachable mirrors.
+func (c *urlChecker) isNotFound(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+
+	c.mu.Lock()
+	result, ok := c.results[rawURL]
+	c.mu.Unlock()
+	if ok && c.now().Before(result.expires) {
+		return result.notFound
+	}
+
+	result = urlCheckResult{expires: c.now().Add(c.ttl)}
+	resp, err := c.client.Head(rawURL)
+	if err == nil {
+		resp.Body.Close()
+		result.notFound = resp.StatusCode == http.StatusNotFound
+	}
+
+	c.mu.Lock()
+	c.store(rawURL, result)
+	c.mu.Unlock()
+	return result.notFound
+}
+
+// store caches result for rawURL. Once the cache is full, the expired results
+// are dropped, and the result expiring first too if none had expired. The
+// caller holds the lock.
+func (c *urlChecker) store(rawURL string, result urlCheckResult) {
+	if _, ok := c.results[rawURL]; !ok && len(c.results) >= c.maxEntries {
+		now := c.now()
+		var first string
+		for key, cached := range c.results {
+			if !now.Before(cached.expires) {
+				delete(c.results, key)
+				continue
+			}
+			if first == "" || cached.expires.Before(c.results[first].expires) {
+				first = key
+			}
+		}
+		if len(c.results) >= c.maxEntries {
+			delete(c.results, first)
+		}
+	}
+	c.results[rawURL] = result
+}
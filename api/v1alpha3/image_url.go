@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"strings"
+)
+
+// ImageURLSchemeTFTP is the scheme of images served over TFTP.
+const ImageURLSchemeTFTP = "tftp"
+
+// NormalizeImageURL rewrites the bare "host:path" form used by PXE
+// environments into a tftp URL, e.g. "pxe-server:/images/disk.img" becomes
+// "tftp://pxe-server/images/disk.img". Other values are returned unchanged.
+func NormalizeImageURL(rawURL string) string {
+	if strings.Contains(rawURL, "://") {
+		return rawURL
+	}
+
+	colon := strings.Index(rawURL, ":")
+	if colon <= 0 || colon == len(rawURL)-1 {
+		return rawURL
+	}
+	host, path := rawURL[:colon], rawURL[colon+1:]
+	if strings.Contains(host, "/") || isDigits(path) {
+		// Not a bare host, or a host:port value
+		return rawURL
+	}
+
+	return ImageURLSchemeTFTP + "://" + host + "/" + strings.TrimLeft(path, "/")
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNormalizeImageURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{
+			name:     "should keep http url",
+			url:      "http://172.22.0.1/images/disk.img",
+			expected: "http://172.22.0.1/images/disk.img",
+		},
+		{
+			name:     "should keep tftp url",
+			url:      "tftp://172.22.0.1/images/disk.img",
+			expected: "tftp://172.22.0.1/images/disk.img",
+		},
+		{
+			name:     "should rewrite bare absolute path",
+			url:      "pxe-server:/images/disk.img",
+			expected: "tftp://pxe-server/images/disk.img",
+		},
+		{
+			name:     "should rewrite bare relative path",
+			url:      "172.22.0.1:images/disk.img",
+			expected: "tftp://172.22.0.1/images/disk.img",
+		},
+		{
+			name:     "should keep host and port",
+			url:      "pxe-server:8080",
+			expected: "pxe-server:8080",
+		},
+		{
+			name:     "should keep value without host",
+			url:      "disk.img",
+			expected: "disk.img",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(NormalizeImageURL(tt.url)).To(Equal(tt.expected))
+		})
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BatchUpdateStatus updates the status of the BareMetalClusters of many
+// managers at once. All the statuses are computed with UpdateClusterStatus
+// first, then the BareMetalClusters are patched with the given client. A
+// failure for a cluster does not prevent the others from being updated; the
+// errors of all the clusters are returned as an aggregate. A request to
+// requeue, e.g. while the endpoint is not reachable yet, is not an error.
+func BatchUpdateStatus(ctx context.Context, c client.Client,
+	managers []*ClusterManager) error {
+
+	errs := []error{}
+	helpers := make([]*patch.Helper, len(managers))
+	for i, s := range managers {
+		helper, err := patch.NewHelper(s.BareMetalCluster, c)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err,
+				"failed to init patch helper for BareMetalCluster %s/%s",
+				s.BareMetalCluster.Namespace, s.BareMetalCluster.Name,
+			))
+			continue
+		}
+		helpers[i] = helper
+
+		if err := s.UpdateClusterStatus(ctx); err != nil {
+			if _, ok := errors.Cause(err).(HasRequeueAfterError); !ok {
+				errs = append(errs, errors.Wrapf(err,
+					"failed to update the status of BareMetalCluster %s/%s",
+					s.BareMetalCluster.Namespace, s.BareMetalCluster.Name,
+				))
+			}
+		}
+	}
+
+	// The status is patched even if it could not be computed, to persist the
+	// error set on the BareMetalCluster
+	for i, s := range managers {
+		if helpers[i] == nil {
+			continue
+		}
+		if err := helpers[i].Patch(ctx, s.BareMetalCluster); err != nil {
+			errs = append(errs, errors.Wrapf(err,
+				"failed to patch BareMetalCluster %s/%s",
+				s.BareMetalCluster.Namespace, s.BareMetalCluster.Name,
+			))
+		}
+	}
+
+	return kerrors.NewAggregate(errs)
+}
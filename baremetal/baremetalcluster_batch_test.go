@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/klogr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalCluster batch status update", func() {
+
+	newNamedCluster := func(name string) *infrav1.BareMetalCluster {
+		bmCluster := newBareMetalCluster(name, bmcOwnerRef, bmcSpec(), nil)
+		bmCluster.Name = name
+		return bmCluster
+	}
+
+	newManager := func(c client.Client, bmCluster *infrav1.BareMetalCluster) *ClusterManager {
+		clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+			bmCluster, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		return clusterMgr.(*ClusterManager)
+	}
+
+	It("Updates the status of all the clusters", func() {
+		bmClusters := []*infrav1.BareMetalCluster{
+			newNamedCluster("bmc-0"),
+			newNamedCluster("bmc-1"),
+		}
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(),
+			bmClusters[0], bmClusters[1],
+		)
+		managers := []*ClusterManager{
+			newManager(c, bmClusters[0]), newManager(c, bmClusters[1]),
+		}
+
+		Expect(BatchUpdateStatus(context.TODO(), c, managers)).To(Succeed())
+
+		for _, s := range managers {
+			savedCluster := infrav1.BareMetalCluster{}
+			Expect(c.Get(context.TODO(), client.ObjectKey{
+				Name:      s.BareMetalCluster.Name,
+				Namespace: s.BareMetalCluster.Namespace,
+			}, &savedCluster)).To(Succeed())
+			Expect(savedCluster.Status.Ready).To(BeTrue())
+			Expect(savedCluster.Status.APIEndpoints).NotTo(BeEmpty())
+		}
+	})
+
+	It("Collects the errors of the clusters that fail to patch", func() {
+		storedCluster := newNamedCluster("bmc-0")
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(), storedCluster)
+		stored := newManager(c, storedCluster)
+		// Never created, so patching it fails
+		missing := newManager(c, newNamedCluster("bmc-1"))
+
+		err := BatchUpdateStatus(context.TODO(), c,
+			[]*ClusterManager{missing, stored},
+		)
+		Expect(err).To(HaveOccurred())
+		aggregate, ok := err.(kerrors.Aggregate)
+		Expect(ok).To(BeTrue())
+		Expect(aggregate.Errors()).To(HaveLen(1))
+		Expect(aggregate.Error()).To(ContainSubstring("bmc-1"))
+
+		savedCluster := infrav1.BareMetalCluster{}
+		Expect(c.Get(context.TODO(), client.ObjectKey{
+			Name:      stored.BareMetalCluster.Name,
+			Namespace: stored.BareMetalCluster.Namespace,
+		}, &savedCluster)).To(Succeed())
+		Expect(savedCluster.Status.Ready).To(BeTrue())
+	})
+})
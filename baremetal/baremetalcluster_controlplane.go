@@ -0,0 +1,55 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+// EnsureControlPlaneInitialized sets Status.ControlPlaneInitialized once a
+// control plane Machine of the cluster is in the Provisioned or Running
+// phase, and returns its value. The flag is never cleared, so the Machines
+// are not listed again once it is set. A missing owner Cluster has no
+// control plane.
+func (s *ClusterManager) EnsureControlPlaneInitialized(ctx context.Context) (bool, error) {
+	if s.BareMetalCluster.Status.ControlPlaneInitialized {
+		return true, nil
+	}
+
+	machines, err := s.listControlPlaneDescendants(ctx)
+	if apierrors.IsNotFound(errors.Cause(err)) {
+		// The owner Cluster is gone, or not created yet, so is its control
+		// plane
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, machine := range machines.Items {
+		switch capi.MachinePhase(machine.Status.Phase) {
+		case capi.MachinePhaseProvisioned, capi.MachinePhaseRunning:
+			s.Log.Info("Control plane initialized", "machine", machine.Name)
+			s.BareMetalCluster.Status.ControlPlaneInitialized = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
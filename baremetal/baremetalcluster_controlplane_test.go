@@ -0,0 +1,137 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalCluster control plane initialization", func() {
+
+	type testCaseControlPlaneInitialized struct {
+		ControlPlanePhase   clusterv1.MachinePhase
+		WorkerPhase         clusterv1.MachinePhase
+		Initialized         bool
+		ExpectedInitialized bool
+	}
+
+	DescribeTable("Test EnsureControlPlaneInitialized",
+		func(tc testCaseControlPlaneInitialized) {
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				bmcSpec(), nil,
+			)
+			bmCluster.Status.ControlPlaneInitialized = tc.Initialized
+			objects := []runtime.Object{newCluster(clusterName), bmCluster}
+			if tc.ControlPlanePhase != "" {
+				machine := newDescendantMachine("cp-0",
+					clusterv1.MachineControlPlaneLabelName,
+				)
+				machine.Status.SetTypedPhase(tc.ControlPlanePhase)
+				objects = append(objects, machine)
+			}
+			if tc.WorkerPhase != "" {
+				machine := newDescendantMachine("worker-0", "")
+				machine.Status.SetTypedPhase(tc.WorkerPhase)
+				objects = append(objects, machine)
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+			clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+				bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			initialized, err := clusterMgr.EnsureControlPlaneInitialized(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(initialized).To(Equal(tc.ExpectedInitialized))
+			Expect(bmCluster.Status.ControlPlaneInitialized).To(Equal(
+				tc.ExpectedInitialized,
+			))
+		},
+		Entry("No machines", testCaseControlPlaneInitialized{}),
+		Entry("Control plane provisioning", testCaseControlPlaneInitialized{
+			ControlPlanePhase: clusterv1.MachinePhaseProvisioning,
+		}),
+		Entry("Worker provisioned", testCaseControlPlaneInitialized{
+			ControlPlanePhase: clusterv1.MachinePhaseProvisioning,
+			WorkerPhase:       clusterv1.MachinePhaseProvisioned,
+		}),
+		Entry("Control plane provisioned", testCaseControlPlaneInitialized{
+			ControlPlanePhase:   clusterv1.MachinePhaseProvisioned,
+			ExpectedInitialized: true,
+		}),
+		Entry("Control plane running", testCaseControlPlaneInitialized{
+			ControlPlanePhase:   clusterv1.MachinePhaseRunning,
+			ExpectedInitialized: true,
+		}),
+		Entry("Already initialized", testCaseControlPlaneInitialized{
+			Initialized:         true,
+			ExpectedInitialized: true,
+		}),
+	)
+
+	It("Flips the flag once a control plane machine is provisioned", func() {
+		spec := bmcSpec()
+		spec.RequireAllMachinesReady = true
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			spec, nil,
+		)
+		machine := newDescendantMachine("cp-0",
+			clusterv1.MachineControlPlaneLabelName,
+		)
+		machine.Status.SetTypedPhase(clusterv1.MachinePhaseProvisioning)
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(),
+			newCluster(clusterName), bmCluster, machine,
+		)
+		clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+			bmCluster, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Status.ControlPlaneInitialized).To(BeFalse())
+		Expect(bmCluster.Status.Ready).To(BeFalse())
+
+		machine.Status.SetTypedPhase(clusterv1.MachinePhaseProvisioned)
+		Expect(c.Update(context.TODO(), machine)).To(Succeed())
+
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Status.ControlPlaneInitialized).To(BeTrue())
+		Expect(bmCluster.Status.Ready).To(BeTrue())
+
+		// A new machine does not make the initialized cluster not Ready
+		worker := newDescendantMachine("worker-0", "")
+		worker.Status.SetTypedPhase(clusterv1.MachinePhaseProvisioning)
+		Expect(c.Create(context.TODO(), worker)).To(Succeed())
+
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Status.Ready).To(BeTrue())
+
+		// The flag is not cleared when the control plane machine goes away
+		Expect(c.Delete(context.TODO(), machine)).To(Succeed())
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Status.ControlPlaneInitialized).To(BeTrue())
+	})
+})
@@ -0,0 +1,199 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/patch"
+)
+
+const (
+	// DefaultDeleteAttempts is the number of times DeleteDescendants attempts
+	// to delete each Machine, when DeleteAttempts is unset.
+	DefaultDeleteAttempts = 3
+	// deleteBackoff is the base delay between two deletion attempts of a
+	// Machine.
+	deleteBackoff = 200 * time.Millisecond
+	// minPollInterval is the shortest delay between two deletion attempts of
+	// DeleteWithTimeout, or two counts of WaitForDescendantsGone.
+	minPollInterval = time.Second
+)
+
+// DeleteWithTimeout runs ReconcileDelete, every PollInterval, until the
+// BareMetalCluster has no descendants left and is deleted, or until d has
+// elapsed. It returns the number of descendants remaining, zero once the
+// deletion completed. On timeout, an error is returned with the count and
+// what the deletion waits for, e.g. the end of a pause. The BareMetalCluster
+// is patched before returning, which persists the removal of the finalizer.
+func (s *ClusterManager) DeleteWithTimeout(ctx context.Context, d time.Duration) (remaining int, rerr error) {
+	helper, err := patch.NewHelper(s.BareMetalCluster, s.client)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to init patch helper")
+	}
+	defer func() {
+		err := helper.Patch(ctx, s.BareMetalCluster)
+		// The BareMetalCluster is gone once its finalizer is removed
+		if err != nil && !IsNotFoundError(err) && rerr == nil {
+			rerr = errors.Wrapf(err, "failed to patch BareMetalCluster %s/%s",
+				s.BareMetalCluster.Namespace, s.BareMetalCluster.Name,
+			)
+		}
+	}()
+
+	clk := s.getClock()
+	deadline := clk.Now().Add(d)
+	for {
+		result, progress, err := s.reconcileDelete(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if result.IsZero() {
+			return 0, nil
+		}
+
+		if !clk.Now().Before(deadline) {
+			return progress.remaining, errors.Errorf(
+				"timed out after %s deleting BareMetalCluster %s/%s, %d descendants remain, waiting for %s",
+				d, s.BareMetalCluster.Namespace, s.BareMetalCluster.Name,
+				progress.remaining, progress.waitingFor,
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return progress.remaining, ctx.Err()
+		case <-clk.After(s.pollInterval()):
+		}
+	}
+}
+
+// WaitForDescendantsGone blocks until the cluster has no descendants left,
+// counting them every interval, PollInterval if zero. If ctx is cancelled
+// first, the last count is returned with the error of ctx.
+func (s *ClusterManager) WaitForDescendantsGone(ctx context.Context, interval time.Duration) (int, error) {
+	if interval <= 0 {
+		interval = s.pollInterval()
+	}
+	if interval < minPollInterval {
+		interval = minPollInterval
+	}
+	clk := s.getClock()
+	for {
+		remaining, err := s.CountDescendants(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if remaining == 0 {
+			return 0, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return remaining, ctx.Err()
+		case <-clk.After(interval):
+		}
+	}
+}
+
+// DeleteDescendants deletes the Machines of the cluster. The deletion of each
+// Machine is attempted up to DeleteAttempts times, with a linearly increasing
+// backoff, and a failure does not prevent the other Machines from being
+// deleted. The Machines that could not be deleted are listed in the returned
+// aggregate error. It stops early if ctx is cancelled.
+func (s *ClusterManager) DeleteDescendants(ctx context.Context) error {
+	descendants, err := s.listDescendants(ctx)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for i := range descendants.Items {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, errors.Wrapf(err, "%d Machines left undeleted",
+				len(descendants.Items)-i,
+			))
+			break
+		}
+		machine := &descendants.Items[i]
+		if err := s.deleteMachine(ctx, machine); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to delete Machine %s/%s",
+				machine.Namespace, machine.Name,
+			))
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// deleteMachine deletes the Machine, retrying up to DeleteAttempts times. A
+// Machine already gone is not an error.
+func (s *ClusterManager) deleteMachine(ctx context.Context, machine *capi.Machine) error {
+	clk := s.getClock()
+	for attempt := 1; ; attempt++ {
+		err := s.client.Delete(ctx, machine)
+		if err == nil || apierrors.IsNotFound(err) {
+			return nil
+		}
+		if attempt >= s.deleteAttempts() {
+			return err
+		}
+		s.Log.Info("Failed to delete Machine, retrying", "machine",
+			machine.Name, "attempt", attempt, "error", err.Error(),
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clk.After(time.Duration(attempt) * deleteBackoff):
+		}
+	}
+}
+
+// deleteAttempts returns the number of deletion attempts of each Machine,
+// DefaultDeleteAttempts if unset.
+func (s *ClusterManager) deleteAttempts() int {
+	if s.DeleteAttempts > 0 {
+		return s.DeleteAttempts
+	}
+	return DefaultDeleteAttempts
+}
+
+// pollInterval returns the delay between the deletion attempts, RequeueAfter
+// if unset, and at least minPollInterval.
+func (s *ClusterManager) pollInterval() time.Duration {
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = s.RequeueAfter
+	}
+	if interval < minPollInterval {
+		return minPollInterval
+	}
+	return interval
+}
+
+// getClock returns the clock of the ClusterManager, the real clock if unset.
+func (s *ClusterManager) getClock() clock.Clock {
+	if s.Clock == nil {
+		return clock.RealClock{}
+	}
+	return s.Clock
+}
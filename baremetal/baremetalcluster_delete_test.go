@@ -0,0 +1,275 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/klog/klogr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalCluster deletion with a timeout", func() {
+	pollInterval := 10 * time.Second
+
+	// stepClock advances the clock by pollInterval every time
+	// DeleteWithTimeout waits on it, running onWait first, until done is
+	// closed.
+	stepClock := func(fakeClock *clock.FakeClock, onWait func(), done chan struct{}) {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if fakeClock.HasWaiters() {
+				onWait()
+				fakeClock.Step(pollInterval)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	newDeleteSetup := func() (client.Client, *infrav1.BareMetalCluster,
+		*clock.FakeClock, *ClusterManager) {
+
+		cluster := newCluster(clusterName)
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), nil,
+		)
+		bmCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(), cluster,
+			bmCluster, newDescendantMachine("machine-0", ""),
+		)
+		fakeClock := clock.NewFakeClock(time.Now())
+		clusterMgr, err := NewClusterManager(c, cluster, bmCluster,
+			klogr.New(), WithClock(fakeClock), WithPollInterval(pollInterval),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		return c, bmCluster, fakeClock, clusterMgr.(*ClusterManager)
+	}
+
+	It("Completes once the descendants are gone", func() {
+		c, bmCluster, fakeClock, clusterMgr := newDeleteSetup()
+
+		done := make(chan struct{})
+		defer close(done)
+		go stepClock(fakeClock, func() {
+			_ = c.Delete(context.TODO(), newDescendantMachine("machine-0", ""))
+		}, done)
+
+		remaining, err := clusterMgr.DeleteWithTimeout(context.TODO(),
+			time.Minute,
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remaining).To(BeZero())
+		Expect(bmCluster.Finalizers).NotTo(ContainElement(infrav1.ClusterFinalizer))
+
+		// The removal of the finalizer is persisted
+		persisted := &infrav1.BareMetalCluster{}
+		Expect(c.Get(context.TODO(), client.ObjectKey{
+			Name:      bmCluster.Name,
+			Namespace: bmCluster.Namespace,
+		}, persisted)).To(Succeed())
+		Expect(persisted.Finalizers).NotTo(ContainElement(infrav1.ClusterFinalizer))
+	})
+
+	It("Returns the remaining descendants on timeout", func() {
+		c, bmCluster, fakeClock, clusterMgr := newDeleteSetup()
+
+		done := make(chan struct{})
+		defer close(done)
+		go stepClock(fakeClock, func() {}, done)
+
+		remaining, err := clusterMgr.DeleteWithTimeout(context.TODO(),
+			time.Minute,
+		)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("waiting for 1 descendants to be deleted"))
+		Expect(remaining).To(Equal(1))
+		Expect(bmCluster.Finalizers).To(ContainElement(infrav1.ClusterFinalizer))
+
+		persisted := &infrav1.BareMetalCluster{}
+		Expect(c.Get(context.TODO(), client.ObjectKey{
+			Name:      bmCluster.Name,
+			Namespace: bmCluster.Namespace,
+		}, persisted)).To(Succeed())
+		Expect(persisted.Finalizers).To(ContainElement(infrav1.ClusterFinalizer))
+	})
+
+	It("Reports the pause on timeout", func() {
+		c, bmCluster, fakeClock, clusterMgr := newDeleteSetup()
+		Expect(c.Delete(context.TODO(), newDescendantMachine("machine-0", ""))).
+			To(Succeed())
+		clusterMgr.Cluster.Spec.Paused = true
+
+		done := make(chan struct{})
+		defer close(done)
+		go stepClock(fakeClock, func() {}, done)
+
+		remaining, err := clusterMgr.DeleteWithTimeout(context.TODO(),
+			time.Minute,
+		)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("0 descendants remain"))
+		Expect(err.Error()).To(ContainSubstring("waiting for the reconciliation to be unpaused"))
+		Expect(remaining).To(BeZero())
+		Expect(bmCluster.Finalizers).To(ContainElement(infrav1.ClusterFinalizer))
+	})
+
+	It("Polls at least every minPollInterval", func() {
+		_, _, _, clusterMgr := newDeleteSetup()
+		clusterMgr.PollInterval = 0
+		clusterMgr.RequeueAfter = 0
+		Expect(clusterMgr.pollInterval()).To(Equal(minPollInterval))
+	})
+
+	It("Waits until the descendants are gone", func() {
+		c, _, fakeClock, clusterMgr := newDeleteSetup()
+
+		done := make(chan struct{})
+		defer close(done)
+		go stepClock(fakeClock, func() {
+			_ = c.Delete(context.TODO(), newDescendantMachine("machine-0", ""))
+		}, done)
+
+		remaining, err := clusterMgr.WaitForDescendantsGone(context.TODO(),
+			pollInterval,
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remaining).To(BeZero())
+	})
+
+	It("Returns the last count when the wait is cancelled", func() {
+		_, _, fakeClock, clusterMgr := newDeleteSetup()
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		done := make(chan struct{})
+		defer close(done)
+		go stepClock(fakeClock, cancel, done)
+
+		remaining, err := clusterMgr.WaitForDescendantsGone(ctx, 0)
+		Expect(err).To(Equal(context.Canceled))
+		Expect(remaining).To(Equal(1))
+	})
+
+	It("Deletes the descendants past individual failures", func() {
+		cluster := newCluster(clusterName)
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), nil,
+		)
+		c := &failingDeleteClient{
+			Client: fakeclient.NewFakeClientWithScheme(setupScheme(), cluster,
+				bmCluster, newDescendantMachine("machine-0", ""),
+				newDescendantMachine("machine-1", ""),
+				newDescendantMachine("machine-2", ""),
+			),
+			// machine-0 fails twice then succeeds, machine-1 always fails
+			failures: map[string]int{"machine-0": 2, "machine-1": -1},
+		}
+		clusterMgr, err := NewClusterManager(c, cluster, bmCluster,
+			klogr.New(), WithClock(instantClock{clock.NewFakeClock(time.Now())}),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = clusterMgr.DeleteDescendants(context.TODO())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("machine-1"))
+		Expect(err.Error()).NotTo(ContainSubstring("machine-0"))
+		Expect(c.attempts).To(Equal(map[string]int{
+			"machine-0": 3, "machine-1": DefaultDeleteAttempts, "machine-2": 1,
+		}))
+
+		machines := clusterv1.MachineList{}
+		Expect(c.List(context.TODO(), &machines)).To(Succeed())
+		Expect(machineNames(machines)).To(ConsistOf(
+			namespaceName + "/machine-1",
+		))
+	})
+
+	It("Stops deleting the descendants once the context is cancelled", func() {
+		cluster := newCluster(clusterName)
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), nil,
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(), cluster,
+			bmCluster, newDescendantMachine("machine-0", ""),
+		)
+		clusterMgr, err := NewClusterManager(c, cluster, bmCluster,
+			klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+		// The Machines are listed before the context is checked
+		err = clusterMgr.DeleteDescendants(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("1 Machines left undeleted"))
+
+		machines := clusterv1.MachineList{}
+		Expect(c.List(context.TODO(), &machines)).To(Succeed())
+		Expect(machines.Items).To(HaveLen(1))
+	})
+})
+
+// failingDeleteClient fails the deletion of the objects named in failures, the
+// given number of times, or always for a negative number.
+type failingDeleteClient struct {
+	client.Client
+	failures map[string]int
+	attempts map[string]int
+}
+
+func (c *failingDeleteClient) Delete(ctx context.Context, obj runtime.Object,
+	opts ...client.DeleteOption) error {
+
+	name := obj.(*clusterv1.Machine).Name
+	if c.attempts == nil {
+		c.attempts = map[string]int{}
+	}
+	c.attempts[name]++
+	if c.failures[name] != 0 {
+		if c.failures[name] > 0 {
+			c.failures[name]--
+		}
+		return apierrors.NewInternalError(fmt.Errorf("failed to delete %s", name))
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+// instantClock is a fake clock whose timers fire immediately.
+type instantClock struct {
+	*clock.FakeClock
+}
+
+func (instantClock) After(time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+	return ch
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ClusterReadyCondition is the condition reporting the readiness of a
+	// BareMetalCluster in its ClusterDescription.
+	ClusterReadyCondition capm3.ConditionType = "Ready"
+	// DescribeEventCount is the number of events, the most recent ones, kept
+	// in a ClusterDescription.
+	DescribeEventCount = 10
+)
+
+// EventLister lists the events involving an object.
+type EventLister interface {
+	ListEvents(ctx context.Context, obj metav1.Object) ([]corev1.Event, error)
+}
+
+// ClientEventLister is an EventLister listing the events with a client.
+type ClientEventLister struct {
+	Client client.Client
+}
+
+// ListEvents implements EventLister. The events of the namespace of the
+// object are listed, and those involving another object are dropped.
+func (l ClientEventLister) ListEvents(ctx context.Context, obj metav1.Object) ([]corev1.Event, error) {
+	eventList := corev1.EventList{}
+	if err := l.Client.List(ctx, &eventList,
+		client.InNamespace(obj.GetNamespace()),
+	); err != nil {
+		return nil, err
+	}
+
+	events := []corev1.Event{}
+	for _, event := range eventList.Items {
+		if event.InvolvedObject.Name != obj.GetName() {
+			continue
+		}
+		if obj.GetUID() != "" && event.InvolvedObject.UID != obj.GetUID() {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ClusterDescription is the detailed state of a BareMetalCluster, e.g. for a
+// status command.
+type ClusterDescription struct {
+	// Summary is the state of the BareMetalCluster at a glance, including
+	// its failure message and number of Machines.
+	Summary ClusterSummary
+	// Conditions are the conditions of the BareMetalCluster.
+	Conditions capm3.Conditions
+	// FailureReason is the reason of the terminal failure of the
+	// BareMetalCluster, if any.
+	FailureReason string
+	// Events are the most recent events involving the BareMetalCluster, the
+	// newest first. Nil when no EventLister is set.
+	Events []corev1.Event
+}
+
+// Describe returns the ClusterDescription of the BareMetalCluster. The events
+// are only listed if an EventLister is set.
+func (s *ClusterManager) Describe(ctx context.Context) (ClusterDescription, error) {
+	summary, err := s.Summary(ctx)
+	if err != nil {
+		return ClusterDescription{}, err
+	}
+
+	status := s.BareMetalCluster.Status
+	description := ClusterDescription{
+		Summary:    summary,
+		Conditions: capm3.Conditions{s.readyCondition()},
+	}
+	if status.FailureReason != nil {
+		description.FailureReason = string(*status.FailureReason)
+	}
+
+	if s.EventLister == nil {
+		return description, nil
+	}
+	events, err := s.EventLister.ListEvents(ctx, s.BareMetalCluster)
+	if err != nil {
+		return ClusterDescription{}, errors.Wrap(err, "failed to list the events")
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).After(eventTime(events[j]))
+	})
+	if len(events) > DescribeEventCount {
+		events = events[:DescribeEventCount]
+	}
+	description.Events = events
+	return description, nil
+}
+
+// readyCondition returns the ClusterReadyCondition of the BareMetalCluster,
+// built from Ready, ReadyReason and ReadySince.
+func (s *ClusterManager) readyCondition() capm3.Condition {
+	status := s.BareMetalCluster.Status
+	condition := capm3.Condition{
+		Type:   ClusterReadyCondition,
+		Status: corev1.ConditionFalse,
+		Reason: status.ReadyReason,
+	}
+	if status.Ready {
+		condition.Status = corev1.ConditionTrue
+	}
+	if status.ReadySince != nil {
+		condition.LastTransitionTime = *status.ReadySince
+	}
+	return condition
+}
+
+// eventTime returns the last time the event occurred.
+func eventTime(event corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	return event.FirstTimestamp.Time
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	"k8s.io/utils/pointer"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalCluster description", func() {
+
+	readySince := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+
+	newEvent := func(name, involvedName string, age time.Duration) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespaceName,
+			},
+			InvolvedObject: corev1.ObjectReference{
+				Kind:      "BareMetalCluster",
+				Name:      involvedName,
+				Namespace: namespaceName,
+			},
+			Reason:        name,
+			LastTimestamp: metav1.NewTime(time.Now().Add(-age).Truncate(time.Second)),
+		}
+	}
+
+	describe := func(bmCluster *infrav1.BareMetalCluster,
+		objects []runtime.Object, withEvents bool) ClusterDescription {
+		objects = append(objects, newCluster(clusterName), bmCluster,
+			newDescendantMachine("machine-a", ""),
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+		opts := []Option{}
+		if withEvents {
+			opts = append(opts, WithEventLister(ClientEventLister{Client: c}))
+		}
+		clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+			bmCluster, klogr.New(), opts...,
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		description, err := clusterMgr.Describe(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		return description
+	}
+
+	It("Describes a Ready cluster without events", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), &infrav1.BareMetalClusterStatus{
+				Ready:       true,
+				ReadySince:  &readySince,
+				ReadyReason: infrav1.ReadyReasonEndpointValidated,
+			},
+		)
+
+		description := describe(bmCluster, []runtime.Object{
+			newEvent("Provisioned", baremetalClusterName, time.Minute),
+		}, false)
+
+		Expect(description).To(Equal(ClusterDescription{
+			Summary: ClusterSummary{
+				Ready:       true,
+				EndpointURL: "https://192.168.111.249:6443",
+				Descendants: 1,
+			},
+			Conditions: infrav1.Conditions{{
+				Type:               ClusterReadyCondition,
+				Status:             corev1.ConditionTrue,
+				Reason:             infrav1.ReadyReasonEndpointValidated,
+				LastTransitionTime: readySince,
+			}},
+		}))
+	})
+
+	It("Describes a failed cluster with its events", func() {
+		failureReason := capierrors.InvalidConfigurationClusterError
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpecAPIEmpty(), &infrav1.BareMetalClusterStatus{
+				FailureReason:  &failureReason,
+				FailureMessage: pointer.StringPtr("Invalid ControlPlaneEndpoint values"),
+				ReadyReason:    infrav1.ReadyReasonEndpointMissing,
+			},
+		)
+
+		description := describe(bmCluster, []runtime.Object{
+			newEvent("Older", baremetalClusterName, time.Hour),
+			newEvent("Newer", baremetalClusterName, time.Minute),
+			newEvent("Unrelated", "other-cluster", time.Second),
+		}, true)
+
+		Expect(description.Summary.FailureMessage).
+			To(Equal("Invalid ControlPlaneEndpoint values"))
+		Expect(description.Summary.Descendants).To(Equal(1))
+		Expect(description.FailureReason).
+			To(Equal(string(capierrors.InvalidConfigurationClusterError)))
+		Expect(description.Conditions).To(Equal(infrav1.Conditions{{
+			Type:   ClusterReadyCondition,
+			Status: corev1.ConditionFalse,
+			Reason: infrav1.ReadyReasonEndpointMissing,
+		}}))
+		reasons := []string{}
+		for _, event := range description.Events {
+			reasons = append(reasons, event.Reason)
+		}
+		Expect(reasons).To(Equal([]string{"Newer", "Older"}))
+	})
+
+	It("Keeps the most recent events only", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), nil,
+		)
+		events := []runtime.Object{}
+		for i := 0; i < DescribeEventCount+2; i++ {
+			events = append(events, newEvent("event-"+string(rune('a'+i)),
+				baremetalClusterName, time.Duration(i)*time.Minute,
+			))
+		}
+
+		description := describe(bmCluster, events, true)
+
+		Expect(description.Events).To(HaveLen(DescribeEventCount))
+		Expect(description.Events[0].Reason).To(Equal("event-a"))
+	})
+})
@@ -0,0 +1,45 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+)
+
+// EndpointHook is notified of the ControlPlaneEndpoint of the
+// BareMetalClusters, e.g. to update the DNS records of an external-DNS
+// integration.
+type EndpointHook interface {
+	// OnEndpointReady is called when the BareMetalCluster becomes Ready, and
+	// when its ControlPlaneEndpoint changes while it is Ready.
+	OnEndpointReady(endpoint capm3.APIEndpoint)
+}
+
+// NoopEndpointHook is the EndpointHook used by default. It does nothing.
+type NoopEndpointHook struct{}
+
+// OnEndpointReady implements EndpointHook.
+func (NoopEndpointHook) OnEndpointReady(capm3.APIEndpoint) {}
+
+// endpointHook returns the EndpointHook of the ClusterManager, the no-op one
+// if unset.
+func (s *ClusterManager) endpointHook() EndpointHook {
+	if s.EndpointHook == nil {
+		return NoopEndpointHook{}
+	}
+	return s.EndpointHook
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"k8s.io/klog/klogr"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// recordingEndpointHook records the endpoints it is notified of.
+type recordingEndpointHook struct {
+	endpoints []infrav1.APIEndpoint
+}
+
+func (h *recordingEndpointHook) OnEndpointReady(endpoint infrav1.APIEndpoint) {
+	h.endpoints = append(h.endpoints, endpoint)
+}
+
+var _ = Describe("BareMetalCluster endpoint hook", func() {
+
+	It("Fires once per change of the endpoint", func() {
+		hook := &recordingEndpointHook{}
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), nil,
+		)
+		clusterMgr, err := NewClusterManager(
+			fakeclient.NewFakeClientWithScheme(setupScheme()),
+			newCluster(clusterName), bmCluster, klogr.New(),
+			WithEndpointHook(hook),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Becoming Ready
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Status.Ready).To(BeTrue())
+		Expect(hook.endpoints).To(Equal([]infrav1.APIEndpoint{
+			bmcSpec().ControlPlaneEndpoint,
+		}))
+
+		// Nothing changed
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(hook.endpoints).To(HaveLen(1))
+
+		// The endpoint changed while Ready
+		newEndpoint := infrav1.APIEndpoint{Host: "192.168.111.250", Port: 6443}
+		bmCluster.Spec.ControlPlaneEndpoint = newEndpoint
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(hook.endpoints).To(Equal([]infrav1.APIEndpoint{
+			bmcSpec().ControlPlaneEndpoint, newEndpoint,
+		}))
+
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(hook.endpoints).To(HaveLen(2))
+	})
+
+	It("Does not fire while the cluster is not Ready", func() {
+		hook := &recordingEndpointHook{}
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpecAPIEmpty(), nil,
+		)
+		clusterMgr, err := NewClusterManager(
+			fakeclient.NewFakeClientWithScheme(setupScheme()),
+			newCluster(clusterName), bmCluster, klogr.New(),
+			WithEndpointHook(hook),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).NotTo(Succeed())
+		Expect(bmCluster.Status.Ready).To(BeFalse())
+		Expect(hook.endpoints).To(BeEmpty())
+	})
+})
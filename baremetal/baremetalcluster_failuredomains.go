@@ -0,0 +1,56 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	"github.com/pkg/errors"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FailureDomains sets Status.FailureDomains to the distinct failure domains
+// of the BareMetalHosts of the namespace, consumed or not, as given by their
+// FailureDomainLabel, and returns them. Cluster API copies them to the
+// Cluster to spread the Machines, the control plane ones included. Hosts
+// without the label are in no failure domain.
+func (s *ClusterManager) FailureDomains(ctx context.Context) (capi.FailureDomains, error) {
+	hosts := bmh.BareMetalHostList{}
+	opts := &client.ListOptions{
+		Namespace: s.BareMetalCluster.Namespace,
+	}
+	if err := s.client.List(ctx, &hosts, opts); err != nil {
+		return nil, errors.Wrapf(err, "failed to list BareMetalHosts in %s",
+			s.BareMetalCluster.Namespace,
+		)
+	}
+
+	failureDomains := capi.FailureDomains{}
+	for _, host := range hosts.Items {
+		if name, ok := host.Labels[FailureDomainLabel]; ok && name != "" {
+			failureDomains[name] = capi.FailureDomainSpec{ControlPlane: true}
+		}
+	}
+
+	if len(failureDomains) == 0 {
+		failureDomains = nil
+	}
+	s.BareMetalCluster.Status.FailureDomains = failureDomains
+	return failureDomains, nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalCluster failure domains", func() {
+
+	newRackHost := func(name, label, rack string, state bmh.ProvisioningState,
+		consumed bool) *bmh.BareMetalHost {
+
+		host := newAvailabilityHost(name, namespaceName, state, consumed)
+		if rack != "" {
+			host.Labels = map[string]string{label: rack}
+		}
+		return host
+	}
+
+	type testCaseFailureDomains struct {
+		Label                  string
+		Hosts                  []*bmh.BareMetalHost
+		ExpectedFailureDomains clusterv1.FailureDomains
+	}
+
+	DescribeTable("Test FailureDomains",
+		func(tc testCaseFailureDomains) {
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				bmcSpec(), nil,
+			)
+			objects := []runtime.Object{bmCluster}
+			for _, host := range tc.Hosts {
+				objects = append(objects, host)
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+			if tc.Label != "" {
+				FailureDomainLabel = tc.Label
+				defer func() { FailureDomainLabel = DefaultFailureDomainLabel }()
+			}
+			clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+				bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			failureDomains, err := clusterMgr.FailureDomains(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failureDomains).To(Equal(tc.ExpectedFailureDomains))
+			Expect(bmCluster.Status.FailureDomains).To(Equal(
+				tc.ExpectedFailureDomains,
+			))
+		},
+		Entry("No hosts", testCaseFailureDomains{}),
+		Entry("Hosts in two racks", testCaseFailureDomains{
+			Hosts: []*bmh.BareMetalHost{
+				newRackHost("host-0", FailureDomainLabel, "rack-1",
+					bmh.StateReady, false,
+				),
+				newRackHost("host-1", FailureDomainLabel, "rack-1",
+					bmh.StateAvailable, false,
+				),
+				newRackHost("host-2", FailureDomainLabel, "rack-2",
+					bmh.StateReady, false,
+				),
+				newRackHost("unlabelled", FailureDomainLabel, "",
+					bmh.StateReady, false,
+				),
+				newRackHost("consumed", FailureDomainLabel, "rack-3",
+					bmh.StateProvisioned, true,
+				),
+			},
+			ExpectedFailureDomains: clusterv1.FailureDomains{
+				"rack-1": clusterv1.FailureDomainSpec{ControlPlane: true},
+				"rack-2": clusterv1.FailureDomainSpec{ControlPlane: true},
+				"rack-3": clusterv1.FailureDomainSpec{ControlPlane: true},
+			},
+		}),
+		Entry("Custom label", testCaseFailureDomains{
+			Label: "example.com/rack",
+			Hosts: []*bmh.BareMetalHost{
+				newRackHost("host-0", "example.com/rack", "rack-1",
+					bmh.StateReady, false,
+				),
+				newRackHost("host-1", DefaultFailureDomainLabel, "rack-2",
+					bmh.StateReady, false,
+				),
+			},
+			ExpectedFailureDomains: clusterv1.FailureDomains{
+				"rack-1": clusterv1.FailureDomainSpec{ControlPlane: true},
+			},
+		}),
+	)
+})
@@ -0,0 +1,191 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
+	clientcmdv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// KubeconfigSecretPurpose is the suffix of the Secret, named after the
+	// Cluster, where StoreKubeconfig saves the kubeconfig. The kubeconfig is
+	// under the secret.KubeconfigDataName key.
+	KubeconfigSecretPurpose secret.Purpose = "baremetal-kubeconfig"
+)
+
+// GenerateKubeconfig builds an admin kubeconfig for the cluster. It points
+// at the first of the Status APIEndpoints and uses a client certificate
+// signed by the cluster CA, read from the Cluster API CA Secret.
+func (s *ClusterManager) GenerateKubeconfig(ctx context.Context) ([]byte, error) {
+	if s.Cluster == nil {
+		return nil, errors.New("the owner Cluster is not set")
+	}
+	server, err := s.kubeconfigServer()
+	if err != nil {
+		return nil, err
+	}
+
+	caSecret, err := secret.Get(ctx, s.client, s.Cluster, secret.ClusterCA)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, kubeconfig.ErrDependentCertificateNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get the cluster CA")
+	}
+	caCert, err := certs.DecodeCertPEM(caSecret.Data[secret.TLSCrtDataName])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the cluster CA certificate")
+	} else if caCert == nil {
+		return nil, errors.New("the cluster CA certificate is missing")
+	}
+	caKey, err := certs.DecodePrivateKeyPEM(caSecret.Data[secret.TLSKeyDataName])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the cluster CA private key")
+	} else if caKey == nil {
+		return nil, errors.New("the cluster CA private key is missing")
+	}
+
+	cfg, err := kubeconfig.New(s.Cluster.Name, server, caCert, caKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate the kubeconfig")
+	}
+
+	out := &clientcmdv1.Config{}
+	if err := clientcmdlatest.Scheme.Convert(cfg, out, nil); err != nil {
+		return nil, errors.Wrap(err, "failed to convert the kubeconfig")
+	}
+	out.APIVersion = clientcmdv1.SchemeGroupVersion.Version
+	out.Kind = "Config"
+	data, err := yaml.Marshal(out)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to serialize the kubeconfig")
+	}
+	return data, nil
+}
+
+// kubeconfigServer returns the URL of the API server of the cluster, built
+// from the first of the Status APIEndpoints.
+func (s *ClusterManager) kubeconfigServer() (string, error) {
+	if len(s.BareMetalCluster.Status.APIEndpoints) == 0 {
+		return "", errors.New("the BareMetalCluster has no APIEndpoints yet")
+	}
+	endpoint := s.BareMetalCluster.Status.APIEndpoints[0]
+	if endpoint.Host == "" {
+		return "", errors.New("the first APIEndpoint has no host")
+	}
+	return "https://" + net.JoinHostPort(endpoint.Host,
+		strconv.Itoa(endpoint.Port),
+	), nil
+}
+
+// StoreKubeconfig saves the output of GenerateKubeconfig in the Secret named
+// after KubeconfigSecretPurpose. An existing Secret is left untouched, unless
+// its kubeconfig points at another server than the current APIEndpoint.
+func (s *ClusterManager) StoreKubeconfig(ctx context.Context) error {
+	if s.Cluster == nil {
+		return errors.New("the owner Cluster is not set")
+	}
+	name := secret.Name(s.Cluster.Name, KubeconfigSecretPurpose)
+	existing := &corev1.Secret{}
+	err := s.client.Get(ctx,
+		client.ObjectKey{Name: name, Namespace: s.Cluster.Namespace}, existing,
+	)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to get Secret %s", name)
+	}
+	if err == nil {
+		server, err := s.kubeconfigServer()
+		if err != nil {
+			return err
+		}
+		if storedKubeconfigServer(existing, s.Cluster.Name) == server {
+			return nil
+		}
+		data, err := s.GenerateKubeconfig(ctx)
+		if err != nil {
+			return err
+		}
+		s.Log.Info("Refreshing the kubeconfig for the new endpoint",
+			"secret", name, "server", server,
+		)
+		if existing.Data == nil {
+			existing.Data = map[string][]byte{}
+		}
+		existing.Data[secret.KubeconfigDataName] = data
+		if err := s.client.Update(ctx, existing); err != nil {
+			return errors.Wrapf(err, "failed to update Secret %s", name)
+		}
+		return nil
+	}
+
+	data, err := s.GenerateKubeconfig(ctx)
+	if err != nil {
+		return err
+	}
+	kubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.Cluster.Namespace,
+			Labels: map[string]string{
+				capi.ClusterLabelName: s.Cluster.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: s.BareMetalCluster.APIVersion,
+				Kind:       s.BareMetalCluster.Kind,
+				Name:       s.BareMetalCluster.Name,
+				UID:        s.BareMetalCluster.UID,
+			}},
+		},
+		Data: map[string][]byte{
+			secret.KubeconfigDataName: data,
+		},
+	}
+	if err := s.client.Create(ctx, kubeconfigSecret); err != nil {
+		return errors.Wrapf(err, "failed to create Secret %s", name)
+	}
+	return nil
+}
+
+// storedKubeconfigServer returns the server of the cluster in the kubeconfig
+// stored in the Secret, or an empty string if it can not be read.
+func storedKubeconfigServer(kubeconfigSecret *corev1.Secret, clusterName string) string {
+	cfg, err := clientcmd.Load(kubeconfigSecret.Data[secret.KubeconfigDataName])
+	if err != nil {
+		return ""
+	}
+	cluster, ok := cfg.Clusters[clusterName]
+	if !ok {
+		return ""
+	}
+	return cluster.Server
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/klogr"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalCluster kubeconfig", func() {
+
+	newCASecret := func() *corev1.Secret {
+		certificates := secret.Certificates{
+			&secret.Certificate{Purpose: secret.ClusterCA},
+		}
+		Expect(certificates.Generate()).To(Succeed())
+		return certificates.GetByPurpose(secret.ClusterCA).AsSecret(
+			types.NamespacedName{Name: clusterName, Namespace: namespaceName},
+			*bmcOwnerRef,
+		)
+	}
+
+	type testCaseGenerateKubeconfig struct {
+		Endpoints      []infrav1.APIEndpoint
+		CASecret       bool
+		ExpectError    bool
+		ExpectedServer string
+	}
+
+	DescribeTable("Test GenerateKubeconfig",
+		func(tc testCaseGenerateKubeconfig) {
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				bmcSpec(), &infrav1.BareMetalClusterStatus{
+					APIEndpoints: tc.Endpoints,
+				},
+			)
+			objects := []runtime.Object{newCluster(clusterName), bmCluster}
+			var caSecret *corev1.Secret
+			if tc.CASecret {
+				caSecret = newCASecret()
+				objects = append(objects, caSecret)
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+			clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+				bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := clusterMgr.GenerateKubeconfig(context.TODO())
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+
+			cfg, err := clientcmd.Load(data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Clusters).To(HaveKey(clusterName))
+			Expect(cfg.Clusters[clusterName].Server).To(Equal(tc.ExpectedServer))
+			Expect(cfg.Clusters[clusterName].CertificateAuthorityData).To(Equal(
+				caSecret.Data[secret.TLSCrtDataName],
+			))
+			Expect(cfg.Contexts).To(HaveKey(cfg.CurrentContext))
+		},
+		Entry("CA and endpoint present", testCaseGenerateKubeconfig{
+			Endpoints: []infrav1.APIEndpoint{
+				{Host: "192.168.111.249", Port: 6443},
+				{Host: "192.168.111.250", Port: 6443},
+			},
+			CASecret:       true,
+			ExpectError:    false,
+			ExpectedServer: "https://192.168.111.249:6443",
+		}),
+		Entry("IPv6 endpoint", testCaseGenerateKubeconfig{
+			Endpoints:      []infrav1.APIEndpoint{{Host: "fd00::10", Port: 6443}},
+			CASecret:       true,
+			ExpectError:    false,
+			ExpectedServer: "https://[fd00::10]:6443",
+		}),
+		Entry("CA missing", testCaseGenerateKubeconfig{
+			Endpoints:   []infrav1.APIEndpoint{{Host: "192.168.111.249", Port: 6443}},
+			CASecret:    false,
+			ExpectError: true,
+		}),
+		Entry("Endpoint missing", testCaseGenerateKubeconfig{
+			Endpoints:   nil,
+			CASecret:    true,
+			ExpectError: true,
+		}),
+	)
+
+	It("Stores the kubeconfig once in a well-known Secret", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), &infrav1.BareMetalClusterStatus{
+				APIEndpoints: []infrav1.APIEndpoint{
+					{Host: "192.168.111.249", Port: 6443},
+				},
+			},
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(),
+			newCluster(clusterName), bmCluster, newCASecret(),
+		)
+		clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+			bmCluster, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		key := client.ObjectKey{
+			Name:      clusterName + "-" + string(KubeconfigSecretPurpose),
+			Namespace: namespaceName,
+		}
+		Expect(clusterMgr.StoreKubeconfig(context.TODO())).To(Succeed())
+		stored := &corev1.Secret{}
+		Expect(c.Get(context.TODO(), key, stored)).To(Succeed())
+		Expect(stored.Data).To(HaveKey(secret.KubeconfigDataName))
+
+		// The existing Secret is kept
+		Expect(clusterMgr.StoreKubeconfig(context.TODO())).To(Succeed())
+		storedAgain := &corev1.Secret{}
+		Expect(c.Get(context.TODO(), key, storedAgain)).To(Succeed())
+		Expect(storedAgain.Data).To(Equal(stored.Data))
+
+		// The Secret is refreshed once the endpoint changes
+		bmCluster.Status.APIEndpoints[0].Host = "192.168.111.250"
+		Expect(clusterMgr.StoreKubeconfig(context.TODO())).To(Succeed())
+		refreshed := &corev1.Secret{}
+		Expect(c.Get(context.TODO(), key, refreshed)).To(Succeed())
+		cfg, err := clientcmd.Load(refreshed.Data[secret.KubeconfigDataName])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Clusters[clusterName].Server).To(Equal(
+			"https://192.168.111.250:6443",
+		))
+	})
+})
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/pkg/errors"
+)
+
+// LoadBalancerResolver discovers the ControlPlaneEndpoint of the
+// BareMetalClusters whose endpoint is managed by an external load balancer.
+type LoadBalancerResolver interface {
+	// ResolveEndpoint returns the endpoint the load balancer serves for the
+	// BareMetalCluster, or nil if it is not known yet.
+	ResolveEndpoint(ctx context.Context, bmCluster *capm3.BareMetalCluster) (*capm3.APIEndpoint, error)
+}
+
+// ControlPlaneEndpointContext returns the cluster controlplane endpoint, like
+// ControlPlaneEndpoint. If the spec endpoint is empty and
+// ExternallyManagedEndpoint is set, the endpoint is discovered from the
+// LoadBalancer resolver instead, and a RequeueAfterError is returned until
+// the load balancer knows it. Without a resolver, no endpoint is returned
+// until the one managing the endpoint sets it in the spec, which triggers a
+// new reconciliation.
+func (s *ClusterManager) ControlPlaneEndpointContext(ctx context.Context) ([]capm3.APIEndpoint, error) {
+	spec := s.BareMetalCluster.Spec
+	if !spec.ExternallyManagedEndpoint || spec.ControlPlaneEndpoint.Host != "" {
+		return s.ControlPlaneEndpoint()
+	}
+
+	if s.LoadBalancer == nil {
+		s.Log.Info("Waiting for the externally managed endpoint to be set in the spec")
+		return nil, nil
+	}
+	endpoint, err := s.LoadBalancer.ResolveEndpoint(ctx, s.BareMetalCluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve the load balancer endpoint")
+	}
+	if endpoint == nil || endpoint.Host == "" {
+		s.Log.Info("Load balancer endpoint not known yet")
+		return nil, &RequeueAfterError{RequeueAfter: s.RequeueAfter}
+	}
+
+	port := endpoint.Port
+	if port == 0 {
+		port = defaultAPIEndpointPort
+	}
+	return []capm3.APIEndpoint{
+		{
+			Host: endpoint.Host,
+			Port: port,
+		},
+	}, nil
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/pkg/errors"
+	"k8s.io/klog/klogr"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeLoadBalancerResolver returns a fixed endpoint, nil if not known yet,
+// or a fixed error.
+type fakeLoadBalancerResolver struct {
+	endpoint *infrav1.APIEndpoint
+	err      error
+}
+
+func (r *fakeLoadBalancerResolver) ResolveEndpoint(ctx context.Context,
+	bmCluster *infrav1.BareMetalCluster) (*infrav1.APIEndpoint, error) {
+	return r.endpoint, r.err
+}
+
+var _ = Describe("BareMetalCluster load balancer endpoint", func() {
+
+	type testCaseLoadBalancer struct {
+		Spec              *infrav1.BareMetalClusterSpec
+		Resolver          LoadBalancerResolver
+		ExpectedEndpoints []infrav1.APIEndpoint
+		ExpectRequeue     bool
+		ExpectError       bool
+	}
+
+	externalSpec := func() *infrav1.BareMetalClusterSpec {
+		spec := bmcSpecAPIEmpty()
+		spec.ExternallyManagedEndpoint = true
+		return spec
+	}
+
+	DescribeTable("Test ControlPlaneEndpointContext",
+		func(tc testCaseLoadBalancer) {
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				tc.Spec, nil,
+			)
+			opts := []Option{}
+			if tc.Resolver != nil {
+				opts = append(opts, WithLoadBalancerResolver(tc.Resolver))
+			}
+			clusterMgr, err := NewClusterManager(
+				fakeclient.NewFakeClientWithScheme(setupScheme()),
+				newCluster(clusterName), bmCluster, klogr.New(), opts...,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			endpoints, err := clusterMgr.(*ClusterManager).ControlPlaneEndpointContext(
+				context.TODO(),
+			)
+			if tc.ExpectRequeue {
+				Expect(err).To(HaveOccurred())
+				_, ok := errors.Cause(err).(HasRequeueAfterError)
+				Expect(ok).To(BeTrue())
+			} else if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(endpoints).To(Equal(tc.ExpectedEndpoints))
+		},
+		Entry("Endpoint from the spec", testCaseLoadBalancer{
+			Spec: bmcSpec(),
+			Resolver: &fakeLoadBalancerResolver{
+				endpoint: &infrav1.APIEndpoint{Host: "192.168.111.250", Port: 443},
+			},
+			ExpectedEndpoints: []infrav1.APIEndpoint{bmcSpec().ControlPlaneEndpoint},
+		}),
+		Entry("Endpoint discovered from the load balancer", testCaseLoadBalancer{
+			Spec: externalSpec(),
+			Resolver: &fakeLoadBalancerResolver{
+				endpoint: &infrav1.APIEndpoint{Host: "192.168.111.250", Port: 443},
+			},
+			ExpectedEndpoints: []infrav1.APIEndpoint{
+				{Host: "192.168.111.250", Port: 443},
+			},
+		}),
+		Entry("Port defaulted", testCaseLoadBalancer{
+			Spec: externalSpec(),
+			Resolver: &fakeLoadBalancerResolver{
+				endpoint: &infrav1.APIEndpoint{Host: "192.168.111.250"},
+			},
+			ExpectedEndpoints: []infrav1.APIEndpoint{
+				{Host: "192.168.111.250", Port: 6443},
+			},
+		}),
+		Entry("Endpoint not known yet", testCaseLoadBalancer{
+			Spec:          externalSpec(),
+			Resolver:      &fakeLoadBalancerResolver{},
+			ExpectRequeue: true,
+		}),
+		Entry("Resolver error", testCaseLoadBalancer{
+			Spec:        externalSpec(),
+			Resolver:    &fakeLoadBalancerResolver{err: errors.New("Error")},
+			ExpectError: true,
+		}),
+		Entry("No resolver configured", testCaseLoadBalancer{
+			Spec: externalSpec(),
+		}),
+	)
+
+	It("Waits for the endpoint to be set in the spec without a resolver", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			externalSpec(), nil,
+		)
+		clusterMgr, err := NewClusterManager(
+			fakeclient.NewFakeClientWithScheme(setupScheme()),
+			newCluster(clusterName), bmCluster, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Status.Ready).To(BeFalse())
+		Expect(bmCluster.Status.ReadyReason).To(Equal(infrav1.ReadyReasonEndpointMissing))
+		Expect(bmCluster.Status.FailureReason).To(BeNil())
+	})
+
+	It("Publishes the discovered endpoint", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			externalSpec(), nil,
+		)
+		resolver := &fakeLoadBalancerResolver{}
+		clusterMgr, err := NewClusterManager(
+			fakeclient.NewFakeClientWithScheme(setupScheme()),
+			newCluster(clusterName), bmCluster, klogr.New(),
+			WithLoadBalancerResolver(resolver),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Not known yet
+		err = clusterMgr.UpdateClusterStatus(context.TODO())
+		Expect(err).To(HaveOccurred())
+		_, ok := errors.Cause(err).(HasRequeueAfterError)
+		Expect(ok).To(BeTrue())
+		Expect(bmCluster.Status.Ready).To(BeFalse())
+		Expect(bmCluster.Status.ReadyReason).To(Equal(infrav1.ReadyReasonEndpointMissing))
+		Expect(bmCluster.Status.FailureReason).To(BeNil())
+
+		resolver.endpoint = &infrav1.APIEndpoint{Host: "192.168.111.250", Port: 443}
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Status.Ready).To(BeTrue())
+		Expect(bmCluster.Spec.ControlPlaneEndpoint).To(Equal(*resolver.endpoint))
+		Expect(bmCluster.Status.APIEndpoints).To(Equal([]infrav1.APIEndpoint{
+			*resolver.endpoint,
+		}))
+	})
+})
@@ -18,7 +18,13 @@ package baremetal
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -27,7 +33,13 @@ import (
 	_ "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/metal3-io/cluster-api-provider-baremetal/featuregate"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
 	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
@@ -36,12 +48,34 @@ import (
 
 // ClusterManagerInterface is an interface for a ClusterManager
 type ClusterManagerInterface interface {
+	Reconcile(context.Context) (Result, error)
+	ReconcileNormal(context.Context) (Result, error)
+	ReconcileDelete(context.Context) (Result, error)
+	DeleteWithTimeout(context.Context, time.Duration) (int, error)
+	WaitForDescendantsGone(context.Context, time.Duration) (int, error)
 	Create(context.Context) error
 	Delete() error
-	UpdateClusterStatus() error
+	UpdateClusterStatus(context.Context) error
+	ResetStatus()
+	SetReady()
+	ClearReady()
 	SetFinalizer()
 	UnsetFinalizer()
 	CountDescendants(context.Context) (int, error)
+	DescendantNames(context.Context) ([]string, error)
+	DeleteDescendants(context.Context) error
+	CanProvision(context.Context) (bool, error)
+	Validate(context.Context) field.ErrorList
+	GenerateKubeconfig(context.Context) ([]byte, error)
+	StoreKubeconfig(context.Context) error
+	Summary(context.Context) (ClusterSummary, error)
+	Describe(context.Context) (ClusterDescription, error)
+	EnsureControlPlaneInitialized(context.Context) (bool, error)
+	GetDescendantsByPhase(context.Context) (map[string]int, error)
+	FailureDomains(context.Context) (capi.FailureDomains, error)
+	Refetch(context.Context) error
+	EndpointChanged() bool
+	ConsistencyCheck() error
 }
 
 // ClusterManager is responsible for performing machine reconciliation
@@ -51,56 +85,506 @@ type ClusterManager struct {
 	Cluster          *capi.Cluster
 	BareMetalCluster *capm3.BareMetalCluster
 	Log              logr.Logger
+	// ControlPlaneLabel is the label key identifying control plane Machines.
+	// Defaults to DefaultControlPlaneLabel when empty.
+	ControlPlaneLabel string
+	// DescendantLabelKeys are the label keys holding the cluster name on the
+	// Machines of the cluster. A Machine matching any of them is a
+	// descendant. Defaults to capi.ClusterLabelName when empty.
+	DescendantLabelKeys []string
+	// LookupHost resolves the endpoint DNS name. Defaults to
+	// net.DefaultResolver.LookupHost when nil.
+	LookupHost func(ctx context.Context, host string) ([]string, error)
+	// DialContext connects to the endpoint when probing it. Defaults to
+	// net.Dialer.DialContext when nil.
+	DialContext func(ctx context.Context, network, address string) (net.Conn, error)
+	// Clock times the probes of the endpoint and the polling of
+	// DeleteWithTimeout. Defaults to the real clock when nil.
+	Clock clock.Clock
+	// EndpointHook is notified when the cluster becomes Ready and when its
+	// ControlPlaneEndpoint changes. Defaults to NoopEndpointHook when nil.
+	EndpointHook EndpointHook
+	// LoadBalancer discovers the ControlPlaneEndpoint of the clusters with
+	// an ExternallyManagedEndpoint left empty.
+	LoadBalancer LoadBalancerResolver
+	// EventRecorder, if set, records an event for each error set on the
+	// BareMetalCluster.
+	EventRecorder record.EventRecorder
+	// EventLister, if set, lists the events of the BareMetalCluster for
+	// Describe.
+	EventLister EventLister
+	// RequeueAfter is the delay before checking again on a cluster that
+	// waits for its descendants.
+	RequeueAfter time.Duration
+	// PollInterval is the delay between the deletion attempts of
+	// DeleteWithTimeout. Defaults to RequeueAfter when zero.
+	PollInterval time.Duration
+	// DeleteAttempts is the number of times DeleteDescendants attempts to
+	// delete each Machine. Defaults to DefaultDeleteAttempts when zero.
+	DeleteAttempts int
+	// FinalizerName is the finalizer set on the BareMetalCluster.
+	FinalizerName string
+	// ForceDelete lets ReconcileDelete delete the BareMetalCluster while
+	// Machines of the cluster remain, e.g. for teardown scripts removing the
+	// infrastructure first. A warning is logged with the count.
+	ForceDelete bool
 	// name string
 }
 
-// NewClusterManager returns a new helper for managing a cluster with a given name.
+// Option configures a ClusterManager.
+type Option func(*ClusterManager)
+
+// WithClient replaces the client of the ClusterManager.
+func WithClient(client client.Client) Option {
+	return func(s *ClusterManager) {
+		s.client = client
+	}
+}
+
+// WithEventRecorder sets the recorder for the events of the ClusterManager.
+func WithEventRecorder(recorder record.EventRecorder) Option {
+	return func(s *ClusterManager) {
+		s.EventRecorder = recorder
+	}
+}
+
+// WithEventLister sets the lister for the events returned by Describe.
+func WithEventLister(lister EventLister) Option {
+	return func(s *ClusterManager) {
+		s.EventLister = lister
+	}
+}
+
+// WithRequeueAfter sets the delay before checking again on a waiting cluster.
+func WithRequeueAfter(requeueAfter time.Duration) Option {
+	return func(s *ClusterManager) {
+		s.RequeueAfter = requeueAfter
+	}
+}
+
+// WithPollInterval sets the delay between the deletion attempts of
+// DeleteWithTimeout.
+func WithPollInterval(pollInterval time.Duration) Option {
+	return func(s *ClusterManager) {
+		s.PollInterval = pollInterval
+	}
+}
+
+// WithDeleteAttempts sets the number of times DeleteDescendants attempts to
+// delete each Machine.
+func WithDeleteAttempts(attempts int) Option {
+	return func(s *ClusterManager) {
+		s.DeleteAttempts = attempts
+	}
+}
+
+// WithFinalizerName sets the finalizer set on the BareMetalCluster.
+func WithFinalizerName(finalizerName string) Option {
+	return func(s *ClusterManager) {
+		s.FinalizerName = finalizerName
+	}
+}
+
+// WithForceDelete makes ReconcileDelete proceed without waiting for the
+// Machines of the cluster to be deleted.
+func WithForceDelete() Option {
+	return func(s *ClusterManager) {
+		s.ForceDelete = true
+	}
+}
+
+// WithControlPlaneLabel sets the label key identifying control plane Machines.
+func WithControlPlaneLabel(label string) Option {
+	return func(s *ClusterManager) {
+		s.ControlPlaneLabel = label
+	}
+}
+
+// WithDescendantLabelKeys sets the label keys holding the cluster name on
+// the Machines of the cluster, e.g. to also match a legacy label key.
+func WithDescendantLabelKeys(keys ...string) Option {
+	return func(s *ClusterManager) {
+		s.DescendantLabelKeys = keys
+	}
+}
+
+// WithDialContext sets the dialer used to probe the endpoint.
+func WithDialContext(dialContext func(ctx context.Context, network, address string) (net.Conn, error)) Option {
+	return func(s *ClusterManager) {
+		s.DialContext = dialContext
+	}
+}
+
+// WithClock sets the clock timing the probes of the endpoint and the polling
+// of DeleteWithTimeout.
+func WithClock(clk clock.Clock) Option {
+	return func(s *ClusterManager) {
+		s.Clock = clk
+	}
+}
+
+// WithEndpointHook sets the hook notified of the ControlPlaneEndpoint.
+func WithEndpointHook(hook EndpointHook) Option {
+	return func(s *ClusterManager) {
+		s.EndpointHook = hook
+	}
+}
+
+// WithLoadBalancerResolver sets the resolver discovering externally managed
+// endpoints.
+func WithLoadBalancerResolver(resolver LoadBalancerResolver) Option {
+	return func(s *ClusterManager) {
+		s.LoadBalancer = resolver
+	}
+}
+
+// WithLookupHost sets the resolver for the endpoint DNS name.
+func WithLookupHost(lookupHost func(ctx context.Context, host string) ([]string, error)) Option {
+	return func(s *ClusterManager) {
+		s.LookupHost = lookupHost
+	}
+}
+
+const (
+	// EndpointConfigMapAnnotation names a ConfigMap, in the BareMetalCluster
+	// namespace, holding the control plane endpoint in its "host" and "port"
+	// keys.
+	EndpointConfigMapAnnotation = "baremetalcluster.infrastructure.cluster.x-k8s.io/endpoint-configmap"
+	// EndpointDNSNameAnnotation holds a DNS name that is resolved to get the
+	// control plane endpoint address.
+	EndpointDNSNameAnnotation = "baremetalcluster.infrastructure.cluster.x-k8s.io/endpoint-dns-name"
+	// APIEndpointsAnnotation holds a JSON list of additional endpoints, e.g.
+	// written by a load balancer controller, as in
+	// [{"host": "192.168.111.250", "port": 6443}]. They are published in
+	// Status.APIEndpoints after the ControlPlaneEndpoint, sorted by host and
+	// port.
+	APIEndpointsAnnotation = "baremetalcluster.infrastructure.cluster.x-k8s.io/api-endpoints"
+	// ObservedEndpointAnnotation records the ControlPlaneEndpoint, as
+	// "host:port", at the last successful status update. It is compared to
+	// the current endpoint by EndpointChanged.
+	ObservedEndpointAnnotation = "baremetalcluster.infrastructure.cluster.x-k8s.io/observed-endpoint"
+	// FinalizeAfterAnnotation names a finalizer of the BareMetalCluster that
+	// must be removed, e.g. by another controller of a teardown chain,
+	// before the finalizer of the manager is.
+	FinalizeAfterAnnotation = "baremetalcluster.infrastructure.cluster.x-k8s.io/finalize-after"
+	// defaultAPIEndpointPort is used when no source gives a port.
+	defaultAPIEndpointPort = 6443
+)
+
+// DefaultControlPlaneLabel is the label key set by Cluster API on control
+// plane Machines.
+const DefaultControlPlaneLabel = capi.MachineControlPlaneLabelName
+
+// NewClusterManager returns a new helper for managing a cluster with a given
+// name. The options are applied in order, after the defaults. The Cluster may
+// only be nil when the BareMetalCluster is being deleted, e.g. after its owner
+// Cluster, in which case only ReconcileDelete may be called.
 func NewClusterManager(client client.Client, cluster *capi.Cluster,
 	bareMetalCluster *capm3.BareMetalCluster,
-	clusterLog logr.Logger) (ClusterManagerInterface, error) {
+	clusterLog logr.Logger, opts ...Option) (ClusterManagerInterface, error) {
 
 	if bareMetalCluster == nil {
 		return nil, errors.New("BareMetalCluster is required when creating a ClusterManager")
 	}
-	if cluster == nil {
+	if cluster == nil && bareMetalCluster.DeletionTimestamp.IsZero() {
 		return nil, errors.New("Cluster is required when creating a ClusterManager")
 	}
 
-	return &ClusterManager{
+	clusterMgr := &ClusterManager{
 		client:           client,
 		BareMetalCluster: bareMetalCluster,
 		Cluster:          cluster,
 		Log:              clusterLog,
-	}, nil
+		RequeueAfter:     requeueAfter,
+		FinalizerName:    capm3.ClusterFinalizer,
+	}
+	for _, opt := range opts {
+		opt(clusterMgr)
+	}
+	return clusterMgr, nil
+}
+
+// Reconcile runs the normal reconciliation of the BareMetalCluster: it sets
+// the finalizer, validates the cluster and updates its status. A Result
+// asking for a requeue is returned while the ControlPlaneEndpoint does not
+// pass the health check. Nothing is done if the Cluster does not reference
+// the BareMetalCluster as its infrastructure.
+func (s *ClusterManager) Reconcile(ctx context.Context) (Result, error) {
+	if err := s.checkInfrastructureRef(); err != nil {
+		return Result{}, err
+	}
+
+	s.SetFinalizer()
+
+	if err := s.Create(ctx); err != nil {
+		return Result{}, err
+	}
+
+	s.ResetStatus()
+
+	if _, err := s.FailureDomains(ctx); err != nil {
+		return Result{}, err
+	}
+
+	if err := s.UpdateClusterStatus(ctx); err != nil {
+		if requeueErr, ok := errors.Cause(err).(HasRequeueAfterError); ok {
+			return Result{RequeueAfter: requeueErr.GetRequeueAfter()}, nil
+		}
+		return Result{}, err
+	}
+	return Result{}, nil
+}
+
+// ReconcileNormal runs the whole non-delete path of the BareMetalCluster, in
+// order: it returns a Result asking for a requeue after RequeueAfter while the
+// reconciliation is paused, then checks that the Cluster references the
+// BareMetalCluster, validates it, sets the finalizer and updates its status,
+// which sets the ObservedGeneration and the clusterReady metric.
+func (s *ClusterManager) ReconcileNormal(ctx context.Context) (Result, error) {
+	if util.IsPaused(s.Cluster, s.BareMetalCluster) {
+		s.Log.Info("Reconciliation is paused for this object, requeuing")
+		return Result{RequeueAfter: s.RequeueAfter}, nil
+	}
+	return s.Reconcile(ctx)
+}
+
+// checkInfrastructureRef returns an error if the InfrastructureRef of the
+// Cluster is unset or does not point at the BareMetalCluster, e.g. when the
+// Cluster was re-pointed at another infrastructure. The namespace of the
+// reference defaults to the one of the Cluster.
+func (s *ClusterManager) checkInfrastructureRef() error {
+	ref := s.Cluster.Spec.InfrastructureRef
+	if ref == nil {
+		return errors.Errorf("Cluster %s/%s has no InfrastructureRef, expected BareMetalCluster %s/%s",
+			s.Cluster.Namespace, s.Cluster.Name,
+			s.BareMetalCluster.Namespace, s.BareMetalCluster.Name,
+		)
+	}
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = s.Cluster.Namespace
+	}
+	if ref.Name != s.BareMetalCluster.Name || namespace != s.BareMetalCluster.Namespace {
+		return errors.Errorf("InfrastructureRef of Cluster %s/%s points at %s %s/%s, not at BareMetalCluster %s/%s",
+			s.Cluster.Namespace, s.Cluster.Name, ref.Kind, namespace, ref.Name,
+			s.BareMetalCluster.Namespace, s.BareMetalCluster.Name,
+		)
+	}
+	return nil
+}
+
+// ReconcileDelete runs the deletion of the BareMetalCluster. It returns a
+// Result asking for a requeue after RequeueAfter while the reconciliation is
+// paused or while Machines of the cluster remain. The finalizer is only
+// removed once there are none left, including when the owner Cluster is
+// already gone, unless ForceDelete is set, and once the finalizer named by the
+// FinalizeAfterAnnotation is gone.
+func (s *ClusterManager) ReconcileDelete(ctx context.Context) (Result, error) {
+	result, _, err := s.reconcileDelete(ctx)
+	return result, err
+}
+
+// deleteProgress tells why a deletion attempt asked for a requeue.
+type deleteProgress struct {
+	// remaining is the number of descendants left.
+	remaining int
+	// waitingFor describes what the deletion waits for, empty once it
+	// completed.
+	waitingFor string
+}
+
+// reconcileDelete runs the steps of ReconcileDelete and reports what a
+// requeue waits for.
+func (s *ClusterManager) reconcileDelete(ctx context.Context) (Result, deleteProgress, error) {
+	if s.Cluster != nil && util.IsPaused(s.Cluster, s.BareMetalCluster) {
+		s.Log.Info("Deletion is paused for this object, requeuing")
+		return Result{RequeueAfter: s.RequeueAfter},
+			deleteProgress{waitingFor: "the reconciliation to be unpaused"}, nil
+	}
+
+	ownerName, ownerGone, err := s.ownerClusterGone(ctx)
+	if err != nil {
+		return Result{}, deleteProgress{}, err
+	}
+
+	// Verify that no baremetalmachine depend on the baremetalcluster. They
+	// cannot be listed through a deleted owner Cluster, so look them up by
+	// the name it had
+	var descendants []string
+	if ownerGone {
+		s.Log.Info("Owner Cluster is gone", "cluster", ownerName)
+		var machines capi.MachineList
+		machines, err = s.listClusterMachines(ctx,
+			s.BareMetalCluster.Namespace, ownerName,
+		)
+		descendants = machineNames(machines)
+	} else {
+		descendants, err = s.DescendantNames(ctx)
+	}
+	if err != nil {
+		return Result{}, deleteProgress{}, err
+	}
+	if len(descendants) > 0 && s.ForceDelete {
+		s.Log.Info("Warning: force deleting the BareMetalCluster while descendants remain",
+			"count", len(descendants), "descendants", descendants,
+		)
+		if s.EventRecorder != nil {
+			s.EventRecorder.Eventf(s.BareMetalCluster, corev1.EventTypeWarning,
+				"ForceDeleted", "Deleted while %d descendants remain",
+				len(descendants),
+			)
+		}
+	} else if len(descendants) > 0 {
+		s.Log.Info("Waiting for descendants to be deleted, requeuing",
+			"descendants", descendants,
+		)
+		if s.EventRecorder != nil {
+			s.EventRecorder.Eventf(s.BareMetalCluster, corev1.EventTypeNormal,
+				"WaitingForDescendants", "Waiting for the deletion of %s",
+				strings.Join(descendants, ", "),
+			)
+		}
+		return Result{RequeueAfter: s.RequeueAfter}, deleteProgress{
+			remaining: len(descendants),
+			waitingFor: fmt.Sprintf("%d descendants to be deleted",
+				len(descendants),
+			),
+		}, nil
+	}
+
+	// Let the finalizer this one is ordered after run first
+	if prerequisite := s.pendingFinalizer(); prerequisite != "" {
+		s.Log.Info("Waiting for the prerequisite finalizer to be removed, requeuing",
+			"finalizer", prerequisite,
+		)
+		return Result{RequeueAfter: s.RequeueAfter}, deleteProgress{
+			remaining:  len(descendants),
+			waitingFor: fmt.Sprintf("the finalizer %s to be removed", prerequisite),
+		}, nil
+	}
+
+	if err := s.Delete(); err != nil {
+		return Result{}, deleteProgress{}, errors.Wrap(err, "failed to delete BareMetalCluster")
+	}
+
+	// Cluster is deleted so remove the finalizer.
+	s.UnsetFinalizer()
+	return Result{}, deleteProgress{}, nil
+}
+
+// ownerClusterGone returns the name of the owner Cluster, and true if it no
+// longer exists. A BareMetalCluster without owner yet is not considered
+// orphaned, since its Cluster may still be being created.
+func (s *ClusterManager) ownerClusterGone(ctx context.Context) (string, bool, error) {
+	for _, ref := range s.BareMetalCluster.OwnerReferences {
+		if ref.Kind != "Cluster" || ref.APIVersion != capi.GroupVersion.String() {
+			continue
+		}
+		_, err := util.GetClusterByName(ctx, s.client,
+			s.BareMetalCluster.Namespace, ref.Name,
+		)
+		if apierrors.IsNotFound(err) {
+			return ref.Name, true, nil
+		}
+		if err != nil {
+			return ref.Name, false, errors.Wrapf(err,
+				"failed to get owner Cluster %s", ref.Name,
+			)
+		}
+		return ref.Name, false, nil
+	}
+	return "", false, nil
+}
+
+// Refetch reloads the Cluster and the BareMetalCluster from the client, in
+// place, so that status writes apply on top of their latest version. The
+// Cluster is skipped when the manager has none. An error is returned if
+// either object was deleted.
+func (s *ClusterManager) Refetch(ctx context.Context) error {
+	bmCluster := capm3.BareMetalCluster{}
+	key := client.ObjectKey{
+		Name:      s.BareMetalCluster.Name,
+		Namespace: s.BareMetalCluster.Namespace,
+	}
+	if err := s.client.Get(ctx, key, &bmCluster); err != nil {
+		return errors.Wrapf(err, "failed to refetch BareMetalCluster %s/%s",
+			key.Namespace, key.Name,
+		)
+	}
+
+	if s.Cluster != nil {
+		cluster := capi.Cluster{}
+		key := client.ObjectKey{
+			Name:      s.Cluster.Name,
+			Namespace: s.Cluster.Namespace,
+		}
+		if err := s.client.Get(ctx, key, &cluster); err != nil {
+			return errors.Wrapf(err, "failed to refetch Cluster %s/%s",
+				key.Namespace, key.Name,
+			)
+		}
+		*s.Cluster = cluster
+	}
+	*s.BareMetalCluster = bmCluster
+	return nil
 }
 
 // SetFinalizer sets finalizer
 func (s *ClusterManager) SetFinalizer() {
 	// If the BareMetalCluster doesn't have finalizer, add it.
-	if !util.Contains(s.BareMetalCluster.ObjectMeta.Finalizers, capm3.ClusterFinalizer) {
+	if !util.Contains(s.BareMetalCluster.ObjectMeta.Finalizers, s.finalizerName()) {
 		s.BareMetalCluster.ObjectMeta.Finalizers = append(
-			s.BareMetalCluster.ObjectMeta.Finalizers, capm3.ClusterFinalizer,
+			s.BareMetalCluster.ObjectMeta.Finalizers, s.finalizerName(),
 		)
 	}
 }
 
-// UnsetFinalizer unsets finalizer
+// UnsetFinalizer unsets finalizer, unless the finalizer named by the
+// FinalizeAfterAnnotation is still set.
 func (s *ClusterManager) UnsetFinalizer() {
+	if prerequisite := s.pendingFinalizer(); prerequisite != "" {
+		s.Log.Info("Deferring the removal of the finalizer",
+			"finalizer", s.finalizerName(), "waiting for", prerequisite,
+		)
+		return
+	}
 	// Cluster is deleted so remove the finalizer.
 	s.BareMetalCluster.ObjectMeta.Finalizers = util.Filter(
-		s.BareMetalCluster.ObjectMeta.Finalizers, capm3.ClusterFinalizer,
+		s.BareMetalCluster.ObjectMeta.Finalizers, s.finalizerName(),
+	)
+}
+
+// pendingFinalizer returns the finalizer named by the
+// FinalizeAfterAnnotation if it is still set on the BareMetalCluster, or an
+// empty string.
+func (s *ClusterManager) pendingFinalizer() string {
+	prerequisite := strings.TrimSpace(
+		s.BareMetalCluster.Annotations[FinalizeAfterAnnotation],
 	)
+	if prerequisite == "" || prerequisite == s.finalizerName() ||
+		!util.Contains(s.BareMetalCluster.Finalizers, prerequisite) {
+		return ""
+	}
+	return prerequisite
+}
+
+// finalizerName returns the finalizer set on the BareMetalCluster, defaulting
+// to capm3.ClusterFinalizer for managers not built by NewClusterManager.
+func (s *ClusterManager) finalizerName() string {
+	if s.FinalizerName == "" {
+		return capm3.ClusterFinalizer
+	}
+	return s.FinalizerName
 }
 
 // Create creates a cluster manager for the cluster.
 func (s *ClusterManager) Create(ctx context.Context) error {
 
-	config := s.BareMetalCluster.Spec
-	err := config.IsValid()
-	if err != nil {
+	if errs := s.Validate(ctx); len(errs) > 0 {
 		// Should have been picked earlier. Do not requeue
 		s.setError("Invalid BareMetalCluster provided", capierrors.InvalidConfigurationClusterError)
-		return err
+		return errs.ToAggregate()
 	}
 
 	// clear an error if one was previously set
@@ -109,6 +593,27 @@ func (s *ClusterManager) Create(ctx context.Context) error {
 	return nil
 }
 
+// Validate returns the errors found in the BareMetalCluster. The checks are
+// the ones run by the webhook, applied to the effective control plane
+// endpoint.
+func (s *ClusterManager) Validate(ctx context.Context) field.ErrorList {
+	fldPath := field.NewPath("spec", "controlPlaneEndpoint")
+	endpoint, err := s.effectiveEndpoint(ctx)
+	if err != nil {
+		return field.ErrorList{field.InternalError(fldPath, err)}
+	}
+	var allErrs field.ErrorList
+	// An externally managed endpoint is discovered later, from the load
+	// balancer
+	if !s.BareMetalCluster.Spec.ExternallyManagedEndpoint || endpoint.Host != "" {
+		allErrs = capm3.ValidateControlPlaneEndpoint(endpoint, fldPath)
+	}
+	return append(allErrs, capm3.ValidateServiceEndpoints(
+		s.BareMetalCluster.Spec.ServiceEndpoints,
+		field.NewPath("spec", "serviceEndpoints"),
+	)...)
+}
+
 // ControlPlaneEndpoint returns cluster controlplane endpoint
 func (s *ClusterManager) ControlPlaneEndpoint() ([]capm3.APIEndpoint, error) {
 	//Get IP address from spec, which gets it from posted cr yaml
@@ -128,41 +633,230 @@ func (s *ClusterManager) ControlPlaneEndpoint() ([]capm3.APIEndpoint, error) {
 	}, nil
 }
 
-// Delete function, no-op for now
+// Delete cleans up after the BareMetalCluster, once it has no descendants.
+// Only the metrics of the cluster are deleted for now.
 func (s *ClusterManager) Delete() error {
+	s.forgetReady()
 	return nil
 }
 
-// UpdateClusterStatus updates a machine object's status.
-func (s *ClusterManager) UpdateClusterStatus() error {
+// UpdateClusterStatus updates a machine object's status. A RequeueAfterError
+// is returned, after clearing Ready, if the ControlPlaneEndpoint does not
+// accept connections yet. The ObservedGeneration is only updated on success.
+// The ReadyReason tells why the cluster is, or is not, Ready.
+func (s *ClusterManager) UpdateClusterStatus(ctx context.Context) error {
+	wasReady := s.BareMetalCluster.Status.Ready
+	defer s.recordReady()
 
-	// Get APIEndpoints from  BaremetalCluster Spec
-	_, err := s.ControlPlaneEndpoint()
+	// Publish the effective endpoint in the BaremetalCluster Spec, where the
+	// Cluster API Cluster Controller pulls it from. The Spec is only written
+	// while it is empty, so that an endpoint derived from DNS or from a
+	// Machine does not move once the Cluster uses it
+	endpoint, err := s.effectiveEndpoint(ctx)
+	if err != nil {
+		s.ClearReady()
+		s.BareMetalCluster.Status.ReadyReason = capm3.ReadyReasonEndpointMissing
+		s.setError("Failed to get the ControlPlaneEndpoint", capierrors.InvalidConfigurationClusterError)
+		return err
+	}
+	if s.BareMetalCluster.Spec.ControlPlaneEndpoint.Host == "" {
+		s.BareMetalCluster.Spec.ControlPlaneEndpoint = endpoint
+	} else {
+		endpoint = s.BareMetalCluster.Spec.ControlPlaneEndpoint
+	}
+
+	// Catch typos in the endpoint DNS name early, if requested
+	if endpoint.Host != "" &&
+		featuregate.Enabled(featuregate.ControlPlaneEndpointResolution) {
+		if err := s.timeProbe(endpointProbeDNS, func() error {
+			return s.resolveEndpointHost(ctx, endpoint.Host)
+		}); err != nil {
+			s.ClearReady()
+			s.BareMetalCluster.Status.ReadyReason = capm3.ReadyReasonProbeFailed
+			s.setError("ControlPlaneEndpoint host does not resolve", capierrors.InvalidConfigurationClusterError)
+			return err
+		}
+	}
+
+	// Get APIEndpoints from  BaremetalCluster Spec, or from the load balancer
+	// if the endpoint is externally managed
+	apiEndpoints, err := s.ControlPlaneEndpointContext(ctx)
 
 	if err != nil {
-		s.BareMetalCluster.Status.Ready = false
+		s.ClearReady()
+		s.BareMetalCluster.Status.ReadyReason = capm3.ReadyReasonEndpointMissing
+		if _, ok := errors.Cause(err).(HasRequeueAfterError); ok {
+			return err
+		}
 		s.setError("Invalid ControlPlaneEndpoint values", capierrors.InvalidConfigurationClusterError)
 		return err
 	}
+	if endpoint.Host == "" && len(apiEndpoints) > 0 {
+		endpoint = apiEndpoints[0]
+		s.BareMetalCluster.Spec.ControlPlaneEndpoint = endpoint
+	}
 
-	// Mark the baremetalCluster ready
-	s.BareMetalCluster.Status.Ready = true
+	// Add the endpoints discovered at runtime
+	externalEndpoints, err := s.externalAPIEndpoints()
+	if err != nil {
+		s.ClearReady()
+		s.BareMetalCluster.Status.ReadyReason = capm3.ReadyReasonEndpointInvalid
+		s.setError("Invalid "+APIEndpointsAnnotation+" annotation", capierrors.InvalidConfigurationClusterError)
+		return err
+	}
+	// The ControlPlaneEndpoint stays first, the kubeconfig being built from
+	// the first endpoint
+	s.BareMetalCluster.Status.APIEndpoints = orderAPIEndpoints(
+		mergeAPIEndpoints(apiEndpoints, sortAPIEndpoints(externalEndpoints)),
+		s.BareMetalCluster.Spec.EndpointAddressFamily,
+	)
+
+	// Publish the named endpoints
+	serviceEndpoints := s.BareMetalCluster.Spec.ServiceEndpoints
+	if errs := capm3.ValidateServiceEndpoints(serviceEndpoints,
+		field.NewPath("spec", "serviceEndpoints"),
+	); len(errs) > 0 {
+		s.ClearReady()
+		s.BareMetalCluster.Status.ReadyReason = capm3.ReadyReasonEndpointInvalid
+		s.setError("Invalid ServiceEndpoints values", capierrors.InvalidConfigurationClusterError)
+		return errs.ToAggregate()
+	}
+	s.BareMetalCluster.Status.ServiceEndpoints = nil
+	if len(serviceEndpoints) > 0 {
+		s.BareMetalCluster.Status.ServiceEndpoints = map[string]capm3.APIEndpoint{}
+		for name, endpoint := range serviceEndpoints {
+			s.BareMetalCluster.Status.ServiceEndpoints[name] = endpoint
+		}
+	}
+
+	// The endpoints are valid again, drop a failure set by a previous call
+	s.clearError()
+
+	// Once the control plane is initialized, a Ready cluster is not made not
+	// Ready again by new machines being provisioned, nor by the endpoint
+	// being briefly unreachable
+	initialized, err := s.EnsureControlPlaneInitialized(ctx)
+	if err != nil {
+		s.ClearReady()
+		return err
+	}
+	stayReady := wasReady && initialized
+
+	// Mark the baremetalCluster ready once it has an endpoint, and once all
+	// its machines are provisioned if requested
+	ready := len(apiEndpoints) > 0
+	readyReason := capm3.ReadyReasonEndpointValidated
+	if !ready {
+		readyReason = capm3.ReadyReasonEndpointMissing
+	}
+	if ready && !stayReady && (s.BareMetalCluster.Spec.RequireAllMachinesReady ||
+		featuregate.Enabled(featuregate.StrictClusterReadiness)) {
+		ready, err = s.allDescendantsProvisioned(ctx)
+		if err != nil {
+			s.ClearReady()
+			s.BareMetalCluster.Status.ReadyReason = capm3.ReadyReasonDescendantsPending
+			return err
+		}
+		if !ready {
+			readyReason = capm3.ReadyReasonDescendantsPending
+		}
+	}
+	// Only mark the baremetalCluster ready once the endpoint is listening, if
+	// requested
+	var probeErr error
+	if ready && !stayReady && endpoint.Host != "" &&
+		featuregate.Enabled(featuregate.ControlPlaneEndpointHealthCheck) {
+		probeErr = s.timeProbe(endpointProbeTCP, func() error {
+			return s.probeEndpoint(ctx, endpoint)
+		})
+		if probeErr != nil {
+			s.Log.Info("ControlPlaneEndpoint is not reachable yet", "error", probeErr.Error())
+			ready = false
+			readyReason = capm3.ReadyReasonProbeFailed
+		}
+	}
+	s.BareMetalCluster.Status.ReadyReason = readyReason
+	if ready {
+		s.SetReady()
+	} else {
+		s.ClearReady()
+	}
+	// A failure left by an earlier step must not be published as Ready
+	if err := s.ConsistencyCheck(); err != nil {
+		s.ClearReady()
+		return err
+	}
 	now := metav1.Now()
 	s.BareMetalCluster.Status.LastUpdated = &now
+	if probeErr != nil {
+		return &RequeueAfterError{RequeueAfter: s.RequeueAfter}
+	}
+	s.BareMetalCluster.Status.ObservedGeneration = s.BareMetalCluster.Generation
+	if ready && (!wasReady || s.EndpointChanged()) {
+		s.endpointHook().OnEndpointReady(s.BareMetalCluster.Spec.ControlPlaneEndpoint)
+	}
+	if s.BareMetalCluster.Annotations == nil {
+		s.BareMetalCluster.Annotations = map[string]string{}
+	}
+	s.BareMetalCluster.Annotations[ObservedEndpointAnnotation] = endpointHostPort(
+		s.BareMetalCluster.Spec.ControlPlaneEndpoint,
+	)
 	return nil
 }
 
+// EndpointChanged returns true if the ControlPlaneEndpoint differs from the
+// one recorded at the last successful UpdateClusterStatus, or if none was
+// recorded yet.
+func (s *ClusterManager) EndpointChanged() bool {
+	observed, ok := s.BareMetalCluster.Annotations[ObservedEndpointAnnotation]
+	return !ok || observed != endpointHostPort(s.BareMetalCluster.Spec.ControlPlaneEndpoint)
+}
+
+// endpointHostPort formats the endpoint as "host:port", or returns an empty
+// string if the endpoint is not set.
+func endpointHostPort(endpoint capm3.APIEndpoint) string {
+	if endpoint.Host == "" {
+		return ""
+	}
+	return net.JoinHostPort(endpoint.Host, strconv.Itoa(endpoint.Port))
+}
+
+// SetReady marks the BareMetalCluster ready. ReadySince is only set when the
+// cluster was not ready before.
+func (s *ClusterManager) SetReady() {
+	if s.BareMetalCluster.Status.Ready && s.BareMetalCluster.Status.ReadySince != nil {
+		return
+	}
+	now := metav1.Now()
+	s.BareMetalCluster.Status.Ready = true
+	s.BareMetalCluster.Status.ReadySince = &now
+}
+
+// ClearReady marks the BareMetalCluster not ready and clears ReadySince.
+func (s *ClusterManager) ClearReady() {
+	s.BareMetalCluster.Status.Ready = false
+	s.BareMetalCluster.Status.ReadySince = nil
+}
+
 // setError sets the FailureMessage and FailureReason fields on the machine and logs
 // the message. It assumes the reason is invalid configuration, since that is
 // currently the only relevant MachineStatusError choice.
 func (s *ClusterManager) setError(message string, reason capierrors.ClusterStatusError) {
 	s.BareMetalCluster.Status.FailureMessage = &message
 	s.BareMetalCluster.Status.FailureReason = &reason
+	// A failed cluster is never Ready
+	s.ClearReady()
+	if s.EventRecorder != nil {
+		s.EventRecorder.Event(s.BareMetalCluster, corev1.EventTypeWarning,
+			string(reason), message,
+		)
+	}
 }
 
 // clearError removes the ErrorMessage from the machine's Status if set. Returns
 // nil if ErrorMessage was already nil. Returns a RequeueAfterError if the
-// machine was updated.
+// machine was updated. Ready is left untouched, it is only set again by
+// UpdateClusterStatus.
 func (s *ClusterManager) clearError() {
 	if s.BareMetalCluster.Status.FailureMessage != nil || s.BareMetalCluster.Status.FailureReason != nil {
 		s.BareMetalCluster.Status.FailureMessage = nil
@@ -170,6 +864,28 @@ func (s *ClusterManager) clearError() {
 	}
 }
 
+// ConsistencyCheck returns an error if the BareMetalCluster is Ready while a
+// failure is set on it.
+func (s *ClusterManager) ConsistencyCheck() error {
+	status := s.BareMetalCluster.Status
+	if !status.Ready {
+		return nil
+	}
+	if status.FailureMessage != nil {
+		return errors.Errorf("BareMetalCluster %s/%s is Ready with the failure %q",
+			s.BareMetalCluster.Namespace, s.BareMetalCluster.Name,
+			*status.FailureMessage,
+		)
+	}
+	if status.FailureReason != nil {
+		return errors.Errorf("BareMetalCluster %s/%s is Ready with the failure reason %q",
+			s.BareMetalCluster.Namespace, s.BareMetalCluster.Name,
+			*status.FailureReason,
+		)
+	}
+	return nil
+}
+
 // CountDescendants will return the number of descendants objects of the
 // BaremetalCluster
 func (s *ClusterManager) CountDescendants(ctx context.Context) (int, error) {
@@ -192,6 +908,29 @@ func (s *ClusterManager) CountDescendants(ctx context.Context) (int, error) {
 	return nbDescendants, nil
 }
 
+// DescendantNames returns the sorted namespaced names of the descendants of
+// the BaremetalCluster
+func (s *ClusterManager) DescendantNames(ctx context.Context) ([]string, error) {
+	descendants, err := s.listDescendants(ctx)
+	if err != nil {
+		s.Log.Error(err, "Failed to list descendants")
+
+		return nil, err
+	}
+
+	return machineNames(descendants), nil
+}
+
+// machineNames returns the sorted namespaced names of the Machines.
+func machineNames(machines capi.MachineList) []string {
+	names := make([]string, 0, len(machines.Items))
+	for _, machine := range machines.Items {
+		names = append(names, machine.Namespace+"/"+machine.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // listDescendants returns a list of all Machines, for the cluster owning the
 // BaremetalCluster.
 func (s *ClusterManager) listDescendants(ctx context.Context) (capi.MachineList, error) {
@@ -203,18 +942,353 @@ func (s *ClusterManager) listDescendants(ctx context.Context) (capi.MachineList,
 	if err != nil {
 		return machines, err
 	}
+	if cluster == nil {
+		// No owner Cluster yet, so no Machine can belong to it
+		return machines, nil
+	}
+
+	return s.listClusterMachines(ctx, cluster.Namespace, cluster.Name)
+}
+
+// listClusterMachines returns the Machines labelled with the given cluster
+// name under any of the descendant label keys. A Machine carrying several of
+// the keys is only returned once.
+func (s *ClusterManager) listClusterMachines(ctx context.Context,
+	namespace, clusterName string) (capi.MachineList, error) {
+
+	machines := capi.MachineList{}
+	seen := map[string]bool{}
+	for _, labelKey := range s.descendantLabelKeys() {
+		keyMachines := capi.MachineList{}
+		listOptions := []client.ListOption{
+			client.InNamespace(namespace),
+			client.MatchingLabels(map[string]string{
+				labelKey: clusterName,
+			}),
+		}
+
+		if err := s.client.List(ctx, &keyMachines, listOptions...); err != nil {
+			return machines, errors.Wrapf(err,
+				"failed to list Machines for cluster %s/%s", namespace, clusterName,
+			)
+		}
+
+		for _, machine := range keyMachines.Items {
+			if seen[machine.Name] {
+				continue
+			}
+			seen[machine.Name] = true
+			machines.Items = append(machines.Items, machine)
+		}
+	}
+
+	return machines, nil
+}
+
+// descendantLabelKeys returns the label keys holding the cluster name on the
+// Machines of the cluster.
+func (s *ClusterManager) descendantLabelKeys() []string {
+	if len(s.DescendantLabelKeys) == 0 {
+		return []string{capi.ClusterLabelName}
+	}
+	return s.DescendantLabelKeys
+}
+
+// CanProvision returns true if a BareMetalMachine of the cluster may start
+// provisioning a host, that is if fewer than Spec.MaxSimultaneousProvisioning
+// BareMetalMachines of the cluster are in the Provisioning phase. It always
+// returns true when MaxSimultaneousProvisioning is not set.
+func (s *ClusterManager) CanProvision(ctx context.Context) (bool, error) {
+	maxProvisioning := s.BareMetalCluster.Spec.MaxSimultaneousProvisioning
+	if maxProvisioning <= 0 {
+		return true, nil
+	}
 
-	listOptions := []client.ListOption{
-		client.InNamespace(cluster.Namespace),
-		client.MatchingLabels(map[string]string{
-			capi.ClusterLabelName: cluster.Name,
-		}),
+	bmMachines, err := s.listDescendantBareMetalMachines(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	provisioning := 0
+	for _, bmMachine := range bmMachines.Items {
+		if bmMachine.Status.Phase == capm3.BareMetalMachinePhaseProvisioning {
+			provisioning++
+		}
+	}
+	if provisioning >= maxProvisioning {
+		s.Log.Info("Too many BareMetalMachines provisioning",
+			"provisioning", provisioning, "max", maxProvisioning,
+		)
+		return false, nil
+	}
+	return true, nil
+}
+
+// listDescendantBareMetalMachines returns the BareMetalMachines labelled with
+// the name of the Cluster under any of the descendant label keys. A
+// BareMetalMachine carrying several of the keys is only returned once.
+func (s *ClusterManager) listDescendantBareMetalMachines(ctx context.Context) (capm3.BareMetalMachineList, error) {
+	bmMachines := capm3.BareMetalMachineList{}
+	seen := map[string]bool{}
+	for _, labelKey := range s.descendantLabelKeys() {
+		keyMachines := capm3.BareMetalMachineList{}
+		listOptions := []client.ListOption{
+			client.InNamespace(s.BareMetalCluster.Namespace),
+			client.MatchingLabels(map[string]string{
+				labelKey: s.Cluster.Name,
+			}),
+		}
+		if err := s.client.List(ctx, &keyMachines, listOptions...); err != nil {
+			return bmMachines, errors.Wrapf(err, "failed to list BareMetalMachines for cluster %s/%s",
+				s.BareMetalCluster.Namespace, s.Cluster.Name,
+			)
+		}
+
+		for _, bmMachine := range keyMachines.Items {
+			if seen[bmMachine.Name] {
+				continue
+			}
+			seen[bmMachine.Name] = true
+			bmMachines.Items = append(bmMachines.Items, bmMachine)
+		}
+	}
+	return bmMachines, nil
+}
+
+// allDescendantsProvisioned returns true if all the Machines of the cluster
+// are in the Provisioned or Running phase.
+func (s *ClusterManager) allDescendantsProvisioned(ctx context.Context) (bool, error) {
+	machines, err := s.listDescendants(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, machine := range machines.Items {
+		switch capi.MachinePhase(machine.Status.Phase) {
+		case capi.MachinePhaseProvisioned, capi.MachinePhaseRunning:
+		default:
+			s.Log.Info("Waiting for Machine to be provisioned",
+				"machine", machine.Name, "phase", machine.Status.Phase,
+			)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// listControlPlaneDescendants returns a list of the control plane Machines,
+// for the cluster owning the BaremetalCluster. Control plane Machines are the
+// ones carrying the ControlPlaneLabel key, whatever its value.
+func (s *ClusterManager) listControlPlaneDescendants(ctx context.Context) (capi.MachineList, error) {
+	machines, err := s.listDescendants(ctx)
+	if err != nil {
+		return machines, err
 	}
 
-	if s.client.List(ctx, &machines, listOptions...) != nil {
-		errMsg := fmt.Sprintf("failed to list BaremetalMachines for cluster %s/%s", cluster.Namespace, cluster.Name)
-		return machines, errors.Wrapf(err, errMsg)
+	labelKey := s.controlPlaneLabel()
+	controlPlaneMachines := []capi.Machine{}
+	for _, machine := range machines.Items {
+		if _, ok := machine.Labels[labelKey]; ok {
+			controlPlaneMachines = append(controlPlaneMachines, machine)
+		}
 	}
+	machines.Items = controlPlaneMachines
 
 	return machines, nil
 }
+
+// controlPlaneLabel returns the label key identifying control plane Machines.
+func (s *ClusterManager) controlPlaneLabel() string {
+	if s.ControlPlaneLabel == "" {
+		return DefaultControlPlaneLabel
+	}
+	return s.ControlPlaneLabel
+}
+
+// effectiveEndpoint returns the control plane endpoint of the cluster. The
+// sources are considered in this order, the first one giving a host wins:
+//  1. the ConfigMap named by the EndpointConfigMapAnnotation,
+//  2. the lowest address the EndpointDNSNameAnnotation resolves to,
+//  3. the BaremetalCluster Spec.ControlPlaneEndpoint,
+//  4. the InternalIP of the first control plane Machine, by name.
+//
+// Except for the ConfigMap, the port comes from the Spec, or defaults to 6443.
+// An empty endpoint is returned if no source gives a host. UpdateClusterStatus
+// only copies it to an empty Spec.ControlPlaneEndpoint.
+func (s *ClusterManager) effectiveEndpoint(ctx context.Context) (capm3.APIEndpoint, error) {
+	specEndpoint := s.BareMetalCluster.Spec.ControlPlaneEndpoint
+	port := specEndpoint.Port
+	if port == 0 {
+		port = defaultAPIEndpointPort
+	}
+
+	if name, ok := s.BareMetalCluster.Annotations[EndpointConfigMapAnnotation]; ok {
+		return s.configMapEndpoint(ctx, name, port)
+	}
+
+	if name, ok := s.BareMetalCluster.Annotations[EndpointDNSNameAnnotation]; ok {
+		lookupHost := s.LookupHost
+		if lookupHost == nil {
+			lookupHost = net.DefaultResolver.LookupHost
+		}
+		addrs, err := lookupHost(ctx, name)
+		if err != nil {
+			return capm3.APIEndpoint{}, errors.Wrapf(err, "failed to resolve %s", name)
+		}
+		if len(addrs) == 0 {
+			return capm3.APIEndpoint{}, errors.Errorf("no address found for %s", name)
+		}
+		// Round-robin DNS returns the addresses in any order
+		sort.Strings(addrs)
+		return capm3.APIEndpoint{Host: addrs[0], Port: port}, nil
+	}
+
+	if specEndpoint.Host != "" {
+		return capm3.APIEndpoint{Host: specEndpoint.Host, Port: port}, nil
+	}
+
+	// Discovered from the load balancer by ControlPlaneEndpointContext
+	if s.BareMetalCluster.Spec.ExternallyManagedEndpoint {
+		return capm3.APIEndpoint{}, nil
+	}
+
+	machines, err := s.listControlPlaneDescendants(ctx)
+	if err != nil {
+		return capm3.APIEndpoint{}, err
+	}
+	sort.Slice(machines.Items, func(i, j int) bool {
+		return machines.Items[i].Name < machines.Items[j].Name
+	})
+	for _, machine := range machines.Items {
+		for _, address := range machine.Status.Addresses {
+			if address.Type == capi.MachineInternalIP && address.Address != "" {
+				return capm3.APIEndpoint{Host: address.Address, Port: port}, nil
+			}
+		}
+	}
+
+	return capm3.APIEndpoint{}, nil
+}
+
+// externalAPIEndpoints parses and validates the endpoints listed in the
+// APIEndpointsAnnotation. It returns nil if the annotation is not set.
+func (s *ClusterManager) externalAPIEndpoints() ([]capm3.APIEndpoint, error) {
+	value, ok := s.BareMetalCluster.Annotations[APIEndpointsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	endpoints := []capm3.APIEndpoint{}
+	if err := json.Unmarshal([]byte(value), &endpoints); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s annotation",
+			APIEndpointsAnnotation,
+		)
+	}
+
+	fldPath := field.NewPath("metadata", "annotations").Key(APIEndpointsAnnotation)
+	var allErrs field.ErrorList
+	for i, endpoint := range endpoints {
+		allErrs = append(allErrs,
+			capm3.ValidateControlPlaneEndpoint(endpoint, fldPath.Index(i))...,
+		)
+	}
+	if len(allErrs) > 0 {
+		return nil, allErrs.ToAggregate()
+	}
+	return endpoints, nil
+}
+
+// mergeAPIEndpoints returns the union of the given lists, in order, without
+// duplicates.
+func mergeAPIEndpoints(lists ...[]capm3.APIEndpoint) []capm3.APIEndpoint {
+	merged := []capm3.APIEndpoint{}
+	seen := map[capm3.APIEndpoint]bool{}
+	for _, list := range lists {
+		for _, endpoint := range list {
+			if seen[endpoint] {
+				continue
+			}
+			seen[endpoint] = true
+			merged = append(merged, endpoint)
+		}
+	}
+	return merged
+}
+
+// sortAPIEndpoints sorts the endpoints by host, then by port, so that their
+// order does not depend on where they were found and the status does not
+// change between reconciliations.
+func sortAPIEndpoints(endpoints []capm3.APIEndpoint) []capm3.APIEndpoint {
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Host != endpoints[j].Host {
+			return endpoints[i].Host < endpoints[j].Host
+		}
+		return endpoints[i].Port < endpoints[j].Port
+	})
+	return endpoints
+}
+
+// orderAPIEndpoints moves the endpoints whose host is an IP address of the
+// preferred family first. The order is otherwise kept, and DNS names, whose
+// family is unknown, come after the preferred endpoints.
+func orderAPIEndpoints(endpoints []capm3.APIEndpoint,
+	family capm3.EndpointAddressFamily) []capm3.APIEndpoint {
+
+	var preferIPv6 bool
+	switch family {
+	case capm3.EndpointAddressFamilyIPv4:
+		preferIPv6 = false
+	case capm3.EndpointAddressFamilyIPv6:
+		preferIPv6 = true
+	default:
+		return endpoints
+	}
+
+	preferred := func(endpoint capm3.APIEndpoint) bool {
+		ip := net.ParseIP(endpoint.Host)
+		if ip == nil {
+			return false
+		}
+		return (ip.To4() == nil) == preferIPv6
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		return preferred(endpoints[i]) && !preferred(endpoints[j])
+	})
+	return endpoints
+}
+
+// configMapEndpoint reads the control plane endpoint from the "host" and
+// "port" keys of the named ConfigMap. The port is optional.
+func (s *ClusterManager) configMapEndpoint(ctx context.Context, name string,
+	defaultPort int) (capm3.APIEndpoint, error) {
+
+	configMap := corev1.ConfigMap{}
+	key := client.ObjectKey{
+		Name:      name,
+		Namespace: s.BareMetalCluster.Namespace,
+	}
+	if err := s.client.Get(ctx, key, &configMap); err != nil {
+		return capm3.APIEndpoint{}, errors.Wrapf(err,
+			"failed to get endpoint ConfigMap %s", name,
+		)
+	}
+
+	host := configMap.Data["host"]
+	if host == "" {
+		return capm3.APIEndpoint{}, errors.Errorf(
+			"endpoint ConfigMap %s has no host", name,
+		)
+	}
+	port := defaultPort
+	if rawPort, ok := configMap.Data["port"]; ok {
+		var err error
+		port, err = strconv.Atoi(rawPort)
+		if err != nil {
+			return capm3.APIEndpoint{}, errors.Wrapf(err,
+				"invalid port in endpoint ConfigMap %s", name,
+			)
+		}
+	}
+
+	return capm3.APIEndpoint{Host: host, Port: port}, nil
+}
@@ -18,6 +18,10 @@ package baremetal
 
 import (
 	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -25,9 +29,13 @@ import (
 
 	_ "github.com/go-logr/logr"
 	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/metal3-io/cluster-api-provider-baremetal/featuregate"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	_ "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/klogr"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
@@ -66,6 +74,21 @@ type descendantsTestCase struct {
 	ExpectedDescendants int
 }
 
+type effectiveEndpointTestCase struct {
+	Annotations      map[string]string
+	Spec             *infrav1.BareMetalClusterSpec
+	ConfigMap        *corev1.ConfigMap
+	Machines         []*clusterv1.Machine
+	ExpectError      bool
+	ExpectedEndpoint infrav1.APIEndpoint
+}
+
+type controlPlaneDescendantsTestCase struct {
+	Machines            []*clusterv1.Machine
+	ControlPlaneLabel   string
+	ExpectedDescendants int
+}
+
 var _ = Describe("BareMetalCluster manager", func() {
 
 	Describe("Test New Cluster Manager", func() {
@@ -102,7 +125,71 @@ var _ = Describe("BareMetalCluster manager", func() {
 				BMCluster:     &infrav1.BareMetalCluster{},
 				ExpectSuccess: false,
 			}),
+			Entry("Cluster undefined, BMCluster deleted", testCaseBMClusterManager{
+				Cluster: nil,
+				BMCluster: &infrav1.BareMetalCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						DeletionTimestamp: &metav1.Time{Time: time.Now()},
+					},
+				},
+				ExpectSuccess: true,
+			}),
 		)
+
+		It("Applies the defaults when no option is given", func() {
+			clusterMgr, err := NewClusterManager(fakeClient,
+				&clusterv1.Cluster{}, &infrav1.BareMetalCluster{}, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			mgr := clusterMgr.(*ClusterManager)
+			Expect(mgr.client).To(Equal(fakeClient))
+			Expect(mgr.EventRecorder).To(BeNil())
+			Expect(mgr.RequeueAfter).To(Equal(requeueAfter))
+			Expect(mgr.FinalizerName).To(Equal(infrav1.ClusterFinalizer))
+			Expect(mgr.ControlPlaneLabel).To(BeEmpty())
+			Expect(mgr.DescendantLabelKeys).To(BeEmpty())
+			Expect(mgr.LookupHost).To(BeNil())
+			Expect(mgr.endpointHook()).To(Equal(NoopEndpointHook{}))
+		})
+
+		It("Applies the options", func() {
+			otherClient := fakeclient.NewFakeClientWithScheme(setupScheme())
+			recorder := record.NewFakeRecorder(1)
+			bmCluster := &infrav1.BareMetalCluster{}
+			clusterMgr, err := NewClusterManager(fakeClient,
+				&clusterv1.Cluster{}, bmCluster, klogr.New(),
+				WithClient(otherClient),
+				WithEventRecorder(recorder),
+				WithRequeueAfter(time.Minute),
+				WithPollInterval(time.Second),
+				WithDeleteAttempts(5),
+				WithEndpointHook(&recordingEndpointHook{}),
+				WithFinalizerName("example.com/finalizer"),
+				WithControlPlaneLabel("example.com/control-plane"),
+				WithDescendantLabelKeys(clusterv1.ClusterLabelName, "example.com/cluster"),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			mgr := clusterMgr.(*ClusterManager)
+			Expect(mgr.client).To(Equal(otherClient))
+			Expect(mgr.RequeueAfter).To(Equal(time.Minute))
+			Expect(mgr.pollInterval()).To(Equal(time.Second))
+			Expect(mgr.deleteAttempts()).To(Equal(5))
+			Expect(mgr.endpointHook()).To(Equal(&recordingEndpointHook{}))
+			Expect(mgr.ControlPlaneLabel).To(Equal("example.com/control-plane"))
+			Expect(mgr.DescendantLabelKeys).To(Equal([]string{
+				clusterv1.ClusterLabelName, "example.com/cluster",
+			}))
+
+			mgr.SetFinalizer()
+			Expect(bmCluster.Finalizers).To(ConsistOf("example.com/finalizer"))
+			mgr.UnsetFinalizer()
+			Expect(bmCluster.Finalizers).To(BeEmpty())
+
+			mgr.setError("abc", capierrors.InvalidConfigurationClusterError)
+			Expect(recorder.Events).To(Receive(ContainSubstring("abc")))
+		})
 	})
 
 	DescribeTable("Test Finalizers",
@@ -257,7 +344,7 @@ var _ = Describe("BareMetalCluster manager", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(clusterMgr).NotTo(BeNil())
 
-			err = clusterMgr.UpdateClusterStatus()
+			err = clusterMgr.UpdateClusterStatus(context.TODO())
 			Expect(err).NotTo(HaveOccurred())
 
 			//apiEndPoints := tc.BMCluster.Status.APIEndpoints
@@ -360,8 +447,1425 @@ var _ = Describe("BareMetalCluster manager", func() {
 		},
 		descendantsTestCases...,
 	)
+
+	DescribeTable("Test DescendantNames",
+		func(machines []*clusterv1.Machine, expectedNames []string) {
+			clusterMgr := descendantsSetup(descendantsTestCase{
+				Machines: machines,
+			})
+
+			names, err := clusterMgr.DescendantNames(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal(expectedNames))
+		},
+		Entry("No Cluster Descendants", []*clusterv1.Machine{}, []string{}),
+		Entry("Sorted Cluster Descendants", []*clusterv1.Machine{
+			newDescendantMachine("machine-b", ""),
+			newDescendantMachine("machine-a", ""),
+		}, []string{
+			namespaceName + "/machine-a", namespaceName + "/machine-b",
+		}),
+	)
+
+	DescribeTable("Test List Descendants with DescendantLabelKeys",
+		func(labelKeys []string, expectedNames []string) {
+			legacyMachine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "legacy-0",
+					Namespace: namespaceName,
+					Labels: map[string]string{
+						"example.com/cluster": clusterName,
+					},
+				},
+			}
+			bothMachine := newDescendantMachine("both-0", "")
+			bothMachine.Labels["example.com/cluster"] = clusterName
+			clusterMgr := descendantsSetup(descendantsTestCase{
+				Machines: []*clusterv1.Machine{
+					newDescendantMachine("machine-0", ""),
+					legacyMachine,
+					bothMachine,
+				},
+			})
+			clusterMgr.DescendantLabelKeys = labelKeys
+
+			names, err := clusterMgr.DescendantNames(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal(expectedNames))
+		},
+		Entry("Default label key", nil, []string{
+			namespaceName + "/both-0", namespaceName + "/machine-0",
+		}),
+		Entry("Legacy label key only", []string{"example.com/cluster"}, []string{
+			namespaceName + "/both-0", namespaceName + "/legacy-0",
+		}),
+		Entry("Standard and legacy label keys", []string{
+			clusterv1.ClusterLabelName, "example.com/cluster",
+		}, []string{
+			namespaceName + "/both-0", namespaceName + "/legacy-0",
+			namespaceName + "/machine-0",
+		}),
+	)
+
+	DescribeTable("Test List Control Plane Descendants",
+		func(tc controlPlaneDescendantsTestCase) {
+			clusterMgr := descendantsSetup(descendantsTestCase{
+				Machines: tc.Machines,
+			})
+			clusterMgr.ControlPlaneLabel = tc.ControlPlaneLabel
+
+			descendants, err := clusterMgr.listControlPlaneDescendants(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(len(descendants.Items)).To(Equal(tc.ExpectedDescendants))
+			for _, machine := range descendants.Items {
+				Expect(machine.Name).To(HavePrefix("cp-"))
+			}
+		},
+		Entry("No Cluster Descendants", controlPlaneDescendantsTestCase{
+			Machines:            []*clusterv1.Machine{},
+			ExpectedDescendants: 0,
+		}),
+		Entry("Default control plane label", controlPlaneDescendantsTestCase{
+			Machines: []*clusterv1.Machine{
+				newDescendantMachine("cp-0", clusterv1.MachineControlPlaneLabelName),
+				newDescendantMachine("worker-0", ""),
+				newDescendantMachine("worker-1", "example.com/control-plane"),
+			},
+			ExpectedDescendants: 1,
+		}),
+		Entry("Custom control plane label", controlPlaneDescendantsTestCase{
+			Machines: []*clusterv1.Machine{
+				newDescendantMachine("cp-0", "example.com/control-plane"),
+				newDescendantMachine("cp-1", "example.com/control-plane"),
+				newDescendantMachine("worker-0", clusterv1.MachineControlPlaneLabelName),
+			},
+			ControlPlaneLabel:   "example.com/control-plane",
+			ExpectedDescendants: 2,
+		}),
+	)
+
+	DescribeTable("Test effectiveEndpoint",
+		func(tc effectiveEndpointTestCase) {
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				tc.Spec, nil,
+			)
+			bmCluster.Annotations = tc.Annotations
+			objects := []runtime.Object{newCluster(clusterName), bmCluster}
+			if tc.ConfigMap != nil {
+				objects = append(objects, tc.ConfigMap)
+			}
+			for _, machine := range tc.Machines {
+				objects = append(objects, machine)
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+			clusterMgr := &ClusterManager{
+				client:           c,
+				BareMetalCluster: bmCluster,
+				Cluster:          newCluster(clusterName),
+				Log:              klogr.New(),
+				LookupHost: func(ctx context.Context, host string) ([]string, error) {
+					switch host {
+					case "api.example.com":
+						return []string{"192.168.111.10", "192.168.111.11"}, nil
+					case "rr.example.com":
+						return []string{"192.168.111.21", "192.168.111.20"}, nil
+					}
+					return nil, errors.New("no such host")
+				},
+			}
+
+			endpoint, err := clusterMgr.effectiveEndpoint(context.TODO())
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(endpoint).To(Equal(tc.ExpectedEndpoint))
+		},
+		Entry("ConfigMap wins over all other sources", effectiveEndpointTestCase{
+			Annotations: map[string]string{
+				EndpointConfigMapAnnotation: "endpoint",
+				EndpointDNSNameAnnotation:   "api.example.com",
+			},
+			Spec: bmcSpec(),
+			ConfigMap: newEndpointConfigMap(map[string]string{
+				"host": "192.168.111.5", "port": "8443",
+			}),
+			Machines: []*clusterv1.Machine{
+				newControlPlaneMachine("cp-0", "172.22.0.10"),
+			},
+			ExpectedEndpoint: infrav1.APIEndpoint{Host: "192.168.111.5", Port: 8443},
+		}),
+		Entry("ConfigMap without port uses Spec port", effectiveEndpointTestCase{
+			Annotations: map[string]string{
+				EndpointConfigMapAnnotation: "endpoint",
+			},
+			Spec: bmcSpec(),
+			ConfigMap: newEndpointConfigMap(map[string]string{
+				"host": "192.168.111.5",
+			}),
+			ExpectedEndpoint: infrav1.APIEndpoint{Host: "192.168.111.5", Port: 6443},
+		}),
+		Entry("ConfigMap without host", effectiveEndpointTestCase{
+			Annotations: map[string]string{
+				EndpointConfigMapAnnotation: "endpoint",
+			},
+			Spec:        bmcSpec(),
+			ConfigMap:   newEndpointConfigMap(map[string]string{"port": "8443"}),
+			ExpectError: true,
+		}),
+		Entry("ConfigMap not found", effectiveEndpointTestCase{
+			Annotations: map[string]string{
+				EndpointConfigMapAnnotation: "endpoint",
+			},
+			Spec:        bmcSpec(),
+			ExpectError: true,
+		}),
+		Entry("DNS name wins over Spec and control plane", effectiveEndpointTestCase{
+			Annotations: map[string]string{
+				EndpointDNSNameAnnotation: "api.example.com",
+			},
+			Spec: bmcSpec(),
+			Machines: []*clusterv1.Machine{
+				newControlPlaneMachine("cp-0", "172.22.0.10"),
+			},
+			ExpectedEndpoint: infrav1.APIEndpoint{Host: "192.168.111.10", Port: 6443},
+		}),
+		Entry("Lowest address of a round-robin DNS name", effectiveEndpointTestCase{
+			Annotations: map[string]string{
+				EndpointDNSNameAnnotation: "rr.example.com",
+			},
+			Spec:             bmcSpec(),
+			ExpectedEndpoint: infrav1.APIEndpoint{Host: "192.168.111.20", Port: 6443},
+		}),
+		Entry("DNS name does not resolve", effectiveEndpointTestCase{
+			Annotations: map[string]string{
+				EndpointDNSNameAnnotation: "api.invalid",
+			},
+			Spec:        bmcSpec(),
+			ExpectError: true,
+		}),
+		Entry("Spec wins over control plane", effectiveEndpointTestCase{
+			Spec: bmcSpec(),
+			Machines: []*clusterv1.Machine{
+				newControlPlaneMachine("cp-0", "172.22.0.10"),
+			},
+			ExpectedEndpoint: infrav1.APIEndpoint{Host: "192.168.111.249", Port: 6443},
+		}),
+		Entry("Control plane Machine used when Spec is empty", effectiveEndpointTestCase{
+			Spec: bmcSpecAPIEmpty(),
+			Machines: []*clusterv1.Machine{
+				newControlPlaneMachine("cp-1", "172.22.0.11"),
+				newControlPlaneMachine("cp-0", "172.22.0.10"),
+				newDescendantMachine("worker-0", ""),
+			},
+			ExpectedEndpoint: infrav1.APIEndpoint{Host: "172.22.0.10", Port: 6443},
+		}),
+		Entry("No source gives an endpoint", effectiveEndpointTestCase{
+			Spec: bmcSpecAPIEmpty(),
+			Machines: []*clusterv1.Machine{
+				newDescendantMachine("worker-0", ""),
+			},
+			ExpectedEndpoint: infrav1.APIEndpoint{},
+		}),
+	)
+
+	It("Publishes the effective endpoint in the Spec", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpecAPIEmpty(), nil,
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(),
+			newCluster(clusterName), bmCluster,
+			newControlPlaneMachine("cp-0", "172.22.0.10"),
+		)
+		clusterMgr := &ClusterManager{
+			client:           c,
+			BareMetalCluster: bmCluster,
+			Cluster:          newCluster(clusterName),
+			Log:              klogr.New(),
+		}
+
+		Expect(clusterMgr.Create(context.TODO())).To(Succeed())
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Spec.ControlPlaneEndpoint).To(Equal(
+			infrav1.APIEndpoint{Host: "172.22.0.10", Port: 6443},
+		))
+		Expect(bmCluster.Status.Ready).To(BeTrue())
+	})
+
+	It("Keeps the endpoint published in the Spec", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpecAPIEmpty(), nil,
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(),
+			newCluster(clusterName), bmCluster,
+			newControlPlaneMachine("cp-1", "172.22.0.11"),
+		)
+		clusterMgr := &ClusterManager{
+			client:           c,
+			BareMetalCluster: bmCluster,
+			Cluster:          newCluster(clusterName),
+			Log:              klogr.New(),
+		}
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+
+		// A Machine now comes first, the endpoint does not follow it
+		Expect(c.Create(context.TODO(),
+			newControlPlaneMachine("cp-0", "172.22.0.10"),
+		)).To(Succeed())
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Spec.ControlPlaneEndpoint).To(Equal(
+			infrav1.APIEndpoint{Host: "172.22.0.11", Port: 6443},
+		))
+		Expect(bmCluster.Annotations[ObservedEndpointAnnotation]).To(
+			Equal("172.22.0.11:6443"),
+		)
+	})
+
+	type testCaseRequireAllMachinesReady struct {
+		RequireAllMachinesReady bool
+		StrictClusterReadiness  bool
+		MachinePhases           []clusterv1.MachinePhase
+		ExpectedReady           bool
+	}
+
+	DescribeTable("Test UpdateClusterStatus with RequireAllMachinesReady",
+		func(tc testCaseRequireAllMachinesReady) {
+			defer func(enabled bool) {
+				_ = featuregate.Gates.SetFromMap(map[string]bool{
+					string(featuregate.StrictClusterReadiness): enabled,
+				})
+			}(featuregate.Enabled(featuregate.StrictClusterReadiness))
+			Expect(featuregate.Gates.SetFromMap(map[string]bool{
+				string(featuregate.StrictClusterReadiness): tc.StrictClusterReadiness,
+			})).To(Succeed())
+
+			spec := bmcSpec()
+			spec.RequireAllMachinesReady = tc.RequireAllMachinesReady
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				spec, nil,
+			)
+			objects := []runtime.Object{newCluster(clusterName), bmCluster}
+			for i, phase := range tc.MachinePhases {
+				machine := newDescendantMachine(fmt.Sprintf("machine-%d", i), "")
+				machine.Status.SetTypedPhase(phase)
+				objects = append(objects, machine)
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+			clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+				bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+			Expect(bmCluster.Status.Ready).To(Equal(tc.ExpectedReady))
+		},
+		Entry("Flag unset, machines in mixed phases",
+			testCaseRequireAllMachinesReady{
+				RequireAllMachinesReady: false,
+				MachinePhases: []clusterv1.MachinePhase{
+					clusterv1.MachinePhaseProvisioning, clusterv1.MachinePhaseRunning,
+				},
+				ExpectedReady: true,
+			},
+		),
+		Entry("Flag set, no machines", testCaseRequireAllMachinesReady{
+			RequireAllMachinesReady: true,
+			ExpectedReady:           true,
+		}),
+		Entry("Flag set, all machines provisioned",
+			testCaseRequireAllMachinesReady{
+				RequireAllMachinesReady: true,
+				MachinePhases: []clusterv1.MachinePhase{
+					clusterv1.MachinePhaseProvisioned, clusterv1.MachinePhaseRunning,
+				},
+				ExpectedReady: true,
+			},
+		),
+		Entry("Flag set, machines in mixed phases",
+			testCaseRequireAllMachinesReady{
+				RequireAllMachinesReady: true,
+				MachinePhases: []clusterv1.MachinePhase{
+					clusterv1.MachinePhaseRunning, clusterv1.MachinePhaseProvisioning,
+					clusterv1.MachinePhaseProvisioned,
+				},
+				ExpectedReady: false,
+			},
+		),
+		Entry("Flag unset, gate enabled, machines in mixed phases",
+			testCaseRequireAllMachinesReady{
+				RequireAllMachinesReady: false,
+				StrictClusterReadiness:  true,
+				MachinePhases: []clusterv1.MachinePhase{
+					clusterv1.MachinePhaseRunning, clusterv1.MachinePhaseProvisioning,
+				},
+				ExpectedReady: false,
+			},
+		),
+		Entry("Flag unset, gate enabled, all machines provisioned",
+			testCaseRequireAllMachinesReady{
+				RequireAllMachinesReady: false,
+				StrictClusterReadiness:  true,
+				MachinePhases: []clusterv1.MachinePhase{
+					clusterv1.MachinePhaseRunning,
+				},
+				ExpectedReady: true,
+			},
+		),
+		Entry("Flag set, machine failed", testCaseRequireAllMachinesReady{
+			RequireAllMachinesReady: true,
+			MachinePhases: []clusterv1.MachinePhase{
+				clusterv1.MachinePhaseProvisioned, clusterv1.MachinePhaseFailed,
+			},
+			ExpectedReady: false,
+		}),
+	)
+
+	type testCaseAPIEndpoints struct {
+		Annotation        *string
+		ExpectError       bool
+		ExpectedEndpoints []infrav1.APIEndpoint
+	}
+
+	DescribeTable("Test UpdateClusterStatus with APIEndpointsAnnotation",
+		func(tc testCaseAPIEndpoints) {
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				bmcSpec(), nil,
+			)
+			if tc.Annotation != nil {
+				bmCluster.Annotations = map[string]string{
+					APIEndpointsAnnotation: *tc.Annotation,
+				}
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(),
+				newCluster(clusterName), bmCluster,
+			)
+			clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+				bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = clusterMgr.UpdateClusterStatus(context.TODO())
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				Expect(bmCluster.Status.Ready).To(BeFalse())
+				Expect(bmCluster.Status.FailureReason).NotTo(BeNil())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bmCluster.Status.APIEndpoints).To(Equal(tc.ExpectedEndpoints))
+		},
+		Entry("No annotation", testCaseAPIEndpoints{
+			ExpectedEndpoints: []infrav1.APIEndpoint{
+				{Host: "192.168.111.249", Port: 6443},
+			},
+		}),
+		Entry("Annotation overlapping with the spec", testCaseAPIEndpoints{
+			Annotation: pointer.StringPtr(`[
+				{"host": "192.168.111.250", "port": 6443},
+				{"host": "192.168.111.249", "port": 6443},
+				{"host": "192.168.111.250", "port": 6443}
+			]`),
+			ExpectedEndpoints: []infrav1.APIEndpoint{
+				{Host: "192.168.111.249", Port: 6443},
+				{Host: "192.168.111.250", Port: 6443},
+			},
+		}),
+		Entry("Same host, other port", testCaseAPIEndpoints{
+			Annotation: pointer.StringPtr(`[{"host": "192.168.111.249", "port": 443}]`),
+			ExpectedEndpoints: []infrav1.APIEndpoint{
+				{Host: "192.168.111.249", Port: 6443},
+				{Host: "192.168.111.249", Port: 443},
+			},
+		}),
+		Entry("Empty list", testCaseAPIEndpoints{
+			Annotation: pointer.StringPtr(`[]`),
+			ExpectedEndpoints: []infrav1.APIEndpoint{
+				{Host: "192.168.111.249", Port: 6443},
+			},
+		}),
+		Entry("Invalid JSON", testCaseAPIEndpoints{
+			Annotation:  pointer.StringPtr(`[{"host": "192.168.111.250",`),
+			ExpectError: true,
+		}),
+		Entry("Invalid endpoint", testCaseAPIEndpoints{
+			Annotation:  pointer.StringPtr(`[{"host": "127.0.0.1", "port": 6443}]`),
+			ExpectError: true,
+		}),
+	)
+
+	It("Orders the endpoints identically across reconciliations", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), nil,
+		)
+		bmCluster.Annotations = map[string]string{
+			APIEndpointsAnnotation: `[
+				{"host": "192.168.111.250", "port": 6443},
+				{"host": "192.168.111.248", "port": 6443}
+			]`,
+		}
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(),
+			newCluster(clusterName), bmCluster,
+		)
+		clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+			bmCluster, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		first := append([]infrav1.APIEndpoint{}, bmCluster.Status.APIEndpoints...)
+		// The ControlPlaneEndpoint first, then the others sorted
+		Expect(first).To(Equal([]infrav1.APIEndpoint{
+			{Host: "192.168.111.249", Port: 6443},
+			{Host: "192.168.111.248", Port: 6443},
+			{Host: "192.168.111.250", Port: 6443},
+		}))
+		server, err := clusterMgr.(*ClusterManager).kubeconfigServer()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server).To(Equal("https://192.168.111.249:6443"))
+
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Status.APIEndpoints).To(Equal(first))
+
+		// The same endpoints, listed in another order
+		bmCluster.Annotations[APIEndpointsAnnotation] = `[
+			{"host": "192.168.111.248", "port": 6443},
+			{"host": "192.168.111.250", "port": 6443}
+		]`
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Status.APIEndpoints).To(Equal(first))
+	})
+
+	type testCaseServiceEndpoints struct {
+		ServiceEndpoints  map[string]infrav1.APIEndpoint
+		ExpectError       bool
+		ExpectedEndpoints map[string]infrav1.APIEndpoint
+	}
+
+	DescribeTable("Test UpdateClusterStatus with ServiceEndpoints",
+		func(tc testCaseServiceEndpoints) {
+			spec := bmcSpec()
+			spec.ServiceEndpoints = tc.ServiceEndpoints
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				spec, nil,
+			)
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(),
+				newCluster(clusterName), bmCluster,
+			)
+			clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+				bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = clusterMgr.UpdateClusterStatus(context.TODO())
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				Expect(bmCluster.Status.Ready).To(BeFalse())
+				Expect(bmCluster.Status.FailureReason).NotTo(BeNil())
+				Expect(bmCluster.Status.ServiceEndpoints).To(BeNil())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bmCluster.Status.ServiceEndpoints).To(Equal(tc.ExpectedEndpoints))
+		},
+		Entry("No service endpoints", testCaseServiceEndpoints{}),
+		Entry("Valid service endpoint", testCaseServiceEndpoints{
+			ServiceEndpoints: map[string]infrav1.APIEndpoint{
+				"ingress": {Host: "192.168.111.250", Port: 443},
+			},
+			ExpectedEndpoints: map[string]infrav1.APIEndpoint{
+				"ingress": {Host: "192.168.111.250", Port: 443},
+			},
+		}),
+		Entry("Invalid service endpoint", testCaseServiceEndpoints{
+			ServiceEndpoints: map[string]infrav1.APIEndpoint{
+				"ingress": {Host: "192.168.111.250", Port: 70000},
+			},
+			ExpectError: true,
+		}),
+	)
+
+	type testCaseOrderAPIEndpoints struct {
+		Family            infrav1.EndpointAddressFamily
+		ExpectedEndpoints []infrav1.APIEndpoint
+	}
+
+	DescribeTable("Test orderAPIEndpoints",
+		func(tc testCaseOrderAPIEndpoints) {
+			endpoints := []infrav1.APIEndpoint{
+				{Host: "192.168.111.249", Port: 6443},
+				{Host: "fd2e:6f44:5dd8:c956::14", Port: 6443},
+				{Host: "api.example.com", Port: 6443},
+				{Host: "192.168.111.250", Port: 6443},
+				{Host: "fd2e:6f44:5dd8:c956::15", Port: 6443},
+			}
+			Expect(orderAPIEndpoints(endpoints, tc.Family)).To(
+				Equal(tc.ExpectedEndpoints),
+			)
+		},
+		Entry("No preference", testCaseOrderAPIEndpoints{
+			ExpectedEndpoints: []infrav1.APIEndpoint{
+				{Host: "192.168.111.249", Port: 6443},
+				{Host: "fd2e:6f44:5dd8:c956::14", Port: 6443},
+				{Host: "api.example.com", Port: 6443},
+				{Host: "192.168.111.250", Port: 6443},
+				{Host: "fd2e:6f44:5dd8:c956::15", Port: 6443},
+			},
+		}),
+		Entry("IPv4 first", testCaseOrderAPIEndpoints{
+			Family: infrav1.EndpointAddressFamilyIPv4,
+			ExpectedEndpoints: []infrav1.APIEndpoint{
+				{Host: "192.168.111.249", Port: 6443},
+				{Host: "192.168.111.250", Port: 6443},
+				{Host: "fd2e:6f44:5dd8:c956::14", Port: 6443},
+				{Host: "api.example.com", Port: 6443},
+				{Host: "fd2e:6f44:5dd8:c956::15", Port: 6443},
+			},
+		}),
+		Entry("IPv6 first", testCaseOrderAPIEndpoints{
+			Family: infrav1.EndpointAddressFamilyIPv6,
+			ExpectedEndpoints: []infrav1.APIEndpoint{
+				{Host: "fd2e:6f44:5dd8:c956::14", Port: 6443},
+				{Host: "fd2e:6f44:5dd8:c956::15", Port: 6443},
+				{Host: "192.168.111.249", Port: 6443},
+				{Host: "api.example.com", Port: 6443},
+				{Host: "192.168.111.250", Port: 6443},
+			},
+		}),
+		Entry("Dual stack", testCaseOrderAPIEndpoints{
+			Family: infrav1.EndpointAddressFamilyDualStack,
+			ExpectedEndpoints: []infrav1.APIEndpoint{
+				{Host: "192.168.111.249", Port: 6443},
+				{Host: "fd2e:6f44:5dd8:c956::14", Port: 6443},
+				{Host: "api.example.com", Port: 6443},
+				{Host: "192.168.111.250", Port: 6443},
+				{Host: "fd2e:6f44:5dd8:c956::15", Port: 6443},
+			},
+		}),
+	)
+
+	type testCaseReadyTransition struct {
+		Ready              bool
+		SetReady           bool
+		ExpectReadySince   bool
+		ExpectSinceChanged bool
+	}
+
+	DescribeTable("Test SetReady and ClearReady",
+		func(tc testCaseReadyTransition) {
+			since := metav1.NewTime(time.Now().Add(-time.Hour))
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				bmcSpec(), nil,
+			)
+			bmCluster.Status.Ready = tc.Ready
+			if tc.Ready {
+				bmCluster.Status.ReadySince = since.DeepCopy()
+			}
+			clusterMgr, err := NewClusterManager(
+				fakeclient.NewFakeClientWithScheme(setupScheme()),
+				newCluster(clusterName), bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			if tc.SetReady {
+				clusterMgr.SetReady()
+			} else {
+				clusterMgr.ClearReady()
+			}
+
+			Expect(bmCluster.Status.Ready).To(Equal(tc.SetReady))
+			if !tc.ExpectReadySince {
+				Expect(bmCluster.Status.ReadySince).To(BeNil())
+				return
+			}
+			Expect(bmCluster.Status.ReadySince).NotTo(BeNil())
+			Expect(bmCluster.Status.ReadySince.Equal(&since)).To(
+				Equal(!tc.ExpectSinceChanged),
+			)
+		},
+		Entry("Not ready to ready", testCaseReadyTransition{
+			Ready:              false,
+			SetReady:           true,
+			ExpectReadySince:   true,
+			ExpectSinceChanged: true,
+		}),
+		Entry("Ready stays ready", testCaseReadyTransition{
+			Ready:              true,
+			SetReady:           true,
+			ExpectReadySince:   true,
+			ExpectSinceChanged: false,
+		}),
+		Entry("Ready to not ready", testCaseReadyTransition{
+			Ready:            true,
+			SetReady:         false,
+			ExpectReadySince: false,
+		}),
+		Entry("Not ready stays not ready", testCaseReadyTransition{
+			Ready:            false,
+			SetReady:         false,
+			ExpectReadySince: false,
+		}),
+	)
+
+	It("Keeps ReadySince across UpdateClusterStatus calls", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), nil,
+		)
+		clusterMgr, err := NewClusterManager(
+			fakeclient.NewFakeClientWithScheme(setupScheme()),
+			newCluster(clusterName), bmCluster, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Status.ReadySince).NotTo(BeNil())
+		readySince := bmCluster.Status.ReadySince.DeepCopy()
+
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Status.ReadySince).To(Equal(readySince))
+	})
+
+	It("Sets ObservedGeneration once UpdateClusterStatus succeeds", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), &infrav1.BareMetalClusterStatus{ObservedGeneration: 1},
+		)
+		bmCluster.Generation = 2
+		bmCluster.Annotations = map[string]string{
+			APIEndpointsAnnotation: "not a list",
+		}
+		clusterMgr, err := NewClusterManager(
+			fakeclient.NewFakeClientWithScheme(setupScheme()),
+			newCluster(clusterName), bmCluster, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).NotTo(Succeed())
+		Expect(bmCluster.Status.ObservedGeneration).To(BeEquivalentTo(1))
+
+		delete(bmCluster.Annotations, APIEndpointsAnnotation)
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Status.ObservedGeneration).To(BeEquivalentTo(2))
+		Expect(bmCluster.Status.FailureMessage).To(BeNil())
+		Expect(bmCluster.Status.Ready).To(BeTrue())
+	})
+
+	It("Never leaves a failed BareMetalCluster Ready", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), &infrav1.BareMetalClusterStatus{Ready: true},
+		)
+		bmCluster.Annotations = map[string]string{
+			APIEndpointsAnnotation: "not a list",
+		}
+		clusterMgr, err := NewClusterManager(
+			fakeclient.NewFakeClientWithScheme(setupScheme()),
+			newCluster(clusterName), bmCluster, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).NotTo(Succeed())
+		Expect(bmCluster.Status.FailureMessage).NotTo(BeNil())
+		Expect(bmCluster.Status.Ready).To(BeFalse())
+		Expect(clusterMgr.ConsistencyCheck()).To(Succeed())
+	})
+
+	type testCaseConsistencyCheck struct {
+		Status      infrav1.BareMetalClusterStatus
+		ExpectError bool
+	}
+
+	DescribeTable("Test ConsistencyCheck",
+		func(tc testCaseConsistencyCheck) {
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				bmcSpec(), tc.Status.DeepCopy(),
+			)
+			clusterMgr, err := NewClusterManager(
+				fakeclient.NewFakeClientWithScheme(setupScheme()),
+				newCluster(clusterName), bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = clusterMgr.ConsistencyCheck()
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+		},
+		Entry("Ready", testCaseConsistencyCheck{
+			Status: infrav1.BareMetalClusterStatus{Ready: true},
+		}),
+		Entry("Failed", testCaseConsistencyCheck{
+			Status: infrav1.BareMetalClusterStatus{
+				FailureMessage: pointer.StringPtr("failed"),
+			},
+		}),
+		Entry("Ready with a failure message", testCaseConsistencyCheck{
+			Status: infrav1.BareMetalClusterStatus{
+				Ready:          true,
+				FailureMessage: pointer.StringPtr("failed"),
+			},
+			ExpectError: true,
+		}),
+		Entry("Ready with a failure reason", testCaseConsistencyCheck{
+			Status: infrav1.BareMetalClusterStatus{
+				Ready: true,
+				FailureReason: func() *capierrors.ClusterStatusError {
+					reason := capierrors.InvalidConfigurationClusterError
+					return &reason
+				}(),
+			},
+			ExpectError: true,
+		}),
+	)
+
+	type testCaseReadyReason struct {
+		Spec           *infrav1.BareMetalClusterSpec
+		Annotations    map[string]string
+		MachinePhases  []clusterv1.MachinePhase
+		ExpectedReason string
+	}
+
+	DescribeTable("Test ReadyReason",
+		func(tc testCaseReadyReason) {
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				tc.Spec, nil,
+			)
+			bmCluster.Annotations = tc.Annotations
+			objects := []runtime.Object{newCluster(clusterName), bmCluster}
+			for i, phase := range tc.MachinePhases {
+				machine := newDescendantMachine(fmt.Sprintf("machine-%d", i), "")
+				machine.Status.SetTypedPhase(phase)
+				objects = append(objects, machine)
+			}
+			clusterMgr, err := NewClusterManager(
+				fakeclient.NewFakeClientWithScheme(setupScheme(), objects...),
+				newCluster(clusterName), bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			_ = clusterMgr.UpdateClusterStatus(context.TODO())
+			Expect(bmCluster.Status.ReadyReason).To(Equal(tc.ExpectedReason))
+			Expect(bmCluster.Status.Ready).To(Equal(
+				tc.ExpectedReason == infrav1.ReadyReasonEndpointValidated,
+			))
+		},
+		Entry("Endpoint validated", testCaseReadyReason{
+			Spec:           bmcSpec(),
+			ExpectedReason: infrav1.ReadyReasonEndpointValidated,
+		}),
+		Entry("Endpoint missing", testCaseReadyReason{
+			Spec:           bmcSpecAPIEmpty(),
+			ExpectedReason: infrav1.ReadyReasonEndpointMissing,
+		}),
+		Entry("Invalid additional endpoints", testCaseReadyReason{
+			Spec:           bmcSpec(),
+			Annotations:    map[string]string{APIEndpointsAnnotation: "not a list"},
+			ExpectedReason: infrav1.ReadyReasonEndpointInvalid,
+		}),
+		Entry("Descendants pending", testCaseReadyReason{
+			Spec: func() *infrav1.BareMetalClusterSpec {
+				spec := bmcSpec()
+				spec.RequireAllMachinesReady = true
+				return spec
+			}(),
+			MachinePhases: []clusterv1.MachinePhase{
+				clusterv1.MachinePhaseProvisioning,
+			},
+			ExpectedReason: infrav1.ReadyReasonDescendantsPending,
+		}),
+	)
+
+	type testCaseCanProvision struct {
+		MaxSimultaneousProvisioning int
+		Phases                      []string
+		ExpectCanProvision          bool
+	}
+
+	DescribeTable("Test CanProvision",
+		func(tc testCaseCanProvision) {
+			spec := bmcSpec()
+			spec.MaxSimultaneousProvisioning = tc.MaxSimultaneousProvisioning
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				spec, nil,
+			)
+			objects := []runtime.Object{newCluster(clusterName), bmCluster}
+			for i, phase := range tc.Phases {
+				objects = append(objects, &infrav1.BareMetalMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("bmmachine-%d", i),
+						Namespace: namespaceName,
+						Labels: map[string]string{
+							clusterv1.ClusterLabelName: clusterName,
+						},
+					},
+					Status: infrav1.BareMetalMachineStatus{Phase: phase},
+				})
+			}
+			// A machine of another cluster is not counted
+			objects = append(objects, &infrav1.BareMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "other-bmmachine",
+					Namespace: namespaceName,
+					Labels: map[string]string{
+						clusterv1.ClusterLabelName: "other-cluster",
+					},
+				},
+				Status: infrav1.BareMetalMachineStatus{
+					Phase: infrav1.BareMetalMachinePhaseProvisioning,
+				},
+			})
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+			clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+				bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			canProvision, err := clusterMgr.CanProvision(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(canProvision).To(Equal(tc.ExpectCanProvision))
+		},
+		Entry("No cap", testCaseCanProvision{
+			Phases: []string{
+				infrav1.BareMetalMachinePhaseProvisioning,
+				infrav1.BareMetalMachinePhaseProvisioning,
+			},
+			ExpectCanProvision: true,
+		}),
+		Entry("Below the cap", testCaseCanProvision{
+			MaxSimultaneousProvisioning: 2,
+			Phases: []string{
+				infrav1.BareMetalMachinePhaseProvisioning,
+				infrav1.BareMetalMachinePhaseProvisioned,
+				"",
+				"",
+			},
+			ExpectCanProvision: true,
+		}),
+		Entry("At the cap, several machines pending", testCaseCanProvision{
+			MaxSimultaneousProvisioning: 2,
+			Phases: []string{
+				infrav1.BareMetalMachinePhaseProvisioning,
+				infrav1.BareMetalMachinePhaseProvisioning,
+				infrav1.BareMetalMachinePhaseProvisioned,
+				"",
+				"",
+			},
+			ExpectCanProvision: false,
+		}),
+	)
+
+	It("Counts the BareMetalMachines under the DescendantLabelKeys in CanProvision", func() {
+		spec := bmcSpec()
+		spec.MaxSimultaneousProvisioning = 1
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			spec, nil,
+		)
+		bmMachine := &infrav1.BareMetalMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bmmachine-0",
+				Namespace: namespaceName,
+				Labels: map[string]string{
+					"example.com/cluster-name": clusterName,
+				},
+			},
+			Status: infrav1.BareMetalMachineStatus{
+				Phase: infrav1.BareMetalMachinePhaseProvisioning,
+			},
+		}
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(),
+			newCluster(clusterName), bmCluster, bmMachine,
+		)
+		clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+			bmCluster, klogr.New(), WithDescendantLabelKeys(
+				clusterv1.ClusterLabelName, "example.com/cluster-name",
+			),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		canProvision, err := clusterMgr.CanProvision(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(canProvision).To(BeFalse())
+	})
+
+	type testCaseReconcileDelete struct {
+		Paused          bool
+		OwnerGone       bool
+		ForceDelete     bool
+		Descendants     int
+		ExpectRequeue   bool
+		ExpectFinalizer bool
+	}
+
+	DescribeTable("Test ReconcileDelete",
+		func(tc testCaseReconcileDelete) {
+			cluster := newCluster(clusterName)
+			cluster.Spec.Paused = tc.Paused
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				bmcSpec(), nil,
+			)
+			bmCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			now := metav1.Now()
+			bmCluster.DeletionTimestamp = &now
+			objects := []runtime.Object{bmCluster}
+			if tc.OwnerGone {
+				// The controller has no Cluster to give the manager
+				cluster = nil
+			} else {
+				objects = append(objects, cluster)
+			}
+			for i := 0; i < tc.Descendants; i++ {
+				objects = append(objects,
+					newDescendantMachine(fmt.Sprintf("machine-%d", i), ""),
+				)
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+			options := []Option{}
+			if tc.ForceDelete {
+				options = append(options, WithForceDelete())
+			}
+			clusterMgr, err := NewClusterManager(c, cluster, bmCluster,
+				klogr.New(), options...,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			res, err := clusterMgr.ReconcileDelete(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			if tc.ExpectRequeue {
+				Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(res.RequeueAfter).To(Equal(requeueAfter))
+			} else {
+				Expect(res.IsZero()).To(BeTrue())
+			}
+			if tc.ExpectFinalizer {
+				Expect(bmCluster.Finalizers).To(ContainElement(infrav1.ClusterFinalizer))
+			} else {
+				Expect(bmCluster.Finalizers).NotTo(ContainElement(infrav1.ClusterFinalizer))
+			}
+		},
+		Entry("Paused", testCaseReconcileDelete{
+			Paused:          true,
+			ExpectRequeue:   true,
+			ExpectFinalizer: true,
+		}),
+		Entry("Waiting for descendants", testCaseReconcileDelete{
+			Descendants:     2,
+			ExpectRequeue:   true,
+			ExpectFinalizer: true,
+		}),
+		Entry("No descendants left", testCaseReconcileDelete{
+			Descendants:     0,
+			ExpectRequeue:   false,
+			ExpectFinalizer: false,
+		}),
+		Entry("Owner Cluster gone, descendants left", testCaseReconcileDelete{
+			OwnerGone:       true,
+			Descendants:     1,
+			ExpectRequeue:   true,
+			ExpectFinalizer: true,
+		}),
+		Entry("Owner Cluster gone, no descendants left", testCaseReconcileDelete{
+			OwnerGone:       true,
+			ExpectRequeue:   false,
+			ExpectFinalizer: false,
+		}),
+		Entry("Force delete, descendants left", testCaseReconcileDelete{
+			ForceDelete:     true,
+			Descendants:     2,
+			ExpectRequeue:   false,
+			ExpectFinalizer: false,
+		}),
+		Entry("Force delete while paused", testCaseReconcileDelete{
+			Paused:          true,
+			ForceDelete:     true,
+			Descendants:     2,
+			ExpectRequeue:   true,
+			ExpectFinalizer: true,
+		}),
+	)
+
+	It("Refetch picks up changes made since the construction", func() {
+		cluster := newCluster(clusterName)
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), nil,
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(), cluster,
+			bmCluster,
+		)
+		managerCluster := cluster.DeepCopy()
+		managerBMCluster := bmCluster.DeepCopy()
+		clusterMgr, err := NewClusterManager(c, managerCluster,
+			managerBMCluster, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Changed by another writer
+		bmCluster.Spec.RequireAllMachinesReady = true
+		Expect(c.Update(context.TODO(), bmCluster)).To(Succeed())
+		cluster.Spec.Paused = true
+		Expect(c.Update(context.TODO(), cluster)).To(Succeed())
+
+		Expect(clusterMgr.Refetch(context.TODO())).To(Succeed())
+		Expect(managerBMCluster.Spec.RequireAllMachinesReady).To(BeTrue())
+		Expect(managerBMCluster.ResourceVersion).To(
+			Equal(bmCluster.ResourceVersion),
+		)
+		Expect(managerCluster.Spec.Paused).To(BeTrue())
+	})
+
+	It("Refetch fails once the objects are deleted", func() {
+		cluster := newCluster(clusterName)
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), nil,
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(), cluster,
+			bmCluster,
+		)
+		clusterMgr, err := NewClusterManager(c, cluster.DeepCopy(),
+			bmCluster.DeepCopy(), klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.Delete(context.TODO(), cluster)).To(Succeed())
+		err = clusterMgr.Refetch(context.TODO())
+		Expect(apierrors.IsNotFound(errors.Cause(err))).To(BeTrue())
+
+		Expect(c.Delete(context.TODO(), bmCluster)).To(Succeed())
+		err = clusterMgr.Refetch(context.TODO())
+		Expect(apierrors.IsNotFound(errors.Cause(err))).To(BeTrue())
+	})
+
+	It("EndpointChanged reports the endpoint changes between status updates", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), nil,
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(),
+			newCluster(clusterName), bmCluster,
+		)
+		clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+			bmCluster, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		// First observation
+		Expect(clusterMgr.EndpointChanged()).To(BeTrue())
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(bmCluster.Annotations[ObservedEndpointAnnotation]).To(
+			Equal("192.168.111.249:6443"),
+		)
+		Expect(clusterMgr.EndpointChanged()).To(BeFalse())
+
+		// Unchanged endpoint
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(clusterMgr.EndpointChanged()).To(BeFalse())
+
+		// Real change, until the next status update
+		bmCluster.Spec.ControlPlaneEndpoint.Port = 6444
+		Expect(clusterMgr.EndpointChanged()).To(BeTrue())
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		Expect(clusterMgr.EndpointChanged()).To(BeFalse())
+	})
+
+	It("Names the remaining descendants in the ReconcileDelete event", func() {
+		cluster := newCluster(clusterName)
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), nil,
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(), cluster,
+			bmCluster, newDescendantMachine("machine-0", ""),
+		)
+		recorder := record.NewFakeRecorder(1)
+		clusterMgr, err := NewClusterManager(c, cluster, bmCluster,
+			klogr.New(), WithEventRecorder(recorder),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := clusterMgr.ReconcileDelete(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+		Expect(recorder.Events).To(Receive(ContainSubstring(
+			namespaceName + "/machine-0",
+		)))
+	})
+
+	It("Defers the removal of the finalizer until the prerequisite one is gone", func() {
+		cluster := newCluster(clusterName)
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), nil,
+		)
+		bmCluster.Annotations = map[string]string{
+			FinalizeAfterAnnotation: "example.com/teardown",
+		}
+		bmCluster.Finalizers = []string{
+			infrav1.ClusterFinalizer, "example.com/teardown",
+		}
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(), cluster,
+			bmCluster,
+		)
+		clusterMgr, err := NewClusterManager(c, cluster, bmCluster,
+			klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := clusterMgr.ReconcileDelete(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RequeueAfter).To(Equal(requeueAfter))
+		Expect(bmCluster.Finalizers).To(ContainElement(infrav1.ClusterFinalizer))
+
+		// Not removed directly either
+		clusterMgr.UnsetFinalizer()
+		Expect(bmCluster.Finalizers).To(ContainElement(infrav1.ClusterFinalizer))
+
+		// The other controller is done
+		bmCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+		res, err = clusterMgr.ReconcileDelete(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.IsZero()).To(BeTrue())
+		Expect(bmCluster.Finalizers).To(BeEmpty())
+	})
+
+	It("Warns about the remaining descendants when force deleting", func() {
+		cluster := newCluster(clusterName)
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), nil,
+		)
+		bmCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+		c := fakeclient.NewFakeClientWithScheme(setupScheme(), cluster,
+			bmCluster, newDescendantMachine("machine-0", ""),
+			newDescendantMachine("machine-1", ""),
+		)
+		recorder := record.NewFakeRecorder(1)
+		clusterMgr, err := NewClusterManager(c, cluster, bmCluster,
+			klogr.New(), WithEventRecorder(recorder), WithForceDelete(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := clusterMgr.ReconcileDelete(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.IsZero()).To(BeTrue())
+		Expect(bmCluster.Finalizers).NotTo(ContainElement(infrav1.ClusterFinalizer))
+		Expect(recorder.Events).To(Receive(And(
+			HavePrefix(corev1.EventTypeWarning),
+			ContainSubstring("2 descendants remain"),
+		)))
+	})
+
+	type testCaseInfrastructureRef struct {
+		InfrastructureRef *corev1.ObjectReference
+		ExpectError       bool
+	}
+
+	DescribeTable("Test Reconcile with the InfrastructureRef of the Cluster",
+		func(tc testCaseInfrastructureRef) {
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				bmcSpec(), nil,
+			)
+			cluster := newCluster(clusterName)
+			cluster.Spec.InfrastructureRef = tc.InfrastructureRef
+			clusterMgr, err := NewClusterManager(
+				fakeclient.NewFakeClientWithScheme(setupScheme(), bmCluster),
+				cluster, bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = clusterMgr.Reconcile(context.TODO())
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(
+					namespaceName + "/" + clusterName,
+				))
+				Expect(bmCluster.Finalizers).To(BeEmpty())
+				Expect(bmCluster.Status.Ready).To(BeFalse())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(bmCluster.Finalizers).NotTo(BeEmpty())
+				Expect(bmCluster.Status.Ready).To(BeTrue())
+			}
+		},
+		Entry("Points at the BareMetalCluster", testCaseInfrastructureRef{
+			InfrastructureRef: &corev1.ObjectReference{
+				Kind:      "BareMetalCluster",
+				Name:      baremetalClusterName,
+				Namespace: namespaceName,
+			},
+		}),
+		Entry("Namespace defaults to the one of the Cluster", testCaseInfrastructureRef{
+			InfrastructureRef: &corev1.ObjectReference{
+				Kind: "BareMetalCluster",
+				Name: baremetalClusterName,
+			},
+		}),
+		Entry("No InfrastructureRef", testCaseInfrastructureRef{
+			ExpectError: true,
+		}),
+		Entry("Points at another BareMetalCluster", testCaseInfrastructureRef{
+			InfrastructureRef: &corev1.ObjectReference{
+				Kind:      "BareMetalCluster",
+				Name:      "other-bmc",
+				Namespace: namespaceName,
+			},
+			ExpectError: true,
+		}),
+		Entry("Points at another namespace", testCaseInfrastructureRef{
+			InfrastructureRef: &corev1.ObjectReference{
+				Kind:      "BareMetalCluster",
+				Name:      baremetalClusterName,
+				Namespace: "otherns",
+			},
+			ExpectError: true,
+		}),
+	)
+
+	type testCaseReconcileNormal struct {
+		Paused          bool
+		Invalid         bool
+		ExpectRequeue   bool
+		ExpectError     bool
+		ExpectFinalizer bool
+		ExpectReady     bool
+	}
+
+	DescribeTable("Test ReconcileNormal",
+		func(tc testCaseReconcileNormal) {
+			cluster := newCluster(clusterName)
+			cluster.Spec.Paused = tc.Paused
+			spec := bmcSpec()
+			if tc.Invalid {
+				spec.ControlPlaneEndpoint.Host = ""
+			}
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				spec, nil,
+			)
+			bmCluster.Generation = 2
+			clusterMgr, err := NewClusterManager(
+				fakeclient.NewFakeClientWithScheme(setupScheme(), bmCluster),
+				cluster, bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			res, err := clusterMgr.ReconcileNormal(context.TODO())
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				Expect(bmCluster.Status.FailureReason).NotTo(BeNil())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(bmCluster.Status.FailureReason).To(BeNil())
+			}
+			if tc.ExpectRequeue {
+				Expect(res.RequeueAfter).To(Equal(requeueAfter))
+			} else {
+				Expect(res.IsZero()).To(BeTrue())
+			}
+			if tc.ExpectFinalizer {
+				Expect(bmCluster.Finalizers).To(ContainElement(infrav1.ClusterFinalizer))
+			} else {
+				Expect(bmCluster.Finalizers).To(BeEmpty())
+			}
+			Expect(bmCluster.Status.Ready).To(Equal(tc.ExpectReady))
+			if tc.ExpectReady {
+				Expect(bmCluster.Status.ObservedGeneration).To(BeEquivalentTo(2))
+			} else {
+				Expect(bmCluster.Status.ObservedGeneration).To(BeZero())
+			}
+		},
+		Entry("Paused", testCaseReconcileNormal{
+			Paused:        true,
+			ExpectRequeue: true,
+		}),
+		Entry("Invalid", testCaseReconcileNormal{
+			Invalid:         true,
+			ExpectError:     true,
+			ExpectFinalizer: true,
+		}),
+		Entry("Healthy", testCaseReconcileNormal{
+			ExpectFinalizer: true,
+			ExpectReady:     true,
+		}),
+	)
+
+	DescribeTable("Test Validate matches the webhook",
+		func(endpoint infrav1.APIEndpoint, expectValid bool) {
+			spec := infrav1.BareMetalClusterSpec{ControlPlaneEndpoint: endpoint}
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				&spec, nil,
+			)
+			c := fakeclient.NewFakeClientWithScheme(setupScheme())
+			clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+				bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			managerErrs := clusterMgr.Validate(context.TODO())
+			webhookErr := bmCluster.ValidateCreate()
+			if expectValid {
+				Expect(managerErrs).To(BeEmpty())
+				Expect(webhookErr).NotTo(HaveOccurred())
+				Expect(clusterMgr.Create(context.TODO())).To(Succeed())
+			} else {
+				Expect(managerErrs).NotTo(BeEmpty())
+				Expect(webhookErr).To(HaveOccurred())
+				Expect(clusterMgr.Create(context.TODO())).NotTo(Succeed())
+			}
+		},
+		Entry("Valid IP", infrav1.APIEndpoint{
+			Host: "192.168.111.249", Port: 6443,
+		}, true),
+		Entry("Valid DNS name", infrav1.APIEndpoint{
+			Host: "api.example.com", Port: 443,
+		}, true),
+		Entry("URL instead of host", infrav1.APIEndpoint{
+			Host: "https://192.168.111.249", Port: 6443,
+		}, false),
+		Entry("Host with port", infrav1.APIEndpoint{
+			Host: "192.168.111.249:6443", Port: 6443,
+		}, false),
+		Entry("Unspecified address", infrav1.APIEndpoint{
+			Host: "0.0.0.0", Port: 6443,
+		}, false),
+		Entry("Loopback address", infrav1.APIEndpoint{
+			Host: "127.0.0.1", Port: 6443,
+		}, false),
+		Entry("Port out of range", infrav1.APIEndpoint{
+			Host: "192.168.111.249", Port: 70000,
+		}, false),
+	)
 })
 
+func newEndpointConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "endpoint",
+			Namespace: namespaceName,
+		},
+		Data: data,
+	}
+}
+
+func newControlPlaneMachine(name string, address string) *clusterv1.Machine {
+	machine := newDescendantMachine(name, clusterv1.MachineControlPlaneLabelName)
+	machine.Status.Addresses = clusterv1.MachineAddresses{
+		{Type: clusterv1.MachineExternalIP, Address: "10.0.0.1"},
+		{Type: clusterv1.MachineInternalIP, Address: address},
+	}
+	return machine
+}
+
+func newDescendantMachine(name string, controlPlaneLabel string) *clusterv1.Machine {
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespaceName,
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName: clusterName,
+			},
+		},
+	}
+	if controlPlaneLabel != "" {
+		machine.Labels[controlPlaneLabel] = ""
+	}
+	return machine
+}
+
 func newBMClusterSetup(tc testCaseBMClusterManager) (*ClusterManager, error) {
 	objects := []runtime.Object{}
 
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// endpointProbeDNS labels the resolution of the ControlPlaneEndpoint host.
+	endpointProbeDNS = "dns"
+	// endpointProbeTCP labels the connection to the ControlPlaneEndpoint.
+	endpointProbeTCP = "tcp"
+
+	endpointProbeResultSuccess = "success"
+	endpointProbeResultFailure = "failure"
+)
+
+// endpointProbeSeconds is the latency of the optional probes of the
+// ControlPlaneEndpoint, to help tuning their timeouts.
+var endpointProbeSeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name: "capbm_cluster_endpoint_probe_seconds",
+		Help: "Duration of the probes of the BareMetalCluster ControlPlaneEndpoint, by probe and result.",
+		Buckets: []float64{
+			0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5,
+		},
+	},
+	[]string{"probe", "result"},
+)
+
+// clusterReady reports, for each BareMetalCluster, whether it is Ready.
+var clusterReady = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "capbm_cluster_ready",
+		Help: "Whether the BareMetalCluster is Ready (1) or not (0), by namespace and name.",
+	},
+	[]string{"namespace", "name"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(endpointProbeSeconds, clusterReady)
+}
+
+// timeProbe runs probe and records its duration, measured on the clock of
+// the ClusterManager, in endpointProbeSeconds.
+func (s *ClusterManager) timeProbe(name string, probe func() error) error {
+	clk := s.getClock()
+	start := clk.Now()
+	err := probe()
+	result := endpointProbeResultSuccess
+	if err != nil {
+		result = endpointProbeResultFailure
+	}
+	endpointProbeSeconds.WithLabelValues(name, result).Observe(
+		clk.Since(start).Seconds(),
+	)
+	return err
+}
+
+// recordReady sets the clusterReady series of the BareMetalCluster from its
+// status.
+func (s *ClusterManager) recordReady() {
+	value := 0.0
+	if s.BareMetalCluster.Status.Ready {
+		value = 1
+	}
+	clusterReady.WithLabelValues(s.BareMetalCluster.Namespace,
+		s.BareMetalCluster.Name,
+	).Set(value)
+}
+
+// forgetReady deletes the clusterReady series of the BareMetalCluster, so
+// that no stale series is left once it is deleted.
+func (s *ClusterManager) forgetReady() {
+	clusterReady.DeleteLabelValues(s.BareMetalCluster.Namespace,
+		s.BareMetalCluster.Name,
+	)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/metal3-io/cluster-api-provider-baremetal/featuregate"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/klog/klogr"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalCluster endpoint probe metrics", func() {
+
+	// probeSamples returns the number of samples and their sum in the
+	// histogram of the given probe and result.
+	probeSamples := func(probe, result string) (uint64, float64) {
+		metric := &dto.Metric{}
+		observer := endpointProbeSeconds.WithLabelValues(probe, result)
+		Expect(observer.(prometheus.Metric).Write(metric)).To(Succeed())
+		return metric.GetHistogram().GetSampleCount(),
+			metric.GetHistogram().GetSampleSum()
+	}
+
+	DescribeTable("Test the endpoint probe histogram",
+		func(gateEnabled bool, refused bool, expectedResult string, expectSample bool) {
+			defer func(enabled bool) {
+				_ = featuregate.Gates.SetFromMap(map[string]bool{
+					string(featuregate.ControlPlaneEndpointHealthCheck): enabled,
+				})
+			}(featuregate.Enabled(featuregate.ControlPlaneEndpointHealthCheck))
+			Expect(featuregate.Gates.SetFromMap(map[string]bool{
+				string(featuregate.ControlPlaneEndpointHealthCheck): gateEnabled,
+			})).To(Succeed())
+
+			fakeClock := clock.NewFakeClock(time.Now())
+			dialContext := func(ctx context.Context, network, address string) (net.Conn, error) {
+				fakeClock.Step(2 * time.Second)
+				if refused {
+					return nil, &net.OpError{Op: "dial", Net: network, Err: syscall.ECONNREFUSED}
+				}
+				client, server := net.Pipe()
+				server.Close()
+				return client, nil
+			}
+			clusterMgr, err := NewClusterManager(
+				fakeclient.NewFakeClientWithScheme(setupScheme()),
+				newCluster(clusterName),
+				newBareMetalCluster(baremetalClusterName, bmcOwnerRef, bmcSpec(), nil),
+				klogr.New(),
+				WithDialContext(dialContext),
+				WithClock(fakeClock),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			count, sum := probeSamples(endpointProbeTCP, expectedResult)
+			_ = clusterMgr.UpdateClusterStatus(context.TODO())
+			newCount, newSum := probeSamples(endpointProbeTCP, expectedResult)
+
+			if !expectSample {
+				Expect(newCount).To(Equal(count))
+				return
+			}
+			Expect(newCount).To(Equal(count + 1))
+			Expect(newSum - sum).To(BeNumerically("~", 2.0))
+		},
+		Entry("No probe when the gate is disabled", false, false,
+			endpointProbeResultSuccess, false,
+		),
+		Entry("Successful probe", true, false, endpointProbeResultSuccess, true),
+		Entry("Failed probe", true, true, endpointProbeResultFailure, true),
+	)
+})
+
+var _ = Describe("BareMetalCluster readiness metric", func() {
+
+	// readySeries returns the value of the clusterReady series of the
+	// cluster, and false if there is none.
+	readySeries := func(namespace, name string) (float64, bool) {
+		ch := make(chan prometheus.Metric, 100)
+		clusterReady.Collect(ch)
+		close(ch)
+		for m := range ch {
+			metric := &dto.Metric{}
+			Expect(m.Write(metric)).To(Succeed())
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == namespace && labels["name"] == name {
+				return metric.GetGauge().GetValue(), true
+			}
+		}
+		return 0, false
+	}
+
+	It("Follows the readiness and is removed on deletion", func() {
+		cluster := newCluster(clusterName)
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpecAPIEmpty(), nil,
+		)
+		clusterMgr, err := NewClusterManager(
+			fakeclient.NewFakeClientWithScheme(setupScheme(), cluster, bmCluster),
+			cluster, bmCluster, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Not Ready without endpoint
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		value, ok := readySeries(namespaceName, baremetalClusterName)
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal(0.0))
+
+		bmCluster.Spec = *bmcSpec()
+		Expect(clusterMgr.UpdateClusterStatus(context.TODO())).To(Succeed())
+		value, ok = readySeries(namespaceName, baremetalClusterName)
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal(1.0))
+
+		_, err = clusterMgr.ReconcileDelete(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		_, ok = readySeries(namespaceName, baremetalClusterName)
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,45 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+)
+
+// PhaseUnknown is the bucket of GetDescendantsByPhase counting the
+// BareMetalMachines that have no phase yet.
+const PhaseUnknown = "Unknown"
+
+// GetDescendantsByPhase returns the number of BareMetalMachines of the
+// cluster in each phase. Only the phases of at least one BareMetalMachine are
+// present in the map.
+func (s *ClusterManager) GetDescendantsByPhase(ctx context.Context) (map[string]int, error) {
+	bmMachines, err := s.listDescendantBareMetalMachines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	phases := map[string]int{}
+	for _, bmMachine := range bmMachines.Items {
+		phase := bmMachine.Status.Phase
+		if phase == "" {
+			phase = PhaseUnknown
+		}
+		phases[phase]++
+	}
+	return phases, nil
+}
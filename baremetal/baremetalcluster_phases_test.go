@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalCluster descendants by phase", func() {
+
+	newLabelledBareMetalMachine := func(name, cluster, phase string) *infrav1.BareMetalMachine {
+		return &infrav1.BareMetalMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespaceName,
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName: cluster,
+				},
+			},
+			Status: infrav1.BareMetalMachineStatus{Phase: phase},
+		}
+	}
+
+	type testCaseDescendantsByPhase struct {
+		Phases         []string
+		ExpectedPhases map[string]int
+	}
+
+	DescribeTable("Test GetDescendantsByPhase",
+		func(tc testCaseDescendantsByPhase) {
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				bmcSpec(), nil,
+			)
+			objects := []runtime.Object{newCluster(clusterName), bmCluster}
+			for i, phase := range tc.Phases {
+				objects = append(objects, newLabelledBareMetalMachine(
+					fmt.Sprintf("bmmachine-%d", i), clusterName, phase,
+				))
+			}
+			// A machine of another cluster is not counted
+			objects = append(objects, newLabelledBareMetalMachine(
+				"other-bmmachine", "other-cluster",
+				infrav1.BareMetalMachinePhaseFailed,
+			))
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+			clusterMgr, err := NewClusterManager(c, newCluster(clusterName),
+				bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			phases, err := clusterMgr.GetDescendantsByPhase(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phases).To(Equal(tc.ExpectedPhases))
+		},
+		Entry("No machines", testCaseDescendantsByPhase{
+			ExpectedPhases: map[string]int{},
+		}),
+		Entry("Machines in several phases", testCaseDescendantsByPhase{
+			Phases: []string{
+				infrav1.BareMetalMachinePhaseProvisioning,
+				infrav1.BareMetalMachinePhaseProvisioned,
+				infrav1.BareMetalMachinePhaseProvisioned,
+				infrav1.BareMetalMachinePhaseFailed,
+			},
+			ExpectedPhases: map[string]int{
+				infrav1.BareMetalMachinePhaseProvisioning: 1,
+				infrav1.BareMetalMachinePhaseProvisioned:  2,
+				infrav1.BareMetalMachinePhaseFailed:       1,
+			},
+		}),
+		Entry("Machines without a phase", testCaseDescendantsByPhase{
+			Phases: []string{
+				"", "", infrav1.BareMetalMachinePhaseProvisioned,
+			},
+			ExpectedPhases: map[string]int{
+				PhaseUnknown:                             2,
+				infrav1.BareMetalMachinePhaseProvisioned: 1,
+			},
+		}),
+	)
+})
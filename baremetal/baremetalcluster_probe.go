@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/pkg/errors"
+)
+
+// endpointProbeTimeout bounds the TCP connection to the ControlPlaneEndpoint
+// when probing it.
+const endpointProbeTimeout = 3 * time.Second
+
+// probeEndpoint returns an error if no TCP connection to the endpoint can be
+// established within endpointProbeTimeout.
+func (s *ClusterManager) probeEndpoint(ctx context.Context, endpoint capm3.APIEndpoint) error {
+	dialContext := s.DialContext
+	if dialContext == nil {
+		dialContext = (&net.Dialer{}).DialContext
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, endpointProbeTimeout)
+	defer cancel()
+
+	address := net.JoinHostPort(endpoint.Host, strconv.Itoa(endpoint.Port))
+	conn, err := dialContext(ctx, "tcp", address)
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to the ControlPlaneEndpoint %s", address)
+	}
+	return conn.Close()
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"net"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/metal3-io/cluster-api-provider-baremetal/featuregate"
+	"k8s.io/klog/klogr"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalCluster endpoint health check", func() {
+
+	type dialResult string
+
+	const (
+		dialSucceeds dialResult = "succeeds"
+		dialRefused  dialResult = "refused"
+		dialTimesOut dialResult = "times out"
+	)
+
+	// fakeDialer simulates the result of dialing and records the address.
+	type fakeDialer struct {
+		result  dialResult
+		address string
+	}
+
+	dialContext := func(d *fakeDialer) func(context.Context, string, string) (net.Conn, error) {
+		return func(ctx context.Context, network, address string) (net.Conn, error) {
+			d.address = address
+			switch d.result {
+			case dialRefused:
+				return nil, &net.OpError{Op: "dial", Net: network, Err: syscall.ECONNREFUSED}
+			case dialTimesOut:
+				if _, ok := ctx.Deadline(); !ok {
+					return nil, errors.New("dialing without a deadline")
+				}
+				return nil, context.DeadlineExceeded
+			}
+			client, server := net.Pipe()
+			server.Close()
+			return client, nil
+		}
+	}
+
+	type testCaseEndpointHealthCheck struct {
+		GateEnabled     bool
+		Dial            dialResult
+		ExpectRequeue   bool
+		ExpectReady     bool
+		ExpectedAddress string
+	}
+
+	DescribeTable("Test UpdateClusterStatus with ControlPlaneEndpointHealthCheck",
+		func(tc testCaseEndpointHealthCheck) {
+			defer func(enabled bool) {
+				_ = featuregate.Gates.SetFromMap(map[string]bool{
+					string(featuregate.ControlPlaneEndpointHealthCheck): enabled,
+				})
+			}(featuregate.Enabled(featuregate.ControlPlaneEndpointHealthCheck))
+			Expect(featuregate.Gates.SetFromMap(map[string]bool{
+				string(featuregate.ControlPlaneEndpointHealthCheck): tc.GateEnabled,
+			})).To(Succeed())
+
+			dialer := &fakeDialer{result: tc.Dial}
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				bmcSpec(), nil,
+			)
+			clusterMgr, err := NewClusterManager(
+				fakeclient.NewFakeClientWithScheme(setupScheme()),
+				newCluster(clusterName), bmCluster, klogr.New(),
+				WithDialContext(dialContext(dialer)),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = clusterMgr.UpdateClusterStatus(context.TODO())
+			if tc.ExpectRequeue {
+				_, ok := err.(HasRequeueAfterError)
+				Expect(ok).To(BeTrue())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(bmCluster.Status.Ready).To(Equal(tc.ExpectReady))
+			if tc.ExpectReady {
+				Expect(bmCluster.Status.ReadyReason).To(Equal(
+					infrav1.ReadyReasonEndpointValidated,
+				))
+			} else {
+				Expect(bmCluster.Status.ReadyReason).To(Equal(
+					infrav1.ReadyReasonProbeFailed,
+				))
+			}
+			// A failed probe is transient, not a terminal failure
+			Expect(bmCluster.Status.FailureReason).To(BeNil())
+			Expect(dialer.address).To(Equal(tc.ExpectedAddress))
+		},
+		Entry("Gate disabled", testCaseEndpointHealthCheck{
+			GateEnabled: false,
+			Dial:        dialRefused,
+			ExpectReady: true,
+		}),
+		Entry("Gate enabled, endpoint listening", testCaseEndpointHealthCheck{
+			GateEnabled:     true,
+			Dial:            dialSucceeds,
+			ExpectReady:     true,
+			ExpectedAddress: "192.168.111.249:6443",
+		}),
+		Entry("Gate enabled, connection refused", testCaseEndpointHealthCheck{
+			GateEnabled:     true,
+			Dial:            dialRefused,
+			ExpectRequeue:   true,
+			ExpectReady:     false,
+			ExpectedAddress: "192.168.111.249:6443",
+		}),
+		Entry("Gate enabled, connection timed out", testCaseEndpointHealthCheck{
+			GateEnabled:     true,
+			Dial:            dialTimesOut,
+			ExpectRequeue:   true,
+			ExpectReady:     false,
+			ExpectedAddress: "192.168.111.249:6443",
+		}),
+	)
+})
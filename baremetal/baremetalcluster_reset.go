@@ -0,0 +1,38 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+// ResetStatusAnnotation is set on a BareMetalCluster to have its status
+// reset, e.g. when it is stuck on a failure that no longer applies. It is
+// removed once the status is reset.
+const ResetStatusAnnotation = "baremetalcluster.infrastructure.cluster.x-k8s.io/reset-status"
+
+// ResetStatus starts the status of the BareMetalCluster over if the
+// ResetStatusAnnotation is set, and does nothing otherwise. The failure is
+// cleared, Ready unset and the APIEndpoints dropped, for the status to be
+// recomputed by the following UpdateClusterStatus.
+func (s *ClusterManager) ResetStatus() {
+	if _, ok := s.BareMetalCluster.Annotations[ResetStatusAnnotation]; !ok {
+		return
+	}
+	delete(s.BareMetalCluster.Annotations, ResetStatusAnnotation)
+
+	s.Log.Info("Resetting the status of the BareMetalCluster")
+	s.clearError()
+	s.ClearReady()
+	s.BareMetalCluster.Status.APIEndpoints = nil
+}
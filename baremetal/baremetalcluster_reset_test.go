@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"k8s.io/klog/klogr"
+	"k8s.io/utils/pointer"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalCluster status reset", func() {
+
+	failedStatus := func() *infrav1.BareMetalClusterStatus {
+		reason := capierrors.InvalidConfigurationClusterError
+		return &infrav1.BareMetalClusterStatus{
+			FailureMessage: pointer.StringPtr("stale failure"),
+			FailureReason:  &reason,
+			APIEndpoints: []infrav1.APIEndpoint{
+				{Host: "10.0.0.1", Port: 6443},
+			},
+		}
+	}
+
+	newManager := func(bmCluster *infrav1.BareMetalCluster) ClusterManagerInterface {
+		clusterMgr, err := NewClusterManager(
+			fakeclient.NewFakeClientWithScheme(setupScheme()),
+			newCluster(clusterName), bmCluster, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		return clusterMgr
+	}
+
+	It("Leaves the status alone without the annotation", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), failedStatus(),
+		)
+
+		newManager(bmCluster).ResetStatus()
+		Expect(bmCluster.Status).To(Equal(*failedStatus()))
+	})
+
+	It("Clears the failure, Ready and the APIEndpoints", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), failedStatus(),
+		)
+		bmCluster.Status.Ready = true
+		bmCluster.Annotations = map[string]string{ResetStatusAnnotation: ""}
+
+		newManager(bmCluster).ResetStatus()
+		Expect(bmCluster.Annotations).NotTo(HaveKey(ResetStatusAnnotation))
+		Expect(bmCluster.Status.FailureMessage).To(BeNil())
+		Expect(bmCluster.Status.FailureReason).To(BeNil())
+		Expect(bmCluster.Status.Ready).To(BeFalse())
+		Expect(bmCluster.Status.APIEndpoints).To(BeEmpty())
+	})
+
+	It("Recomputes the status in the same reconciliation", func() {
+		bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+			bmcSpec(), failedStatus(),
+		)
+		bmCluster.Annotations = map[string]string{ResetStatusAnnotation: ""}
+
+		_, err := newManager(bmCluster).Reconcile(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bmCluster.Annotations).NotTo(HaveKey(ResetStatusAnnotation))
+		Expect(bmCluster.Status.FailureMessage).To(BeNil())
+		Expect(bmCluster.Status.FailureReason).To(BeNil())
+		Expect(bmCluster.Status.Ready).To(BeTrue())
+		Expect(bmCluster.Status.APIEndpoints).To(Equal([]infrav1.APIEndpoint{
+			bmcSpec().ControlPlaneEndpoint,
+		}))
+	})
+})
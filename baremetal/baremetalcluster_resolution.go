@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// endpointResolutionNegativeTTL is how long a failed resolution of the
+	// ControlPlaneEndpoint host is remembered before the resolver is queried
+	// again.
+	endpointResolutionNegativeTTL = 30 * time.Second
+	// endpointResolutionMaxFailures bounds the number of failed resolutions
+	// remembered.
+	endpointResolutionMaxFailures = 1024
+)
+
+// endpointResolutionFailures caches the failed resolutions shared by all the
+// ClusterManagers, to avoid querying the resolver on every reconciliation of
+// a misconfigured cluster.
+var endpointResolutionFailures = newNegativeCache(endpointResolutionNegativeTTL)
+
+// negativeCache remembers errors per key for a limited time, up to
+// maxEntries errors.
+type negativeCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]negativeCacheEntry
+}
+
+type negativeCacheEntry struct {
+	err     error
+	expires time.Time
+}
+
+func newNegativeCache(ttl time.Duration) *negativeCache {
+	return &negativeCache{
+		ttl:        ttl,
+		maxEntries: endpointResolutionMaxFailures,
+		now:        time.Now,
+		entries:    map[string]negativeCacheEntry{},
+	}
+}
+
+// get returns the cached error for key, or nil if there is none or it has
+// expired.
+func (c *negativeCache) get(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil
+	}
+	return entry.err
+}
+
+// add caches err for key. Once the cache is full, the expired errors are
+// dropped, and the error expiring first too if none had expired.
+func (c *negativeCache) add(key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		var first string
+		for cachedKey, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, cachedKey)
+				continue
+			}
+			if first == "" || entry.expires.Before(c.entries[first].expires) {
+				first = cachedKey
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			delete(c.entries, first)
+		}
+	}
+	c.entries[key] = negativeCacheEntry{err: err, expires: now.Add(c.ttl)}
+}
+
+// resolveEndpointHost returns an error if host is a DNS name that does not
+// resolve. IP addresses are not checked.
+func (s *ClusterManager) resolveEndpointHost(ctx context.Context, host string) error {
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	if err := endpointResolutionFailures.get(host); err != nil {
+		return err
+	}
+
+	lookupHost := s.LookupHost
+	if lookupHost == nil {
+		lookupHost = net.DefaultResolver.LookupHost
+	}
+	addrs, err := lookupHost(ctx, host)
+	if err == nil && len(addrs) == 0 {
+		err = errors.New("no addresses found")
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "failed to resolve the ControlPlaneEndpoint host %s", host)
+		endpointResolutionFailures.add(host, err)
+		return err
+	}
+	return nil
+}
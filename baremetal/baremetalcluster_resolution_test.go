@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/metal3-io/cluster-api-provider-baremetal/featuregate"
+	"k8s.io/klog/klogr"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalCluster endpoint resolution", func() {
+
+	// fakeResolver resolves the names in addrs and counts the lookups.
+	type fakeResolver struct {
+		addrs   map[string][]string
+		lookups int
+	}
+
+	lookupHost := func(r *fakeResolver) func(context.Context, string) ([]string, error) {
+		return func(ctx context.Context, host string) ([]string, error) {
+			r.lookups++
+			if addrs, ok := r.addrs[host]; ok {
+				return addrs, nil
+			}
+			return nil, errors.New("no such host")
+		}
+	}
+
+	type testCaseEndpointResolution struct {
+		Host          string
+		GateEnabled   bool
+		ExpectError   bool
+		ExpectLookups int
+	}
+
+	DescribeTable("Test UpdateClusterStatus with ControlPlaneEndpointResolution",
+		func(tc testCaseEndpointResolution) {
+			defer func(enabled bool) {
+				_ = featuregate.Gates.SetFromMap(map[string]bool{
+					string(featuregate.ControlPlaneEndpointResolution): enabled,
+				})
+			}(featuregate.Enabled(featuregate.ControlPlaneEndpointResolution))
+			Expect(featuregate.Gates.SetFromMap(map[string]bool{
+				string(featuregate.ControlPlaneEndpointResolution): tc.GateEnabled,
+			})).To(Succeed())
+
+			resolver := &fakeResolver{addrs: map[string][]string{
+				"api.example.com": {"192.168.111.249"},
+			}}
+			spec := bmcSpec()
+			spec.ControlPlaneEndpoint.Host = tc.Host
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				spec, nil,
+			)
+			clusterMgr, err := NewClusterManager(
+				fakeclient.NewFakeClientWithScheme(setupScheme()),
+				newCluster(clusterName), bmCluster, klogr.New(),
+				WithLookupHost(lookupHost(resolver)),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = clusterMgr.UpdateClusterStatus(context.TODO())
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				Expect(bmCluster.Status.Ready).To(BeFalse())
+				Expect(*bmCluster.Status.FailureReason).To(Equal(
+					capierrors.InvalidConfigurationClusterError,
+				))
+				Expect(bmCluster.Status.ReadyReason).To(Equal(
+					infrav1.ReadyReasonProbeFailed,
+				))
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(bmCluster.Status.Ready).To(BeTrue())
+				Expect(bmCluster.Status.ReadyReason).To(Equal(
+					infrav1.ReadyReasonEndpointValidated,
+				))
+			}
+			Expect(resolver.lookups).To(Equal(tc.ExpectLookups))
+		},
+		Entry("Gate disabled, unresolvable name", testCaseEndpointResolution{
+			Host:          "gate-disabled.example.com",
+			GateEnabled:   false,
+			ExpectError:   false,
+			ExpectLookups: 0,
+		}),
+		Entry("Gate enabled, resolvable name", testCaseEndpointResolution{
+			Host:          "api.example.com",
+			GateEnabled:   true,
+			ExpectError:   false,
+			ExpectLookups: 1,
+		}),
+		Entry("Gate enabled, unresolvable name", testCaseEndpointResolution{
+			Host:          "api.exmaple.com",
+			GateEnabled:   true,
+			ExpectError:   true,
+			ExpectLookups: 1,
+		}),
+		Entry("Gate enabled, IP address", testCaseEndpointResolution{
+			Host:          "192.168.111.249",
+			GateEnabled:   true,
+			ExpectError:   false,
+			ExpectLookups: 0,
+		}),
+	)
+
+	It("Caches failed resolutions for a while", func() {
+		now := time.Now()
+		defer func(cache *negativeCache) {
+			endpointResolutionFailures = cache
+		}(endpointResolutionFailures)
+		endpointResolutionFailures = newNegativeCache(time.Minute)
+		endpointResolutionFailures.now = func() time.Time { return now }
+
+		resolver := &fakeResolver{}
+		clusterMgr := &ClusterManager{LookupHost: lookupHost(resolver)}
+
+		Expect(clusterMgr.resolveEndpointHost(context.TODO(), "typo.example.com")).
+			NotTo(Succeed())
+		Expect(clusterMgr.resolveEndpointHost(context.TODO(), "typo.example.com")).
+			NotTo(Succeed())
+		Expect(resolver.lookups).To(Equal(1))
+
+		now = now.Add(time.Minute)
+		Expect(clusterMgr.resolveEndpointHost(context.TODO(), "typo.example.com")).
+			NotTo(Succeed())
+		Expect(resolver.lookups).To(Equal(2))
+	})
+
+	It("Bounds the number of cached failures", func() {
+		now := time.Now()
+		cache := newNegativeCache(time.Minute)
+		cache.maxEntries = 2
+		cache.now = func() time.Time { return now }
+
+		cache.add("a.example.com", errors.New("a"))
+		now = now.Add(time.Second)
+		cache.add("b.example.com", errors.New("b"))
+		Expect(cache.entries).To(HaveLen(2))
+
+		// The cache is full, the failure expiring first is dropped
+		cache.add("c.example.com", errors.New("c"))
+		Expect(cache.entries).To(HaveLen(2))
+		Expect(cache.get("a.example.com")).To(BeNil())
+		Expect(cache.get("c.example.com")).To(HaveOccurred())
+
+		// The expired failures are dropped, even if never looked up again
+		now = now.Add(2 * time.Minute)
+		cache.add("d.example.com", errors.New("d"))
+		Expect(cache.entries).To(HaveLen(1))
+		Expect(cache.entries).To(HaveKey("d.example.com"))
+	})
+})
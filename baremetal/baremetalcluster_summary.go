@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterSummary is the state of a BareMetalCluster at a glance, e.g. for a
+// status command.
+type ClusterSummary struct {
+	// Ready is the readiness of the BareMetalCluster.
+	Ready bool
+	// EndpointURL is the URL of the ControlPlaneEndpoint, or empty if it is
+	// not set.
+	EndpointURL string
+	// Descendants is the number of Machines of the cluster.
+	Descendants int
+	// FailureMessage is the terminal failure of the BareMetalCluster, if any.
+	FailureMessage string
+	// LastUpdated is when the status was last updated.
+	LastUpdated *metav1.Time
+}
+
+// Summary returns the ClusterSummary of the BareMetalCluster. The Machines are
+// listed with a single request when the Cluster is known, the owner Cluster
+// is looked up first otherwise.
+func (s *ClusterManager) Summary(ctx context.Context) (ClusterSummary, error) {
+	status := s.BareMetalCluster.Status
+	summary := ClusterSummary{
+		Ready:       status.Ready,
+		LastUpdated: status.LastUpdated,
+	}
+	if status.FailureMessage != nil {
+		summary.FailureMessage = *status.FailureMessage
+	}
+	if endpoint := s.BareMetalCluster.Spec.ControlPlaneEndpoint; endpoint.Host != "" {
+		summary.EndpointURL = "https://" +
+			net.JoinHostPort(endpoint.Host, strconv.Itoa(endpoint.Port))
+	}
+
+	descendants, err := s.summaryDescendants(ctx)
+	if err != nil {
+		return ClusterSummary{}, err
+	}
+	summary.Descendants = descendants
+	return summary, nil
+}
+
+// summaryDescendants counts the Machines of the cluster, without looking up
+// the owner Cluster when it is already known.
+func (s *ClusterManager) summaryDescendants(ctx context.Context) (int, error) {
+	if s.Cluster == nil {
+		return s.CountDescendants(ctx)
+	}
+
+	machines, err := s.listClusterMachines(ctx, s.Cluster.Namespace,
+		s.Cluster.Name,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return len(machines.Items), nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	"k8s.io/utils/pointer"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalCluster summary", func() {
+
+	lastUpdated := metav1.Now()
+
+	type testCaseSummary struct {
+		Spec            *infrav1.BareMetalClusterSpec
+		Status          *infrav1.BareMetalClusterStatus
+		Descendants     int
+		ExpectedSummary ClusterSummary
+	}
+
+	DescribeTable("Test Summary",
+		func(tc testCaseSummary) {
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef,
+				tc.Spec, tc.Status,
+			)
+			objects := []runtime.Object{newCluster(clusterName), bmCluster}
+			for i := 0; i < tc.Descendants; i++ {
+				objects = append(objects,
+					newDescendantMachine("machine-"+string(rune('a'+i)), ""),
+				)
+			}
+			clusterMgr, err := NewClusterManager(
+				fakeclient.NewFakeClientWithScheme(setupScheme(), objects...),
+				newCluster(clusterName), bmCluster, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			summary, err := clusterMgr.Summary(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary).To(Equal(tc.ExpectedSummary))
+		},
+		Entry("Ready cluster", testCaseSummary{
+			Spec: bmcSpec(),
+			Status: &infrav1.BareMetalClusterStatus{
+				Ready:       true,
+				LastUpdated: &lastUpdated,
+			},
+			Descendants: 2,
+			ExpectedSummary: ClusterSummary{
+				Ready:       true,
+				EndpointURL: "https://192.168.111.249:6443",
+				Descendants: 2,
+				LastUpdated: &lastUpdated,
+			},
+		}),
+		Entry("Failed cluster", testCaseSummary{
+			Spec: bmcSpecAPIEmpty(),
+			Status: &infrav1.BareMetalClusterStatus{
+				FailureMessage: pointer.StringPtr("Invalid ControlPlaneEndpoint values"),
+				LastUpdated:    &lastUpdated,
+			},
+			ExpectedSummary: ClusterSummary{
+				Ready:          false,
+				FailureMessage: "Invalid ControlPlaneEndpoint values",
+				LastUpdated:    &lastUpdated,
+			},
+		}),
+	)
+})
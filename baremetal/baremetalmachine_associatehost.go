@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AssociateHost binds the named BareMetalHost, already deployed with the
+// image of the BareMetalMachine, to the machine without reprovisioning it,
+// e.g. to import pre-provisioned servers. The host must be in the namespace
+// of the BareMetalMachine, provisioned or externally provisioned, and not
+// consumed by another machine. The providerID is set and the machine is
+// marked Provisioned.
+func (m *MachineManager) AssociateHost(ctx context.Context, hostName string) error {
+	host := &bmh.BareMetalHost{}
+	key := client.ObjectKey{
+		Name:      hostName,
+		Namespace: m.BareMetalMachine.Namespace,
+	}
+	if err := m.client.Get(ctx, key, host); err != nil {
+		if apierrors.IsNotFound(err) {
+			return errors.Errorf("BareMetalHost %s/%s not found",
+				key.Namespace, key.Name,
+			)
+		}
+		return errors.Wrapf(err, "failed to get BareMetalHost %s/%s",
+			key.Namespace, key.Name,
+		)
+	}
+
+	switch host.Status.Provisioning.State {
+	case bmh.StateProvisioned, bmh.StateExternallyProvisioned:
+	default:
+		return errors.Errorf("BareMetalHost %s is in state %q, it is not deployed",
+			host.Name, host.Status.Provisioning.State,
+		)
+	}
+	if !m.hostImageMatches(host) {
+		return errors.Errorf("BareMetalHost %s is not deployed with the image of the BareMetalMachine",
+			host.Name,
+		)
+	}
+
+	if err := m.checkHostConsumer(ctx, host); err != nil {
+		return err
+	}
+
+	m.Log.Info("Associating machine with deployed host", "host", host.Name)
+	if err := m.setHostLabel(ctx, host); err != nil {
+		return errors.Wrapf(err, "failed to set the Cluster label in BareMetalHost %s",
+			host.Name,
+		)
+	}
+	// The image of the host is set, so it is not reprovisioned
+	if err := m.setHostSpec(ctx, host); err != nil {
+		return errors.Wrapf(err, "failed to associate BareMetalHost %s", host.Name)
+	}
+	if err := m.ensureAnnotation(ctx, host); err != nil {
+		return err
+	}
+
+	m.BareMetalMachine.Status.FailureDomain = hostFailureDomain(host)
+	m.BareMetalMachine.Status.HostName = pointer.StringPtr(host.Name)
+	m.SetProviderID(m.ProviderID(string(host.UID)))
+	m.BareMetalMachine.Status.Phase = capm3.BareMetalMachinePhaseProvisioned
+	return nil
+}
+
+// hostImageMatches returns true if the host is deployed with the image and
+// checksum of the BareMetalMachine.
+func (m *MachineManager) hostImageMatches(host *bmh.BareMetalHost) bool {
+	image := host.Spec.Image
+	if image == nil {
+		return false
+	}
+	return capm3.NormalizeImageURL(image.URL) == m.imageURL(host) &&
+		image.Checksum == m.BareMetalMachine.Spec.Image.Checksum
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/klogr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalMachine association of a deployed host", func() {
+
+	deployedHost := func(state bmh.ProvisioningState, image *bmh.Image) *bmh.BareMetalHost {
+		host := newAvailabilityHost("myhost", "myns", state, false)
+		host.UID = types.UID("myhost-uid")
+		host.Spec.Image = image
+		return host
+	}
+
+	testImage := func() *bmh.Image {
+		return &bmh.Image{URL: testImageURL, Checksum: testImageChecksumURL}
+	}
+
+	type testCaseAssociateHost struct {
+		Host        *bmh.BareMetalHost
+		HostName    string
+		ExpectError bool
+	}
+
+	DescribeTable("Test AssociateHost",
+		func(tc testCaseAssociateHost) {
+			bmMachine := newBareMetalMachine("mybmmachine", nil, bmmSpecAll(),
+				nil, nil,
+			)
+			bmMachine.Spec.ProviderID = nil
+			objects := []runtime.Object{bmMachine}
+			if tc.Host != nil {
+				objects = append(objects, tc.Host)
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), objects...)
+			machineMgr, err := NewMachineManager(c, nil, nil,
+				newMachine("mymachine", "mybmmachine", nil), bmMachine,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = machineMgr.AssociateHost(context.TODO(), tc.HostName)
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				Expect(bmMachine.Spec.ProviderID).To(BeNil())
+				Expect(machineMgr.HasAnnotation()).To(BeFalse())
+				if tc.Host != nil {
+					savedHost := bmh.BareMetalHost{}
+					Expect(c.Get(context.TODO(), client.ObjectKey{
+						Name: tc.Host.Name, Namespace: "myns",
+					}, &savedHost)).To(Succeed())
+					Expect(savedHost.Spec.ConsumerRef).To(Equal(tc.Host.Spec.ConsumerRef))
+				}
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(machineMgr.HasAnnotation()).To(BeTrue())
+			Expect(*bmMachine.Spec.ProviderID).To(Equal("metal3://myhost-uid"))
+			Expect(bmMachine.Status.Ready).To(BeTrue())
+			Expect(bmMachine.Status.Phase).To(Equal(
+				capm3.BareMetalMachinePhaseProvisioned,
+			))
+			Expect(*bmMachine.Status.HostName).To(Equal("myhost"))
+
+			savedHost := bmh.BareMetalHost{}
+			Expect(c.Get(context.TODO(), client.ObjectKey{
+				Name: "myhost", Namespace: "myns",
+			}, &savedHost)).To(Succeed())
+			Expect(savedHost.Spec.ConsumerRef).NotTo(BeNil())
+			Expect(savedHost.Spec.ConsumerRef.Name).To(Equal("mybmmachine"))
+			// Not reprovisioned
+			Expect(savedHost.Spec.Image).To(Equal(testImage()))
+		},
+		Entry("Deployed host", testCaseAssociateHost{
+			Host:     deployedHost(bmh.StateProvisioned, testImage()),
+			HostName: "myhost",
+		}),
+		Entry("Externally provisioned host", testCaseAssociateHost{
+			Host:     deployedHost(bmh.StateExternallyProvisioned, testImage()),
+			HostName: "myhost",
+		}),
+		Entry("Missing host", testCaseAssociateHost{
+			HostName:    "myhost",
+			ExpectError: true,
+		}),
+		Entry("Available host, not deployed", testCaseAssociateHost{
+			Host:        deployedHost(bmh.StateReady, nil),
+			HostName:    "myhost",
+			ExpectError: true,
+		}),
+		Entry("Deployed with another image", testCaseAssociateHost{
+			Host: deployedHost(bmh.StateProvisioned, &bmh.Image{
+				URL: "http://172.22.0.1/other.qcow2", Checksum: testImageChecksumURL,
+			}),
+			HostName:    "myhost",
+			ExpectError: true,
+		}),
+		Entry("Consumed by another machine", testCaseAssociateHost{
+			Host: func() *bmh.BareMetalHost {
+				host := deployedHost(bmh.StateProvisioned, testImage())
+				host.Spec.ConsumerRef = &corev1.ObjectReference{
+					Kind:      "BareMetalMachine",
+					Name:      "someoneelsesmachine",
+					Namespace: "myns",
+				}
+				return host
+			}(),
+			HostName:    "myhost",
+			ExpectError: true,
+		}),
+	)
+})
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"encoding/json"
+	"time"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// BindingAuditAnnotation is the key for an annotation set on a
+	// BareMetalHost recording the last time a BareMetalMachine claimed or
+	// released it.
+	BindingAuditAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/binding"
+	// BindingAuditActor identifies this controller in the binding audit.
+	BindingAuditActor = "baremetalmachine-controller"
+)
+
+// BindingAction is the change of binding between a machine and a host.
+type BindingAction string
+
+const (
+	// BindingActionClaim is recorded when a BareMetalMachine takes a host.
+	BindingActionClaim BindingAction = "claim"
+	// BindingActionRelease is recorded when a BareMetalMachine frees a host.
+	BindingActionRelease BindingAction = "release"
+)
+
+// BindingAudit is the content of the BindingAuditAnnotation.
+type BindingAudit struct {
+	Action    BindingAction `json:"action"`
+	Host      string        `json:"host"`
+	Machine   string        `json:"machine"`
+	Timestamp string        `json:"timestamp"`
+	Actor     string        `json:"actor"`
+}
+
+// AuditBinding logs the claim or release of the host by the BareMetalMachine
+// and records it in the BindingAuditAnnotation of the host. The host is not
+// updated, this is left to the caller.
+func (m *MachineManager) AuditBinding(host *bmh.BareMetalHost, action BindingAction) error {
+	hostKey, err := cache.MetaNamespaceKeyFunc(host)
+	if err != nil {
+		return err
+	}
+	machineKey, err := cache.MetaNamespaceKeyFunc(m.BareMetalMachine)
+	if err != nil {
+		return err
+	}
+	audit := BindingAudit{
+		Action:    action,
+		Host:      hostKey,
+		Machine:   machineKey,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Actor:     BindingAuditActor,
+	}
+	value, err := json.Marshal(audit)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the binding audit")
+	}
+
+	m.Log.Info("Audit BareMetalHost binding", "action", audit.Action,
+		"host", audit.Host, "baremetalmachine", audit.Machine,
+		"timestamp", audit.Timestamp, "actor", audit.Actor,
+	)
+
+	if host.Annotations == nil {
+		host.Annotations = map[string]string{}
+	}
+	host.Annotations[BindingAuditAnnotation] = string(value)
+	return nil
+}
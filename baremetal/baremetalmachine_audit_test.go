@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalHost binding audit", func() {
+
+	auditedMachine := func() *capm3.BareMetalMachine {
+		return &capm3.BareMetalMachine{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "BareMetalMachine",
+				APIVersion: capm3.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "mybmmachine",
+				Namespace: "myns",
+			},
+		}
+	}
+
+	getAudit := func(host *bmh.BareMetalHost) BindingAudit {
+		value, ok := host.Annotations[BindingAuditAnnotation]
+		Expect(ok).To(BeTrue())
+		audit := BindingAudit{}
+		Expect(json.Unmarshal([]byte(value), &audit)).To(Succeed())
+		return audit
+	}
+
+	DescribeTable("Test AuditBinding",
+		func(action BindingAction, annotations map[string]string) {
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "host1",
+					Namespace:   "myns",
+					Annotations: annotations,
+				},
+			}
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil,
+				auditedMachine(), klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			before := time.Now().UTC().Truncate(time.Second)
+			Expect(machineMgr.AuditBinding(host, action)).To(Succeed())
+
+			audit := getAudit(host)
+			Expect(audit.Action).To(Equal(action))
+			Expect(audit.Host).To(Equal("myns/host1"))
+			Expect(audit.Machine).To(Equal("myns/mybmmachine"))
+			Expect(audit.Actor).To(Equal(BindingAuditActor))
+			timestamp, err := time.Parse(time.RFC3339, audit.Timestamp)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(timestamp.Before(before)).To(BeFalse())
+			for key, value := range annotations {
+				if key != BindingAuditAnnotation {
+					Expect(host.Annotations[key]).To(Equal(value))
+				}
+			}
+		},
+		Entry("Claim, no annotations", BindingActionClaim, nil),
+		Entry("Release, previous claim", BindingActionRelease,
+			map[string]string{
+				BindingAuditAnnotation: `{"action":"claim"}`,
+				"foo":                  "bar",
+			},
+		),
+	)
+
+	type testCaseSetHostSpecAudit struct {
+		ConsumerRef *corev1.ObjectReference
+		ExpectAudit bool
+	}
+
+	DescribeTable("Test setHostSpec binding audit",
+		func(tc testCaseSetHostSpecAudit) {
+			host := newBareMetalHost("host1", &bmh.BareMetalHostSpec{
+				ConsumerRef: tc.ConsumerRef,
+			}, bmh.StateReady, nil, false, false)
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+			machineMgr, err := NewMachineManager(c, nil, nil,
+				newMachine("machine1", "", nil), auditedMachine(), klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(machineMgr.setHostSpec(context.TODO(), host)).To(Succeed())
+
+			savedHost := bmh.BareMetalHost{}
+			Expect(c.Get(context.TODO(),
+				client.ObjectKey{Name: host.Name, Namespace: host.Namespace},
+				&savedHost,
+			)).To(Succeed())
+			if !tc.ExpectAudit {
+				Expect(savedHost.Annotations).NotTo(HaveKey(BindingAuditAnnotation))
+				return
+			}
+			Expect(getAudit(&savedHost).Action).To(Equal(BindingActionClaim))
+		},
+		Entry("New binding", testCaseSetHostSpecAudit{
+			ConsumerRef: nil,
+			ExpectAudit: true,
+		}),
+		Entry("Existing binding", testCaseSetHostSpecAudit{
+			ConsumerRef: &corev1.ObjectReference{
+				Kind:       "BareMetalMachine",
+				Name:       "mybmmachine",
+				Namespace:  "myns",
+				APIVersion: capm3.GroupVersion.String(),
+			},
+			ExpectAudit: false,
+		}),
+	)
+
+	It("Records the release on Delete", func() {
+		host := newBareMetalHost("host1", &bmh.BareMetalHostSpec{
+			ConsumerRef: &corev1.ObjectReference{
+				Kind:       "BareMetalMachine",
+				Name:       "mybmmachine",
+				Namespace:  "myns",
+				APIVersion: capm3.GroupVersion.String(),
+			},
+		}, bmh.StateReady, nil, false, false)
+		bmMachine := auditedMachine()
+		bmMachine.Annotations = map[string]string{
+			HostAnnotation: "myns/host1",
+		}
+		c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host, bmMachine)
+		machineMgr, err := NewMachineManager(c, nil, nil,
+			newMachine("machine1", "", nil), bmMachine, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(machineMgr.Delete(context.TODO())).To(Succeed())
+
+		savedHost := bmh.BareMetalHost{}
+		Expect(c.Get(context.TODO(),
+			client.ObjectKey{Name: host.Name, Namespace: host.Namespace},
+			&savedHost,
+		)).To(Succeed())
+		Expect(savedHost.Spec.ConsumerRef).To(BeNil())
+		Expect(getAudit(&savedHost).Action).To(Equal(BindingActionRelease))
+	})
+})
@@ -0,0 +1,55 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// secureBootRequestedReason is the reason of the SecureBootRequired
+// condition.
+const secureBootRequestedReason = "SecureBootRequested"
+
+// bootMode returns the boot mode of the host, UEFI if unset.
+func (m *MachineManager) bootMode() capm3.BootMode {
+	if m.BareMetalMachine.Spec.BootMode == "" {
+		return capm3.BootModeUEFI
+	}
+	return m.BareMetalMachine.Spec.BootMode
+}
+
+// setSecureBootCondition records, in the SecureBootRequired condition, that
+// the host must support UEFI secure boot. The condition is removed for the
+// other boot modes.
+func (m *MachineManager) setSecureBootCondition() {
+	conditions := &m.BareMetalMachine.Status.Conditions
+	if m.bootMode() != capm3.BootModeUEFISecureBoot {
+		if conditions.Get(capm3.SecureBootRequiredCondition) != nil {
+			conditions.Remove(capm3.SecureBootRequiredCondition)
+		}
+		return
+	}
+
+	m.Log.Info("Host must support UEFI secure boot")
+	conditions.Set(capm3.Condition{
+		Type:    capm3.SecureBootRequiredCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  secureBootRequestedReason,
+		Message: "The host firmware must support UEFI secure boot",
+	})
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"k8s.io/klog/klogr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalMachine boot mode", func() {
+
+	type testCaseBootMode struct {
+		BootMode           capm3.BootMode
+		ExpectedBootMode   string
+		ExpectedSecureBoot bool
+	}
+
+	DescribeTable("Test SetHostSpec with a BootMode",
+		func(tc testCaseBootMode) {
+			host := newBareMetalHost("host2", nil, bmh.StateNone, nil, false,
+				false,
+			)
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+			bmMachine, infrastructureRef := newConfig("",
+				map[string]string{}, []capm3.HostSelectorRequirement{},
+			)
+			bmMachine.Spec.BootMode = tc.BootMode
+			machineMgr, err := NewMachineManager(c, nil, nil,
+				newMachine("machine1", "", infrastructureRef), bmMachine,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(machineMgr.setHostSpec(context.TODO(), host)).To(Succeed())
+
+			savedHost := bmh.BareMetalHost{}
+			Expect(c.Get(context.TODO(), client.ObjectKey{
+				Name: host.Name, Namespace: host.Namespace,
+			}, &savedHost)).To(Succeed())
+			Expect(savedHost.Annotations[BootModeAnnotation]).To(Equal(
+				tc.ExpectedBootMode,
+			))
+			Expect(bmMachine.Status.Conditions.IsTrue(
+				capm3.SecureBootRequiredCondition,
+			)).To(Equal(tc.ExpectedSecureBoot))
+		},
+		Entry("Defaults to UEFI", testCaseBootMode{
+			ExpectedBootMode: "UEFI",
+		}),
+		Entry("UEFI", testCaseBootMode{
+			BootMode:         capm3.BootModeUEFI,
+			ExpectedBootMode: "UEFI",
+		}),
+		Entry("Legacy", testCaseBootMode{
+			BootMode:         capm3.BootModeLegacy,
+			ExpectedBootMode: "legacy",
+		}),
+		Entry("UEFI secure boot", testCaseBootMode{
+			BootMode:           capm3.BootModeUEFISecureBoot,
+			ExpectedBootMode:   "UEFISecureBoot",
+			ExpectedSecureBoot: true,
+		}),
+	)
+})
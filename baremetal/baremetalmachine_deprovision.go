@@ -0,0 +1,73 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"fmt"
+	"time"
+
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+)
+
+// ForceDeleteAnnotation is set on a BareMetalMachine by an operator to stop
+// waiting for its host to deprovision, e.g. once the machine is Failed after
+// the DeprovisionTimeout. The host is released as is, and the finalizer
+// removed.
+const ForceDeleteAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/force-delete"
+
+// startDeprovisioning records when the host started deprovisioning, unless
+// it is already recorded.
+func (m *MachineManager) startDeprovisioning() {
+	if m.BareMetalMachine.Status.DeprovisionStartTime != nil {
+		return
+	}
+	now := metav1.Now()
+	m.BareMetalMachine.Status.DeprovisionStartTime = &now
+}
+
+// forceDeleteRequested returns true if the ForceDeleteAnnotation is set.
+func (m *MachineManager) forceDeleteRequested() bool {
+	_, ok := m.BareMetalMachine.Annotations[ForceDeleteAnnotation]
+	return ok
+}
+
+// checkDeprovisionTimeout is called while waiting for the host to
+// deprovision. The machine is set Failed once the DeprovisionTimeout is
+// exceeded.
+func (m *MachineManager) checkDeprovisionTimeout(hostName string) {
+	m.startDeprovisioning()
+
+	timeout := m.BareMetalMachine.Spec.DeprovisionTimeout
+	if timeout == nil || timeout.Duration == 0 {
+		return
+	}
+	start := m.BareMetalMachine.Status.DeprovisionStartTime
+	if time.Since(start.Time) < timeout.Duration {
+		return
+	}
+
+	m.Log.Info("Timed out deprovisioning BaremetalHost", "host", hostName,
+		"timeout", timeout.Duration,
+	)
+	m.BareMetalMachine.Status.Phase = capm3.BareMetalMachinePhaseFailed
+	m.setError(fmt.Sprintf(
+		"Host %s did not deprovision within %s, set the %s annotation to delete the machine anyway",
+		hostName, timeout.Duration, ForceDeleteAnnotation,
+	), capierrors.DeleteMachineError)
+}
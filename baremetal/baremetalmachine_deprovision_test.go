@@ -0,0 +1,164 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalMachine deprovision timeout", func() {
+
+	type testCaseDeprovisionTimeout struct {
+		Timeout             *metav1.Duration
+		StartedAgo          time.Duration
+		ForceDelete         bool
+		ExpectRequeue       bool
+		ExpectFailed        bool
+		ExpectHostReleased  bool
+		ExpectStartRecorded bool
+	}
+
+	DescribeTable("Test Delete with DeprovisionTimeout",
+		func(tc testCaseDeprovisionTimeout) {
+			host := newBareMetalHost("myhost", bmhSpecNoImg(),
+				bmh.StateDeprovisioning, bmhStatus(), false, false,
+			)
+			objMeta := bmmObjectMetaWithValidAnnotations()
+			if tc.ForceDelete {
+				objMeta.Annotations[ForceDeleteAnnotation] = ""
+			}
+			status := &capm3.BareMetalMachineStatus{}
+			if tc.StartedAgo != 0 {
+				start := metav1.NewTime(time.Now().Add(-tc.StartedAgo))
+				status.DeprovisionStartTime = &start
+			}
+			spec := bmmSecret()
+			spec.DeprovisionTimeout = tc.Timeout
+			bmMachine := newBareMetalMachine("mybmmachine", nil, spec, status,
+				objMeta,
+			)
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host,
+				newSecret(),
+			)
+
+			machineMgr, err := NewMachineManager(c, nil, nil,
+				newMachine("mymachine", "", nil), bmMachine, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = machineMgr.Delete(context.TODO())
+			if tc.ExpectRequeue {
+				_, ok := err.(*RequeueAfterError)
+				Expect(ok).To(BeTrue())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			if tc.ExpectFailed {
+				Expect(bmMachine.Status.Phase).To(Equal(capm3.BareMetalMachinePhaseFailed))
+				Expect(bmMachine.Status.FailureReason).NotTo(BeNil())
+				Expect(*bmMachine.Status.FailureReason).To(
+					Equal(capierrors.DeleteMachineError),
+				)
+				Expect(*bmMachine.Status.FailureMessage).To(
+					ContainSubstring(ForceDeleteAnnotation),
+				)
+			} else {
+				Expect(bmMachine.Status.Phase).NotTo(Equal(capm3.BareMetalMachinePhaseFailed))
+				Expect(bmMachine.Status.FailureReason).To(BeNil())
+			}
+
+			if tc.ExpectStartRecorded {
+				Expect(bmMachine.Status.DeprovisionStartTime).NotTo(BeNil())
+			}
+
+			savedHost := bmh.BareMetalHost{}
+			Expect(c.Get(context.TODO(), client.ObjectKey{
+				Name:      host.Name,
+				Namespace: host.Namespace,
+			}, &savedHost)).To(Succeed())
+			if tc.ExpectHostReleased {
+				Expect(savedHost.Spec.ConsumerRef).To(BeNil())
+			} else {
+				Expect(savedHost.Spec.ConsumerRef).NotTo(BeNil())
+			}
+		},
+		Entry("No timeout", testCaseDeprovisionTimeout{
+			StartedAgo:          24 * time.Hour,
+			ExpectRequeue:       true,
+			ExpectStartRecorded: true,
+		}),
+		Entry("Start time recorded", testCaseDeprovisionTimeout{
+			Timeout:             &metav1.Duration{Duration: time.Hour},
+			ExpectRequeue:       true,
+			ExpectStartRecorded: true,
+		}),
+		Entry("Timeout not exceeded", testCaseDeprovisionTimeout{
+			Timeout:             &metav1.Duration{Duration: time.Hour},
+			StartedAgo:          time.Minute,
+			ExpectRequeue:       true,
+			ExpectStartRecorded: true,
+		}),
+		Entry("Timeout exceeded", testCaseDeprovisionTimeout{
+			Timeout:             &metav1.Duration{Duration: time.Hour},
+			StartedAgo:          2 * time.Hour,
+			ExpectRequeue:       true,
+			ExpectFailed:        true,
+			ExpectStartRecorded: true,
+		}),
+		Entry("Timeout exceeded, force delete", testCaseDeprovisionTimeout{
+			Timeout:            &metav1.Duration{Duration: time.Hour},
+			StartedAgo:         2 * time.Hour,
+			ForceDelete:        true,
+			ExpectHostReleased: true,
+		}),
+	)
+
+	It("Records the start time when deprovisioning is requested", func() {
+		host := newBareMetalHost("myhost", bmhSpec(), bmh.StateProvisioned,
+			bmhStatus(), false, false,
+		)
+		bmMachine := newBareMetalMachine("mybmmachine", nil, bmmSecret(), nil,
+			bmmObjectMetaWithValidAnnotations(),
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host,
+			newSecret(),
+		)
+		machineMgr, err := NewMachineManager(c, nil, nil,
+			newMachine("mymachine", "", nil), bmMachine, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = machineMgr.Delete(context.TODO())
+		_, ok := err.(*RequeueAfterError)
+		Expect(ok).To(BeTrue())
+		Expect(bmMachine.Status.DeprovisionStartTime).NotTo(BeNil())
+	})
+})
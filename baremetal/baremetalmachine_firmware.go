@@ -0,0 +1,41 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"encoding/json"
+)
+
+const (
+	// FirmwareAnnotation is the key for an annotation set on a BareMetalHost
+	// to pass the firmware settings of the BareMetalMachine, as JSON.
+	FirmwareAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/firmware"
+)
+
+// firmwareSettings returns the JSON encoded firmware settings of the
+// machine, or an empty string if there are none.
+func (m *MachineManager) firmwareSettings() (string, error) {
+	firmware := m.BareMetalMachine.Spec.Firmware
+	if firmware == nil {
+		return "", nil
+	}
+	settings, err := json.Marshal(firmware)
+	if err != nil {
+		return "", err
+	}
+	return string(settings), nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"strings"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// hostErrorReason is the reason of the HostError condition when the host
+// does not give the type of its error.
+const hostErrorReason = "HostError"
+
+// setHostErrorCondition reflects the error reported by the host in the
+// HostError condition, so that it can be seen without inspecting the host.
+// The condition is removed once the host recovers.
+func (m *MachineManager) setHostErrorCondition(host *bmh.BareMetalHost) {
+	conditions := &m.BareMetalMachine.Status.Conditions
+	if host == nil || host.Status.ErrorMessage == "" {
+		if conditions.Get(capm3.HostErrorCondition) != nil {
+			m.Log.Info("Host recovered from its error")
+			conditions.Remove(capm3.HostErrorCondition)
+		}
+		return
+	}
+
+	conditions.Set(capm3.Condition{
+		Type:    capm3.HostErrorCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  hostErrorConditionReason(host.Status.ErrorType),
+		Message: host.Status.ErrorMessage,
+	})
+}
+
+// hostErrorConditionReason turns the error type of the host, e.g.
+// "provisioning error", into a CamelCase reason, e.g. "ProvisioningError".
+func hostErrorConditionReason(errorType bmh.ErrorType) string {
+	reason := strings.Replace(strings.Title(string(errorType)), " ", "", -1)
+	if reason == "" {
+		return hostErrorReason
+	}
+	return reason
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+)
+
+var _ = Describe("BareMetalMachine host error", func() {
+
+	var host *bmh.BareMetalHost
+	var bmMachine *capm3.BareMetalMachine
+	var machineMgr *MachineManager
+
+	BeforeEach(func() {
+		host = &bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "myhost",
+				Namespace: "myns",
+			},
+		}
+		bmMachine = newBareMetalMachine("mybmmachine", nil, nil, nil, nil)
+		var err error
+		machineMgr, err = NewMachineManager(nil, nil, nil, nil, bmMachine,
+			klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Reflects the error of the host", func() {
+		host.Status.OperationalStatus = bmh.OperationalStatusError
+		host.Status.ErrorType = bmh.ProvisioningError
+		host.Status.ErrorMessage = "Image provisioning failed: timeout"
+
+		machineMgr.setHostErrorCondition(host)
+
+		Expect(bmMachine.Status.Conditions.IsTrue(capm3.HostErrorCondition)).To(BeTrue())
+		condition := bmMachine.Status.Conditions.Get(capm3.HostErrorCondition)
+		Expect(condition.Reason).To(Equal("ProvisioningError"))
+		Expect(condition.Message).To(Equal("Image provisioning failed: timeout"))
+	})
+
+	It("Uses a generic reason without an error type", func() {
+		host.Status.ErrorMessage = "Something went wrong"
+
+		machineMgr.setHostErrorCondition(host)
+
+		condition := bmMachine.Status.Conditions.Get(capm3.HostErrorCondition)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal(hostErrorReason))
+	})
+
+	It("Clears the condition once the host recovers", func() {
+		host.Status.OperationalStatus = bmh.OperationalStatusError
+		host.Status.ErrorType = bmh.PowerManagementError
+		host.Status.ErrorMessage = "Failed to power on"
+		machineMgr.setHostErrorCondition(host)
+		Expect(bmMachine.Status.Conditions.Get(capm3.HostErrorCondition)).NotTo(BeNil())
+
+		host.Status.OperationalStatus = bmh.OperationalStatusOK
+		host.Status.ErrorType = ""
+		host.Status.ErrorMessage = ""
+		machineMgr.setHostErrorCondition(host)
+
+		Expect(bmMachine.Status.Conditions.Get(capm3.HostErrorCondition)).To(BeNil())
+	})
+
+	It("Leaves a healthy host without condition", func() {
+		machineMgr.setHostErrorCondition(host)
+
+		Expect(bmMachine.Status.Conditions).To(BeEmpty())
+	})
+})
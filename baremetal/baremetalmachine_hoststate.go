@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"fmt"
+	"strings"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// hostStateUnknownReason is the reason of the Associating condition while the
+// host has not reported a provisioning state yet.
+const hostStateUnknownReason = "StateUnknown"
+
+// hostStateCondition returns the condition reflecting the provisioning state
+// of the host, with its status and severity. The error states set the
+// condition of the phase they failed in to False. An unknown state is
+// reported as Unknown in the Associating condition.
+func hostStateCondition(state bmh.ProvisioningState) (capm3.ConditionType,
+	corev1.ConditionStatus, capm3.ConditionSeverity) {
+
+	switch state {
+	case bmh.StateNone, bmh.StateRegistering, bmh.StateMatchProfile,
+		bmh.StateInspecting, bmh.StateReady, bmh.StateAvailable:
+		return capm3.AssociatingCondition, corev1.ConditionTrue, capm3.ConditionSeverityNone
+	case bmh.StateRegistrationError:
+		return capm3.AssociatingCondition, corev1.ConditionFalse, capm3.ConditionSeverityError
+	case bmh.StateProvisioning:
+		return capm3.ProvisioningCondition, corev1.ConditionTrue, capm3.ConditionSeverityNone
+	case bmh.StateProvisioningError:
+		return capm3.ProvisioningCondition, corev1.ConditionFalse, capm3.ConditionSeverityError
+	case bmh.StateProvisioned, bmh.StateExternallyProvisioned:
+		return capm3.ProvisionedCondition, corev1.ConditionTrue, capm3.ConditionSeverityNone
+	case bmh.StatePowerManagementError:
+		// The host may recover once its BMC is reachable again
+		return capm3.ProvisionedCondition, corev1.ConditionFalse, capm3.ConditionSeverityWarning
+	case bmh.StateDeprovisioning, bmh.StateDeleting:
+		return capm3.DeprovisioningCondition, corev1.ConditionTrue, capm3.ConditionSeverityNone
+	}
+	return capm3.AssociatingCondition, corev1.ConditionUnknown, capm3.ConditionSeverityNone
+}
+
+// hostStateConditionTypes are the conditions reflecting the provisioning
+// state of the host, at most one of which is set.
+var hostStateConditionTypes = []capm3.ConditionType{
+	capm3.AssociatingCondition,
+	capm3.ProvisioningCondition,
+	capm3.ProvisionedCondition,
+	capm3.DeprovisioningCondition,
+}
+
+// setHostStateCondition reflects the provisioning state of the host in the
+// condition of the matching phase, and removes the conditions of the other
+// phases.
+func (m *MachineManager) setHostStateCondition(host *bmh.BareMetalHost) {
+	if host == nil {
+		return
+	}
+	state := host.Status.Provisioning.State
+	conditionType, status, severity := hostStateCondition(state)
+
+	conditions := &m.BareMetalMachine.Status.Conditions
+	for _, otherType := range hostStateConditionTypes {
+		if otherType != conditionType && conditions.Get(otherType) != nil {
+			conditions.Remove(otherType)
+		}
+	}
+	conditions.Set(capm3.Condition{
+		Type:     conditionType,
+		Status:   status,
+		Severity: severity,
+		Reason:   hostStateReason(state),
+		Message:  fmt.Sprintf("BareMetalHost %s is in state %q", host.Name, state),
+	})
+}
+
+// hostStateReason turns the provisioning state of the host, e.g. "match
+// profile", into a CamelCase reason, e.g. "MatchProfile".
+func hostStateReason(state bmh.ProvisioningState) string {
+	reason := strings.Replace(strings.Title(string(state)), " ", "", -1)
+	if reason == "" {
+		return hostStateUnknownReason
+	}
+	return reason
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/klogr"
+)
+
+var _ = Describe("BareMetalMachine host state conditions", func() {
+
+	type testCaseHostState struct {
+		State            bmh.ProvisioningState
+		ExpectedType     capm3.ConditionType
+		ExpectedStatus   corev1.ConditionStatus
+		ExpectedSeverity capm3.ConditionSeverity
+		ExpectedReason   string
+	}
+
+	DescribeTable("Test setHostStateCondition",
+		func(tc testCaseHostState) {
+			bmMachine := newBareMetalMachine("mybmmachine", nil, nil, nil, nil)
+			// Left over from a previous phase
+			bmMachine.Status.Conditions.Set(capm3.Condition{
+				Type:   capm3.DeprovisioningCondition,
+				Status: corev1.ConditionTrue,
+			})
+			if tc.ExpectedType == capm3.DeprovisioningCondition {
+				bmMachine.Status.Conditions.Set(capm3.Condition{
+					Type:   capm3.ProvisionedCondition,
+					Status: corev1.ConditionTrue,
+				})
+			}
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil, bmMachine,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+			host := newAvailabilityHost("myhost", "myns", tc.State, false)
+
+			machineMgr.setHostStateCondition(host)
+
+			conditions := bmMachine.Status.Conditions
+			Expect(conditions).To(HaveLen(1))
+			condition := conditions.Get(tc.ExpectedType)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(tc.ExpectedStatus))
+			Expect(condition.Severity).To(Equal(tc.ExpectedSeverity))
+			Expect(condition.Reason).To(Equal(tc.ExpectedReason))
+			Expect(condition.Message).To(ContainSubstring("myhost"))
+		},
+		Entry("No state", testCaseHostState{
+			State:          bmh.StateNone,
+			ExpectedType:   capm3.AssociatingCondition,
+			ExpectedStatus: corev1.ConditionTrue,
+			ExpectedReason: hostStateUnknownReason,
+		}),
+		Entry("Match profile", testCaseHostState{
+			State:          bmh.StateMatchProfile,
+			ExpectedType:   capm3.AssociatingCondition,
+			ExpectedStatus: corev1.ConditionTrue,
+			ExpectedReason: "MatchProfile",
+		}),
+		Entry("Registration error", testCaseHostState{
+			State:            bmh.StateRegistrationError,
+			ExpectedType:     capm3.AssociatingCondition,
+			ExpectedStatus:   corev1.ConditionFalse,
+			ExpectedSeverity: capm3.ConditionSeverityError,
+			ExpectedReason:   "RegistrationError",
+		}),
+		Entry("Provisioning", testCaseHostState{
+			State:          bmh.StateProvisioning,
+			ExpectedType:   capm3.ProvisioningCondition,
+			ExpectedStatus: corev1.ConditionTrue,
+			ExpectedReason: "Provisioning",
+		}),
+		Entry("Provisioning error", testCaseHostState{
+			State:            bmh.StateProvisioningError,
+			ExpectedType:     capm3.ProvisioningCondition,
+			ExpectedStatus:   corev1.ConditionFalse,
+			ExpectedSeverity: capm3.ConditionSeverityError,
+			ExpectedReason:   "ProvisioningError",
+		}),
+		Entry("Externally provisioned", testCaseHostState{
+			State:          bmh.StateExternallyProvisioned,
+			ExpectedType:   capm3.ProvisionedCondition,
+			ExpectedStatus: corev1.ConditionTrue,
+			ExpectedReason: "ExternallyProvisioned",
+		}),
+		Entry("Power management error", testCaseHostState{
+			State:            bmh.StatePowerManagementError,
+			ExpectedType:     capm3.ProvisionedCondition,
+			ExpectedStatus:   corev1.ConditionFalse,
+			ExpectedSeverity: capm3.ConditionSeverityWarning,
+			ExpectedReason:   "PowerManagementError",
+		}),
+		Entry("Deprovisioning", testCaseHostState{
+			State:          bmh.StateDeprovisioning,
+			ExpectedType:   capm3.DeprovisioningCondition,
+			ExpectedStatus: corev1.ConditionTrue,
+			ExpectedReason: "Deprovisioning",
+		}),
+		Entry("Unknown state", testCaseHostState{
+			State:          bmh.ProvisioningState("adopting"),
+			ExpectedType:   capm3.AssociatingCondition,
+			ExpectedStatus: corev1.ConditionUnknown,
+			ExpectedReason: "Adopting",
+		}),
+	)
+})
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"net/url"
+	"strings"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+)
+
+// ImageCacheAnnotation is the key for an annotation set on a BareMetalHost,
+// e.g. by a local image cache service, holding the base http(s) URL of a
+// cache that serves images under the same paths as their origin.
+const ImageCacheAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/image-cache"
+
+// imageURL returns the URL the host downloads the image from. With
+// PreferCachedImage, http(s) images are downloaded from the cache advertised
+// by the host, if any. The normalized Image URL is returned otherwise.
+func (m *MachineManager) imageURL(host *bmh.BareMetalHost) string {
+	imageURL := capm3.NormalizeImageURL(m.BareMetalMachine.Spec.Image.URL)
+	if !m.BareMetalMachine.Spec.PreferCachedImage {
+		return imageURL
+	}
+	cache, ok := host.Annotations[ImageCacheAnnotation]
+	if !ok {
+		return imageURL
+	}
+
+	cachedURL, ok := cachedImageURL(cache, imageURL)
+	if !ok {
+		m.Log.Info("Ignoring invalid image cache", "host", host.Name,
+			"cache", cache,
+		)
+		return imageURL
+	}
+	m.Log.Info("Using cached image", "host", host.Name, "url", cachedURL)
+	return cachedURL
+}
+
+// cachedImageURL returns the URL of the image in the cache: the path and
+// query of the image URL are appended to the cache URL. It returns false if
+// either URL is not an http(s) URL.
+func cachedImageURL(cache, imageURL string) (string, bool) {
+	cacheURL, err := url.Parse(cache)
+	if err != nil || !isHTTPURL(cacheURL) {
+		return "", false
+	}
+	image, err := url.Parse(imageURL)
+	if err != nil || !isHTTPURL(image) {
+		return "", false
+	}
+
+	cached := *cacheURL
+	cached.Path = strings.TrimSuffix(cacheURL.Path, "/") + image.Path
+	cached.RawPath = ""
+	cached.RawQuery = image.RawQuery
+	return cached.String(), true
+}
+
+func isHTTPURL(u *url.URL) bool {
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("BareMetalMachine image cache", func() {
+
+	type testCaseImageURL struct {
+		ImageURL          string
+		PreferCachedImage bool
+		Cache             *string
+		ExpectedURL       string
+	}
+
+	DescribeTable("Test imageURL",
+		func(tc testCaseImageURL) {
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "myhost",
+					Namespace: "myns",
+				},
+			}
+			if tc.Cache != nil {
+				host.Annotations = map[string]string{
+					ImageCacheAnnotation: *tc.Cache,
+				}
+			}
+			bmMachine := newBareMetalMachine("mybmmachine", nil,
+				&capm3.BareMetalMachineSpec{
+					Image:             capm3.Image{URL: tc.ImageURL},
+					PreferCachedImage: tc.PreferCachedImage,
+				}, nil, nil,
+			)
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil, bmMachine,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(machineMgr.imageURL(host)).To(Equal(tc.ExpectedURL))
+		},
+		Entry("Cache advertised", testCaseImageURL{
+			ImageURL:          "http://172.22.0.1/images/centos.qcow2",
+			PreferCachedImage: true,
+			Cache:             pointer.StringPtr("http://192.168.111.1:8080/cache/"),
+			ExpectedURL:       "http://192.168.111.1:8080/cache/images/centos.qcow2",
+		}),
+		Entry("Cache advertised, query kept", testCaseImageURL{
+			ImageURL:          "https://images.example.com/centos.qcow2?version=8",
+			PreferCachedImage: true,
+			Cache:             pointer.StringPtr("http://192.168.111.1:8080"),
+			ExpectedURL:       "http://192.168.111.1:8080/centos.qcow2?version=8",
+		}),
+		Entry("No cache advertised", testCaseImageURL{
+			ImageURL:          "http://172.22.0.1/images/centos.qcow2",
+			PreferCachedImage: true,
+			ExpectedURL:       "http://172.22.0.1/images/centos.qcow2",
+		}),
+		Entry("Cache not preferred", testCaseImageURL{
+			ImageURL:    "http://172.22.0.1/images/centos.qcow2",
+			Cache:       pointer.StringPtr("http://192.168.111.1:8080"),
+			ExpectedURL: "http://172.22.0.1/images/centos.qcow2",
+		}),
+		Entry("Invalid cache", testCaseImageURL{
+			ImageURL:          "http://172.22.0.1/images/centos.qcow2",
+			PreferCachedImage: true,
+			Cache:             pointer.StringPtr("192.168.111.1:8080"),
+			ExpectedURL:       "http://172.22.0.1/images/centos.qcow2",
+		}),
+		Entry("TFTP image", testCaseImageURL{
+			ImageURL:          "172.22.0.1:images/centos.qcow2",
+			PreferCachedImage: true,
+			Cache:             pointer.StringPtr("http://192.168.111.1:8080"),
+			ExpectedURL:       "tftp://172.22.0.1/images/centos.qcow2",
+		}),
+	)
+})
@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"fmt"
+	"strconv"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ImageDownloadProgressAnnotation is the key for an annotation set on a
+	// BareMetalHost, e.g. by the agent writing the image, holding the
+	// percentage of the image downloaded so far.
+	ImageDownloadProgressAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/image-download-progress"
+
+	imageDownloadStartingReason    = "DownloadStarting"
+	imageDownloadInProgressReason  = "DownloadInProgress"
+	imageDownloadFinishingReason   = "DownloadFinishing"
+	imageDownloadFinishingProgress = 90
+)
+
+// SetDownloadProgress records the percentage of the image downloaded by the
+// host, and sets the ImageDownloading condition with an estimate of the
+// download state. Both are cleared once the download is complete.
+func (m *MachineManager) SetDownloadProgress(percent int) {
+	if percent >= 100 {
+		m.clearDownloadProgress()
+		return
+	}
+	if percent < 0 {
+		percent = 0
+	}
+
+	reason := imageDownloadInProgressReason
+	switch {
+	case percent == 0:
+		reason = imageDownloadStartingReason
+	case percent >= imageDownloadFinishingProgress:
+		reason = imageDownloadFinishingReason
+	}
+	m.BareMetalMachine.Status.ImageDownloadProgress = &percent
+	m.BareMetalMachine.Status.Conditions.Set(capm3.Condition{
+		Type:    capm3.ImageDownloadingCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: fmt.Sprintf("The host downloaded %d%% of the image", percent),
+	})
+}
+
+// clearDownloadProgress removes the download progress and the
+// ImageDownloading condition.
+func (m *MachineManager) clearDownloadProgress() {
+	m.BareMetalMachine.Status.ImageDownloadProgress = nil
+	conditions := &m.BareMetalMachine.Status.Conditions
+	if conditions.Get(capm3.ImageDownloadingCondition) != nil {
+		conditions.Remove(capm3.ImageDownloadingCondition)
+	}
+}
+
+// updateDownloadProgress reflects the download progress reported by the host
+// while it is provisioning. It is cleared otherwise.
+func (m *MachineManager) updateDownloadProgress(host *bmh.BareMetalHost) {
+	percent, ok := hostDownloadProgress(host)
+	if !ok {
+		m.clearDownloadProgress()
+		return
+	}
+	m.SetDownloadProgress(percent)
+}
+
+// hostDownloadProgress returns the download progress reported by the host,
+// and false if the host is not provisioning or reports no valid progress.
+func hostDownloadProgress(host *bmh.BareMetalHost) (int, bool) {
+	if host == nil || host.Status.Provisioning.State != bmh.StateProvisioning {
+		return 0, false
+	}
+	value, ok := host.Annotations[ImageDownloadProgressAnnotation]
+	if !ok {
+		return 0, false
+	}
+	percent, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return percent, true
+}
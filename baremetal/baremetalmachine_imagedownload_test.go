@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("BareMetalMachine image download", func() {
+
+	progress := func(percent int) *int { return &percent }
+
+	type testCaseDownloadProgress struct {
+		Progress         []*string
+		HostState        bmh.ProvisioningState
+		ExpectedProgress *int
+		ExpectedReason   string
+	}
+
+	DescribeTable("Test updateDownloadProgress",
+		func(tc testCaseDownloadProgress) {
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "myhost",
+					Namespace: "myns",
+				},
+			}
+			host.Status.Provisioning.State = tc.HostState
+			bmMachine := newBareMetalMachine("mybmmachine", nil, nil, nil, nil)
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil, bmMachine,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Each reported progress is reflected in turn
+			for _, reported := range tc.Progress {
+				host.Annotations = map[string]string{}
+				if reported != nil {
+					host.Annotations[ImageDownloadProgressAnnotation] = *reported
+				}
+				machineMgr.updateDownloadProgress(host)
+			}
+
+			Expect(bmMachine.Status.ImageDownloadProgress).To(Equal(tc.ExpectedProgress))
+			condition := bmMachine.Status.Conditions.Get(capm3.ImageDownloadingCondition)
+			if tc.ExpectedReason == "" {
+				Expect(condition).To(BeNil())
+				return
+			}
+			Expect(condition).NotTo(BeNil())
+			Expect(bmMachine.Status.Conditions.IsTrue(capm3.ImageDownloadingCondition)).To(BeTrue())
+			Expect(condition.Reason).To(Equal(tc.ExpectedReason))
+		},
+		Entry("Download starting", testCaseDownloadProgress{
+			Progress:         []*string{pointer.StringPtr("0")},
+			HostState:        bmh.StateProvisioning,
+			ExpectedProgress: progress(0),
+			ExpectedReason:   imageDownloadStartingReason,
+		}),
+		Entry("Progress updated", testCaseDownloadProgress{
+			Progress: []*string{
+				pointer.StringPtr("10"), pointer.StringPtr("42"),
+			},
+			HostState:        bmh.StateProvisioning,
+			ExpectedProgress: progress(42),
+			ExpectedReason:   imageDownloadInProgressReason,
+		}),
+		Entry("Download finishing", testCaseDownloadProgress{
+			Progress:         []*string{pointer.StringPtr("95")},
+			HostState:        bmh.StateProvisioning,
+			ExpectedProgress: progress(95),
+			ExpectedReason:   imageDownloadFinishingReason,
+		}),
+		Entry("Completion clears the condition", testCaseDownloadProgress{
+			Progress: []*string{
+				pointer.StringPtr("42"), pointer.StringPtr("100"),
+			},
+			HostState: bmh.StateProvisioning,
+		}),
+		Entry("Host provisioned clears the condition", testCaseDownloadProgress{
+			Progress:  []*string{pointer.StringPtr("42")},
+			HostState: bmh.StateProvisioned,
+		}),
+		Entry("No progress reported", testCaseDownloadProgress{
+			Progress:  []*string{pointer.StringPtr("42"), nil},
+			HostState: bmh.StateProvisioning,
+		}),
+		Entry("Invalid progress", testCaseDownloadProgress{
+			Progress:  []*string{pointer.StringPtr("half")},
+			HostState: bmh.StateProvisioning,
+		}),
+	)
+})
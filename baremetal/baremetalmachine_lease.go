@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"time"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConsumerLeaseAnnotation is the key for an annotation set on a claimed
+// BareMetalHost giving the time, in RFC3339, at which its BareMetalMachine
+// last renewed the claim.
+const ConsumerLeaseAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/consumer-lease"
+
+// ConsumerLeaseTTL is the time after which the claim of a host whose lease
+// was not renewed is stale. A host with a stale claim is reclaimed if its
+// BareMetalMachine no longer exists. 0 never reclaims the hosts.
+var ConsumerLeaseTTL = 10 * time.Minute
+
+// renewConsumerLease sets the ConsumerLeaseAnnotation of the host to the
+// current time if the lease is missing or was last renewed more than half of
+// ConsumerLeaseTTL ago. Renewing it on every call would change the host, and
+// trigger a new reconciliation, each time. The host is not updated, this is
+// left to the caller.
+func (m *MachineManager) renewConsumerLease(host *bmh.BareMetalHost) {
+	now := time.Now()
+	renewed, err := time.Parse(time.RFC3339, host.Annotations[ConsumerLeaseAnnotation])
+	if err == nil && (ConsumerLeaseTTL <= 0 || now.Before(renewed.Add(ConsumerLeaseTTL/2))) {
+		return
+	}
+	if host.Annotations == nil {
+		host.Annotations = map[string]string{}
+	}
+	host.Annotations[ConsumerLeaseAnnotation] = now.UTC().Format(time.RFC3339)
+}
+
+// consumerLeaseExpired returns true if the lease of the host was last renewed
+// more than ConsumerLeaseTTL ago. A host without a valid lease, e.g. claimed
+// before the leases were introduced, never expires.
+func consumerLeaseExpired(host *bmh.BareMetalHost, now time.Time) bool {
+	if ConsumerLeaseTTL <= 0 {
+		return false
+	}
+	renewed, err := time.Parse(time.RFC3339, host.Annotations[ConsumerLeaseAnnotation])
+	if err != nil {
+		return false
+	}
+	return now.After(renewed.Add(ConsumerLeaseTTL))
+}
+
+// reclaimOrphanedHost releases the host if its lease expired and the
+// BareMetalMachine of its ConsumerRef no longer exists, e.g. when the
+// controller crashed while provisioning it. The image, user data and the
+// annotations set for the BareMetalMachine are cleared so that the host
+// deprovisions before it is available again. It returns true if the host was
+// reclaimed.
+func (m *MachineManager) reclaimOrphanedHost(ctx context.Context, host *bmh.BareMetalHost) (bool, error) {
+	consumer := host.Spec.ConsumerRef
+	if consumer == nil || !consumerLeaseExpired(host, time.Now()) {
+		return false, nil
+	}
+
+	key := client.ObjectKey{Name: consumer.Name, Namespace: consumer.Namespace}
+	err := m.client.Get(ctx, key, &capm3.BareMetalMachine{})
+	if err == nil {
+		return false, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "failed to get the consumer of BareMetalHost %s",
+			host.Name,
+		)
+	}
+
+	m.Log.Info("Reclaiming BareMetalHost with an expired lease", "host", host.Name,
+		"consumer", consumer.Namespace+"/"+consumer.Name,
+		"lease", host.Annotations[ConsumerLeaseAnnotation],
+	)
+	refs := []metav1.OwnerReference{}
+	for _, ref := range host.OwnerReferences {
+		if ref.Kind != consumer.Kind || ref.Name != consumer.Name {
+			refs = append(refs, ref)
+		}
+	}
+	host.OwnerReferences = refs
+	host.Spec.ConsumerRef = nil
+	host.Spec.Image = nil
+	host.Spec.UserData = nil
+	delete(host.Annotations, ConsumerLeaseAnnotation)
+	clearHostSpecAnnotations(host)
+	delete(host.Labels, capi.ClusterLabelName)
+	if err := m.client.Update(ctx, host); err != nil {
+		return false, errors.Wrapf(err, "failed to reclaim BareMetalHost %s", host.Name)
+	}
+	return true, nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalHost consumer lease", func() {
+
+	// leasedHost returns a host provisioned for the "orphan" BareMetalMachine
+	// whose lease was last renewed age ago.
+	leasedHost := func(age time.Duration) *bmh.BareMetalHost {
+		host := newBareMetalHost("myhost", nil, bmh.StateProvisioned, nil,
+			true, true,
+		)
+		host.Spec.ConsumerRef = &corev1.ObjectReference{
+			Kind:      "BareMetalMachine",
+			Name:      "orphan",
+			Namespace: "myns",
+		}
+		host.Spec.Image = &bmh.Image{URL: "myimage", Checksum: "abcd"}
+		host.Spec.UserData = &corev1.SecretReference{Name: "orphan-user-data"}
+		host.Annotations = map[string]string{
+			ConsumerLeaseAnnotation:   time.Now().Add(-age).UTC().Format(time.RFC3339),
+			RootDeviceHintsAnnotation: `{"deviceName":"/dev/sda"}`,
+		}
+		return host
+	}
+
+	type testCaseReclaim struct {
+		LeaseAge          time.Duration
+		NoLease           bool
+		ConsumerExists    bool
+		ExpectedReclaimed bool
+	}
+
+	DescribeTable("Test reclaimOrphanedHost",
+		func(tc testCaseReclaim) {
+			host := leasedHost(tc.LeaseAge)
+			if tc.NoLease {
+				host.Annotations = nil
+			}
+			objects := []runtime.Object{host}
+			if tc.ConsumerExists {
+				objects = append(objects, newBareMetalMachine("orphan", nil, nil,
+					nil, nil,
+				))
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), objects...)
+			machineMgr, err := NewMachineManager(c, nil, nil,
+				newMachine("mymachine", "mybmmachine", nil),
+				newBareMetalMachine("mybmmachine", nil, nil, nil, nil),
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			reclaimed, err := machineMgr.reclaimOrphanedHost(context.TODO(), host)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reclaimed).To(Equal(tc.ExpectedReclaimed))
+
+			savedHost := bmh.BareMetalHost{}
+			Expect(c.Get(context.TODO(), client.ObjectKey{
+				Name: "myhost", Namespace: "myns",
+			}, &savedHost)).To(Succeed())
+			if tc.ExpectedReclaimed {
+				Expect(savedHost.Spec.ConsumerRef).To(BeNil())
+				Expect(savedHost.Spec.Image).To(BeNil())
+				Expect(savedHost.Spec.UserData).To(BeNil())
+				Expect(savedHost.Annotations).NotTo(HaveKey(ConsumerLeaseAnnotation))
+				Expect(savedHost.Annotations).NotTo(HaveKey(RootDeviceHintsAnnotation))
+			} else {
+				Expect(savedHost.Spec.ConsumerRef).NotTo(BeNil())
+				Expect(savedHost.Spec.Image).NotTo(BeNil())
+			}
+		},
+		Entry("Lease renewed", testCaseReclaim{
+			LeaseAge: time.Minute,
+		}),
+		Entry("Lease expired, consumer exists", testCaseReclaim{
+			LeaseAge:       time.Hour,
+			ConsumerExists: true,
+		}),
+		Entry("No lease", testCaseReclaim{
+			NoLease: true,
+		}),
+		Entry("Lease expired, consumer gone", testCaseReclaim{
+			LeaseAge:          time.Hour,
+			ExpectedReclaimed: true,
+		}),
+	)
+
+	It("Reclaims an orphaned host while choosing a host", func() {
+		host := leasedHost(time.Hour)
+		c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+		machineMgr, err := NewMachineManager(c, nil, nil,
+			newMachine("mymachine", "mybmmachine", nil),
+			newBareMetalMachine("mybmmachine", nil, nil, nil, nil),
+			klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		// The host deprovisions before it can be chosen
+		chosen, err := machineMgr.chooseHost(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(chosen).To(BeNil())
+
+		savedHost := bmh.BareMetalHost{}
+		Expect(c.Get(context.TODO(), client.ObjectKey{
+			Name: "myhost", Namespace: "myns",
+		}, &savedHost)).To(Succeed())
+		Expect(savedHost.Spec.ConsumerRef).To(BeNil())
+		Expect(savedHost.Spec.Image).To(BeNil())
+	})
+
+	type testCaseRenew struct {
+		LeaseAge        time.Duration
+		NoLease         bool
+		ExpectedRenewed bool
+	}
+
+	DescribeTable("Test renewConsumerLease",
+		func(tc testCaseRenew) {
+			host := leasedHost(tc.LeaseAge)
+			if tc.NoLease {
+				host.Annotations = nil
+			}
+			lease := host.Annotations[ConsumerLeaseAnnotation]
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil,
+				newBareMetalMachine("mybmmachine", nil, nil, nil, nil),
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			machineMgr.renewConsumerLease(host)
+
+			Expect(host.Annotations).To(HaveKey(ConsumerLeaseAnnotation))
+			if tc.ExpectedRenewed {
+				Expect(host.Annotations[ConsumerLeaseAnnotation]).NotTo(Equal(lease))
+			} else {
+				Expect(host.Annotations[ConsumerLeaseAnnotation]).To(Equal(lease))
+			}
+		},
+		Entry("No lease", testCaseRenew{
+			NoLease:         true,
+			ExpectedRenewed: true,
+		}),
+		Entry("Recent lease", testCaseRenew{
+			LeaseAge: ConsumerLeaseTTL / 4,
+		}),
+		Entry("Lease older than half the TTL", testCaseRenew{
+			LeaseAge:        ConsumerLeaseTTL * 3 / 4,
+			ExpectedRenewed: true,
+		}),
+	)
+
+	It("Renews the lease when setting the host spec", func() {
+		host := newBareMetalHost("myhost", nil, bmh.StateReady, nil, false,
+			false,
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+		machineMgr, err := NewMachineManager(c, nil, nil,
+			newMachine("mymachine", "mybmmachine", nil),
+			newBareMetalMachine("mybmmachine", nil, nil, nil, nil),
+			klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(machineMgr.setHostSpec(context.TODO(), host)).To(Succeed())
+
+		savedHost := bmh.BareMetalHost{}
+		Expect(c.Get(context.TODO(), client.ObjectKey{
+			Name: "myhost", Namespace: "myns",
+		}, &savedHost)).To(Succeed())
+		Expect(savedHost.Annotations).To(HaveKey(ConsumerLeaseAnnotation))
+		Expect(consumerLeaseExpired(&savedHost, time.Now())).To(BeFalse())
+		Expect(consumerLeaseExpired(&savedHost,
+			time.Now().Add(ConsumerLeaseTTL+time.Minute),
+		)).To(BeTrue())
+	})
+})
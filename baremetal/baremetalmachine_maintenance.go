@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// MaintenanceAnnotation is set on a BareMetalMachine to put its host in
+	// maintenance. The host is paused, so that the baremetal-operator does
+	// not reconcile it nor change its power state, until the annotation is
+	// removed.
+	MaintenanceAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/maintenance"
+
+	maintenanceReasonRequested = "MaintenanceRequested"
+)
+
+// Maintenance reacts to the MaintenanceAnnotation on the BareMetalMachine.
+// The host is paused and the Maintenance condition is set when the
+// annotation is added. The host is resumed and the condition removed once
+// the annotation is gone. Only a pause set by Maintenance is lifted.
+func (m *MachineManager) Maintenance(ctx context.Context) error {
+	_, requested := m.BareMetalMachine.Annotations[MaintenanceAnnotation]
+	inMaintenance := m.BareMetalMachine.Status.Conditions.IsTrue(capm3.MaintenanceCondition)
+	if requested == inMaintenance {
+		return nil
+	}
+
+	host, err := m.getHost(ctx)
+	if err != nil {
+		return err
+	}
+	if host == nil {
+		return fmt.Errorf("host not found for machine %s", m.BareMetalMachine.Name)
+	}
+
+	if requested {
+		if host.Annotations == nil {
+			host.Annotations = map[string]string{}
+		}
+		host.Annotations[bmh.PausedAnnotation] = ""
+		if err := m.client.Update(ctx, host); err != nil {
+			return err
+		}
+		m.BareMetalMachine.Status.Conditions.Set(capm3.Condition{
+			Type:    capm3.MaintenanceCondition,
+			Status:  corev1.ConditionTrue,
+			Reason:  maintenanceReasonRequested,
+			Message: "Host paused for maintenance",
+		})
+		m.Log.Info("Host entered maintenance", "host", host.Name)
+		return nil
+	}
+
+	if _, ok := host.Annotations[bmh.PausedAnnotation]; ok {
+		delete(host.Annotations, bmh.PausedAnnotation)
+		if err := m.client.Update(ctx, host); err != nil {
+			return err
+		}
+	}
+	m.BareMetalMachine.Status.Conditions.Remove(capm3.MaintenanceCondition)
+	m.Log.Info("Host left maintenance", "host", host.Name)
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalMachine maintenance", func() {
+
+	maintenanceStatus := capm3.BareMetalMachineStatus{
+		Conditions: capm3.Conditions{{
+			Type:   capm3.MaintenanceCondition,
+			Status: corev1.ConditionTrue,
+			Reason: maintenanceReasonRequested,
+		}},
+	}
+
+	type testCaseMaintenance struct {
+		MaintenanceRequested bool
+		HostPaused           bool
+		Status               capm3.BareMetalMachineStatus
+		ExpectHostPaused     bool
+		ExpectCondition      bool
+	}
+
+	DescribeTable("Test Maintenance",
+		func(tc testCaseMaintenance) {
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "myhost",
+					Namespace:   "myns",
+					Annotations: map[string]string{},
+				},
+			}
+			if tc.HostPaused {
+				host.Annotations[bmh.PausedAnnotation] = ""
+			}
+			objMeta := bmmObjectMetaWithValidAnnotations()
+			if tc.MaintenanceRequested {
+				objMeta.Annotations[MaintenanceAnnotation] = ""
+			}
+			bmMachine := newBareMetalMachine("mybmmachine", nil, nil,
+				tc.Status.DeepCopy(), objMeta,
+			)
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, bmMachine,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(machineMgr.Maintenance(context.TODO())).To(Succeed())
+
+			savedHost := bmh.BareMetalHost{}
+			err = c.Get(context.TODO(),
+				client.ObjectKey{Name: host.Name, Namespace: host.Namespace},
+				&savedHost,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			_, paused := savedHost.Annotations[bmh.PausedAnnotation]
+			Expect(paused).To(Equal(tc.ExpectHostPaused))
+			Expect(bmMachine.Status.Conditions.IsTrue(capm3.MaintenanceCondition)).To(
+				Equal(tc.ExpectCondition),
+			)
+		},
+		Entry("No maintenance requested", testCaseMaintenance{}),
+		Entry("Entering maintenance", testCaseMaintenance{
+			MaintenanceRequested: true,
+			ExpectHostPaused:     true,
+			ExpectCondition:      true,
+		}),
+		Entry("In maintenance", testCaseMaintenance{
+			MaintenanceRequested: true,
+			HostPaused:           true,
+			Status:               maintenanceStatus,
+			ExpectHostPaused:     true,
+			ExpectCondition:      true,
+		}),
+		Entry("Leaving maintenance", testCaseMaintenance{
+			HostPaused: true,
+			Status:     maintenanceStatus,
+		}),
+		Entry("Host paused by someone else is left alone", testCaseMaintenance{
+			HostPaused:       true,
+			ExpectHostPaused: true,
+		}),
+	)
+})
@@ -51,13 +51,42 @@ const (
 	ProviderName = "baremetal"
 	// HostAnnotation is the key for an annotation that should go on a Machine to
 	// reference what BareMetalHost it corresponds to.
-	HostAnnotation     = "metal3.io/BareMetalHost"
+	HostAnnotation     = capm3.HostAnnotation
 	requeueAfter       = time.Second * 30
 	bmRoleControlPlane = "control-plane"
 	bmRoleNode         = "node"
 	userDataFinalizer  = "baremetalmachine.infrastructure.cluster.x-k8s.io/userData"
+	// userDataOverriddenReason is the reason of the UserDataOverridden
+	// condition.
+	userDataOverriddenReason = "BootstrapDataTakesPrecedence"
+	// BootstrapFormatAnnotation is the key for an annotation set on a
+	// BareMetalHost to give the format of its user data.
+	BootstrapFormatAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/bootstrap-format"
+	// OSTypeAnnotation is the key for an annotation set on a BareMetalHost
+	// to give the operating system of its image.
+	OSTypeAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/os-type"
+	// BootModeAnnotation is the key for an annotation set on a BareMetalHost
+	// to give the mode it boots in.
+	BootModeAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/boot-mode"
+	// AutomatedCleaningModeAnnotation is the key for an annotation set on a
+	// BareMetalHost to give whether its disks are cleaned between provisions.
+	AutomatedCleaningModeAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/automated-cleaning-mode"
+	// DeployKernelAnnotation is the key for an annotation set on a
+	// BareMetalHost to give the kernel URL of a custom deploy image.
+	DeployKernelAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/deploy-kernel"
+	// DeployRamdiskAnnotation is the key for an annotation set on a
+	// BareMetalHost to give the ramdisk URL of a custom deploy image.
+	DeployRamdiskAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/deploy-ramdisk"
+	// DefaultFailureDomainLabel is the default BareMetalHost label holding
+	// the failure domain of the host, e.g. its rack.
+	DefaultFailureDomainLabel = "infrastructure.cluster.x-k8s.io/failure-domain"
 )
 
+// FailureDomainLabel is the BareMetalHost label holding the failure domain of
+// the host. It is used both to publish the failure domains of the cluster
+// and to choose the hosts of the machines placed in one.
+var FailureDomainLabel = DefaultFailureDomainLabel
+
 // MachineManagerInterface is an interface for a ClusterManager
 type MachineManagerInterface interface {
 	SetFinalizer()
@@ -66,11 +95,19 @@ type MachineManagerInterface interface {
 	IsBootstrapReady() bool
 	GetBaremetalHostID(context.Context) (*string, error)
 	Associate(context.Context) error
+	AssociateHost(context.Context, string) error
 	Delete(context.Context) error
 	Update(context.Context) error
 	HasAnnotation() bool
 	SetNodeProviderID(context.Context, string, string, ClientGetter) error
 	SetProviderID(string)
+	ProviderID(string) string
+	PropagateLabels([]string, bool)
+	Remediate(context.Context) error
+	Reprovision(context.Context) error
+	Maintenance(context.Context) error
+	RebootHost(context.Context, string) error
+	GetOwnerMachine(context.Context) (*capi.Machine, error)
 }
 
 // MachineManager is responsible for performing machine reconciliation
@@ -119,6 +156,40 @@ func (m *MachineManager) UnsetFinalizer() {
 	)
 }
 
+// PropagateLabels copies the labels of the owning Cluster whose key is in
+// keys onto the BareMetalMachine. If prefixMatch is true, the keys are
+// prefixes and all matching labels are copied. Labels already set on the
+// BareMetalMachine are not overwritten.
+func (m *MachineManager) PropagateLabels(keys []string, prefixMatch bool) {
+	if m.Cluster == nil || len(keys) == 0 {
+		return
+	}
+
+	for clusterKey, value := range m.Cluster.Labels {
+		if !labelKeyAllowed(clusterKey, keys, prefixMatch) {
+			continue
+		}
+		if _, ok := m.BareMetalMachine.Labels[clusterKey]; ok {
+			continue
+		}
+		if m.BareMetalMachine.Labels == nil {
+			m.BareMetalMachine.Labels = map[string]string{}
+		}
+		m.BareMetalMachine.Labels[clusterKey] = value
+	}
+}
+
+// labelKeyAllowed returns true if key is in keys, or starts with one of them
+// if prefixMatch is true.
+func labelKeyAllowed(key string, keys []string, prefixMatch bool) bool {
+	for _, allowed := range keys {
+		if key == allowed || (prefixMatch && strings.HasPrefix(key, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsProvisioned checks if the machine is provisioned
 func (m *MachineManager) IsProvisioned() bool {
 	if m.BareMetalMachine.Spec.ProviderID != nil && m.BareMetalMachine.Status.Ready {
@@ -162,13 +233,50 @@ func (m *MachineManager) GetBaremetalHostID(ctx context.Context) (*string, error
 		m.Log.Info("BaremetalHost not associated, requeuing")
 		return nil, &RequeueAfterError{RequeueAfter: requeueAfter}
 	}
+	m.BareMetalMachine.Status.Phase = capm3.BareMetalMachinePhaseProvisioning
 	if host.Status.Provisioning.State == bmh.StateProvisioned {
+		m.provisioningDone()
+		if err := m.setNetworkConfiguredCondition(ctx, host); err != nil {
+			return nil, err
+		}
+		if !m.networkReady() {
+			m.Log.Info("Waiting for the BaremetalHost NICs to be configured, requeuing")
+			return nil, &RequeueAfterError{RequeueAfter: requeueAfter}
+		}
+		m.BareMetalMachine.Status.Phase = capm3.BareMetalMachinePhaseProvisioned
 		return pointer.StringPtr(string(host.ObjectMeta.UID)), nil
 	}
+	if err := m.checkProvisioningFailure(ctx, host); err != nil {
+		return nil, err
+	}
+	m.checkProvisioningTimeout(host.Name)
 	m.Log.Info("Provisioning BaremetalHost, requeuing")
 	return nil, &RequeueAfterError{RequeueAfter: requeueAfter}
 }
 
+// GetOwnerMachine returns the Machine owning the BareMetalMachine and sets it
+// as the Machine of the manager. While the owner reference is not set, it
+// returns nil and sets the WaitingForOwner condition.
+func (m *MachineManager) GetOwnerMachine(ctx context.Context) (*capi.Machine, error) {
+	machine, err := util.GetOwnerMachine(ctx, m.client, m.BareMetalMachine.ObjectMeta)
+	if err != nil {
+		return nil, err
+	}
+	if machine == nil {
+		m.BareMetalMachine.Status.Conditions.Set(capm3.Condition{
+			Type:    capm3.WaitingForOwnerCondition,
+			Status:  corev1.ConditionTrue,
+			Reason:  "OwnerReferenceNotSet",
+			Message: "Waiting for the Machine controller to set the owner reference",
+		})
+		return nil, nil
+	}
+
+	m.BareMetalMachine.Status.Conditions.Remove(capm3.WaitingForOwnerCondition)
+	m.Machine = machine
+	return machine, nil
+}
+
 // Associate associates a machine and is invoked by the Machine Controller
 func (m *MachineManager) Associate(ctx context.Context) error {
 	m.Log.Info("Associating machine", "machine", m.Machine.Name)
@@ -217,6 +325,12 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 		m.Log.Info("Machine already associated with host", "host", host.Name)
 	}
 
+	// Another BareMetalMachine may have claimed the host since it was chosen
+	err = m.checkHostConsumer(ctx, host)
+	if err != nil {
+		return err
+	}
+
 	// A machine bootstrap not ready case is caught in the controller
 	// ReconcileNormal function
 	err = m.GetUserData(ctx, host)
@@ -240,6 +354,17 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 		m.Log.Info("Failed to set the Cluster label in the BMC Credentials for BareMetalHost", host.Name)
 	}
 
+	// Only trust the image once its checksum is verified, if requested
+	if host.Spec.Image == nil {
+		err = m.verifyImageSignature(ctx)
+		if err != nil {
+			m.setError("Failed to verify the image signature",
+				capierrors.CreateMachineError,
+			)
+			return err
+		}
+	}
+
 	err = m.setHostSpec(ctx, host)
 	if err != nil {
 		m.setError("Failed to associate the BaremetalHost to the BareMetalMachine",
@@ -256,6 +381,9 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 		return err
 	}
 
+	m.BareMetalMachine.Status.FailureDomain = hostFailureDomain(host)
+	m.BareMetalMachine.Status.HostName = pointer.StringPtr(host.Name)
+
 	m.Log.Info("Finished creating machine")
 	return nil
 }
@@ -272,10 +400,10 @@ func (m *MachineManager) GetUserData(ctx context.Context, host *bmh.BareMetalHos
 	// namespace, just pass the reference
 	if m.Machine.Spec.Bootstrap.DataSecretName != nil &&
 		host.Namespace == m.Machine.Namespace {
-		m.BareMetalMachine.Spec.UserData = &corev1.SecretReference{
+		m.setUserData(&corev1.SecretReference{
 			Name:      *m.Machine.Spec.Bootstrap.DataSecretName,
 			Namespace: m.Machine.Namespace,
-		}
+		})
 		return nil
 
 	} else if m.Machine.Spec.Bootstrap.DataSecretName != nil &&
@@ -354,14 +482,38 @@ func (m *MachineManager) GetUserData(ctx context.Context, host *bmh.BareMetalHos
 		m.Log.Info("Unable to create secret for bootstrap")
 		return err
 	}
-	m.BareMetalMachine.Spec.UserData = &corev1.SecretReference{
+	m.setUserData(&corev1.SecretReference{
 		Name:      m.BareMetalMachine.Name + "-user-data",
 		Namespace: host.Namespace,
-	}
+	})
 
 	return nil
 }
 
+// setUserData sets the UserData to the Secret holding the bootstrap data of
+// the Machine, which takes precedence over the UserData set on the
+// BareMetalMachine. When a different UserData was set, the
+// UserDataOverridden condition records which Secret was replaced.
+func (m *MachineManager) setUserData(userData *corev1.SecretReference) {
+	previous := m.BareMetalMachine.Spec.UserData
+	m.BareMetalMachine.Spec.UserData = userData
+	if previous == nil || *previous == *userData {
+		return
+	}
+
+	message := fmt.Sprintf(
+		"UserData %s/%s replaced by %s/%s: the bootstrap data of the Machine takes precedence",
+		previous.Namespace, previous.Name, userData.Namespace, userData.Name,
+	)
+	m.Log.Info(message)
+	m.BareMetalMachine.Status.Conditions.Set(capm3.Condition{
+		Type:    capm3.UserDataOverriddenCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  userDataOverriddenReason,
+		Message: message,
+	})
+}
+
 // Delete deletes a bare metal machine and is invoked by the Machine Controller
 func (m *MachineManager) Delete(ctx context.Context) error {
 	m.Log.Info("Deleting bare metal machine", "baremetalmachine", m.BareMetalMachine.Name)
@@ -380,6 +532,7 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 		if !consumerRefMatches(host.Spec.ConsumerRef, m.BareMetalMachine) {
 			m.Log.Info("host already associated with another bare metal machine",
 				"host", host.Name)
+			m.BareMetalMachine.Status.HostName = nil
 			return nil
 		}
 
@@ -389,7 +542,7 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 			m.Log.Info("BMC credential not found for BareMetalhost", host.Name)
 		} else if errBMC == nil && tmpBMCSecret != nil {
 			m.Log.Info("Deleting cluster label from BMC credential", host.Spec.BMC.CredentialsName)
-			if tmpBMCSecret.Labels != nil && tmpBMCSecret.Labels[capi.ClusterLabelName] == m.Machine.Spec.ClusterName {
+			if tmpBMCSecret.Labels != nil && tmpBMCSecret.Labels[capi.ClusterLabelName] == m.clusterName() {
 				delete(tmpBMCSecret.Labels, capi.ClusterLabelName)
 			}
 			errBMC = m.client.Update(ctx, tmpBMCSecret)
@@ -399,9 +552,12 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 			}
 		}
 
-		if host.Spec.Image != nil || host.Spec.Online || host.Spec.UserData != nil {
+		if host.Spec.Image != nil || (host.Spec.Online && m.managesPower()) ||
+			host.Spec.UserData != nil {
 			host.Spec.Image = nil
-			host.Spec.Online = false
+			if m.managesPower() {
+				host.Spec.Online = false
+			}
 			host.Spec.UserData = nil
 			err = m.client.Update(ctx, host)
 			if err != nil && !apierrors.IsNotFound(err) {
@@ -410,6 +566,7 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 				)
 				return err
 			}
+			m.startDeprovisioning()
 			m.Log.Info("Deprovisioning BaremetalHost, requeuing")
 			return &RequeueAfterError{}
 		}
@@ -426,13 +583,25 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 			// host is powered off
 			waiting = host.Status.PoweredOn
 		}
+		if waiting && m.forceDeleteRequested() {
+			m.Log.Info("Force delete requested, not waiting for the BaremetalHost to deprovision",
+				"host", host.Name,
+			)
+			waiting = false
+		}
 		if waiting {
+			m.checkDeprovisionTimeout(host.Name)
 			m.Log.Info("Deprovisioning BaremetalHost, requeuing")
 			return &RequeueAfterError{RequeueAfter: requeueAfter}
 		}
 
 		host.Spec.ConsumerRef = nil
-		if host.Labels != nil && host.Labels[capi.ClusterLabelName] == m.Machine.Spec.ClusterName {
+		delete(host.Annotations, ConsumerLeaseAnnotation)
+		clearHostSpecAnnotations(host)
+		if err := m.AuditBinding(host, BindingActionRelease); err != nil {
+			return err
+		}
+		if host.Labels != nil && host.Labels[capi.ClusterLabelName] == m.clusterName() {
 			delete(host.Labels, capi.ClusterLabelName)
 		}
 
@@ -445,17 +614,28 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 		}
 	}
 
+	// The host is released, or was never claimed
+	m.BareMetalMachine.Status.HostName = nil
+
+	secretNamespace := m.BareMetalMachine.Namespace
+	if host != nil {
+		secretNamespace = host.Namespace
+	}
+
 	// Delete created secret, if data was set without DataSecretName or if
-	// BareMetalHost and Machine are in different namespaces.
-	if (m.Machine.Spec.Bootstrap.DataSecretName == nil &&
-		m.Machine.Spec.Bootstrap.Data != nil) ||
+	// BareMetalHost and Machine are in different namespaces. If the Machine
+	// is already gone, we can not tell how the data was provided, so the
+	// secret is only deleted if it carries our finalizer.
+	if m.Machine == nil ||
+		(m.Machine.Spec.Bootstrap.DataSecretName == nil &&
+			m.Machine.Spec.Bootstrap.Data != nil) ||
 		(m.Machine.Spec.Bootstrap.DataSecretName != nil &&
-			m.Machine.Namespace != host.Namespace) {
+			m.Machine.Namespace != secretNamespace) {
 		m.Log.Info("Deleting User data secret for machine")
 		tmpBootstrapSecret := corev1.Secret{}
 		key := client.ObjectKey{
 			Name:      m.BareMetalMachine.Name + "-user-data",
-			Namespace: host.Namespace,
+			Namespace: secretNamespace,
 		}
 		err = m.client.Get(ctx, key, &tmpBootstrapSecret)
 		if err != nil && !apierrors.IsNotFound(err) {
@@ -463,7 +643,8 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 				capierrors.DeleteMachineError,
 			)
 			return err
-		} else if err == nil {
+		} else if err == nil && (m.Machine != nil ||
+			util.Contains(tmpBootstrapSecret.Finalizers, userDataFinalizer)) {
 			//unset the finalizers (remove all since we do not expect anything else
 			// to control that object)
 			tmpBootstrapSecret.Finalizers = []string{}
@@ -488,6 +669,16 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 	return nil
 }
 
+// clusterName returns the name of the cluster the machine belongs to. It is
+// read from the Machine, or from the cluster label of the BareMetalMachine if
+// the Machine no longer exists.
+func (m *MachineManager) clusterName() string {
+	if m.Machine != nil {
+		return m.Machine.Spec.ClusterName
+	}
+	return m.BareMetalMachine.Labels[capi.ClusterLabelName]
+}
+
 // Update updates a machine and is invoked by the Machine Controller
 func (m *MachineManager) Update(ctx context.Context) error {
 	m.Log.Info("Updating machine")
@@ -619,21 +810,38 @@ func (m *MachineManager) chooseHost(ctx context.Context) (*bmh.BareMetalHost, er
 		}
 		reqs = append(reqs, *r)
 	}
+	if failureDomain := m.failureDomain(); failureDomain != "" {
+		m.Log.Info("Adding requirement to match failure domain",
+			"failure domain", failureDomain)
+		r, err := labels.NewRequirement(FailureDomainLabel, selection.Equals,
+			[]string{failureDomain},
+		)
+		if err != nil {
+			m.Log.Error(err, "Failed to create FailureDomain requirement, not choosing host")
+			return nil, err
+		}
+		reqs = append(reqs, *r)
+	}
 	labelSelector = labelSelector.Add(reqs...)
 
 	availableHosts := []*bmh.BareMetalHost{}
 
 	for i, host := range hosts.Items {
 		if host.Available() {
-			if labelSelector.Matches(labels.Set(host.ObjectMeta.Labels)) {
+			if !labelSelector.Matches(labels.Set(host.ObjectMeta.Labels)) {
+				m.Log.Info("Host did not match hostSelector for BareMetalMachine", "host", host.Name)
+			} else if !hostMeetsMinimums(&hosts.Items[i], &m.BareMetalMachine.Spec) {
+				m.Log.Info("Host is below the minimum capacity for BareMetalMachine", "host", host.Name)
+			} else {
 				m.Log.Info("Host matched hostSelector for BareMetalMachine", "host", host.Name)
 				availableHosts = append(availableHosts, &hosts.Items[i])
-			} else {
-				m.Log.Info("Host did not match hostSelector for BareMetalMachine", "host", host.Name)
 			}
 		} else if host.Spec.ConsumerRef != nil && consumerRefMatches(host.Spec.ConsumerRef, m.BareMetalMachine) {
 			m.Log.Info("Found host with existing ConsumerRef", "host", host.Name)
 			return &hosts.Items[i], nil
+		} else if _, err := m.reclaimOrphanedHost(ctx, &hosts.Items[i]); err != nil {
+			// A reclaimed host deprovisions before it can be chosen
+			return nil, err
 		}
 	}
 	m.Log.Info(fmt.Sprintf("%d hosts available while choosing host for bare metal machine", len(availableHosts)))
@@ -648,6 +856,33 @@ func (m *MachineManager) chooseHost(ctx context.Context) (*bmh.BareMetalHost, er
 	return chosenHost, nil
 }
 
+// failureDomain returns the failure domain the host must be in, the one of
+// the BareMetalMachine or else the one Cluster API set on the Machine, or an
+// empty string if there is none.
+func (m *MachineManager) failureDomain() string {
+	if m.BareMetalMachine.Spec.FailureDomain != nil {
+		return *m.BareMetalMachine.Spec.FailureDomain
+	}
+	if m.Machine != nil && m.Machine.Spec.FailureDomain != nil {
+		return *m.Machine.Spec.FailureDomain
+	}
+	return ""
+}
+
+// hostMeetsMinimums returns whether the inventory of the host meets the
+// MinCPUs and MinMemoryMiB of the spec. A host that has not reported its
+// inventory only meets a spec without minimums.
+func hostMeetsMinimums(host *bmh.BareMetalHost, spec *capm3.BareMetalMachineSpec) bool {
+	if spec.MinCPUs == 0 && spec.MinMemoryMiB == 0 {
+		return true
+	}
+	details := hostHardwareDetails(host)
+	if details == nil {
+		return false
+	}
+	return details.CPUCount >= spec.MinCPUs && details.RAMMebibytes >= spec.MinMemoryMiB
+}
+
 // consumerRefMatches returns a boolean based on whether the consumer
 // reference and bare metal machine metadata match
 func consumerRefMatches(consumer *corev1.ObjectReference, bmmachine *capm3.BareMetalMachine) bool {
@@ -666,6 +901,46 @@ func consumerRefMatches(consumer *corev1.ObjectReference, bmmachine *capm3.BareM
 	return true
 }
 
+// HostConflictError is returned when a BareMetalHost is claimed by another
+// consumer than the BareMetalMachine. The claim can be retried with another
+// host after RequeueAfter.
+type HostConflictError struct {
+	Host     string
+	Consumer string
+}
+
+// Error implements the error interface
+func (e *HostConflictError) Error() string {
+	return fmt.Sprintf("host %s is already claimed by %s", e.Host, e.Consumer)
+}
+
+// GetRequeueAfter gets the duration to wait until the claim is retried.
+func (e *HostConflictError) GetRequeueAfter() time.Duration {
+	return requeueAfter
+}
+
+// checkHostConsumer refreshes the host and returns a HostConflictError if
+// its ConsumerRef is set and does not reference the BareMetalMachine. It is
+// called before writing to a host, so that two BareMetalMachines choosing the
+// same host cannot both claim it.
+func (m *MachineManager) checkHostConsumer(ctx context.Context, host *bmh.BareMetalHost) error {
+	key := client.ObjectKey{Name: host.Name, Namespace: host.Namespace}
+	if err := m.client.Get(ctx, key, host); err != nil {
+		return errors.Wrapf(err, "failed to get BareMetalHost %s", host.Name)
+	}
+	consumer := host.Spec.ConsumerRef
+	if consumer == nil || consumerRefMatches(consumer, m.BareMetalMachine) {
+		return nil
+	}
+	m.Log.Info("Host claimed by another consumer", "host", host.Name,
+		"consumer", consumer.Namespace+"/"+consumer.Name,
+	)
+	return &HostConflictError{
+		Host:     host.Namespace + "/" + host.Name,
+		Consumer: consumer.Kind + " " + consumer.Namespace + "/" + consumer.Name,
+	}
+}
+
 // getBMCSecret will return the BMCSecret associated with BMH
 func (m *MachineManager) getBMCSecret(ctx context.Context, host *bmh.BareMetalHost) (*corev1.Secret, error) {
 
@@ -721,16 +996,53 @@ func (m *MachineManager) setHostSpec(ctx context.Context, host *bmh.BareMetalHos
 	// A host with an existing image is already provisioned and
 	// upgrades are not supported at this time. To re-provision a
 	// host, we must fully deprovision it and then provision it again.
-	// Not provisioning while we do not have the UserData
-	if host.Spec.Image == nil && m.BareMetalMachine.Spec.UserData != nil {
+	// Not provisioning while we do not have the UserData, nor while the host
+	// deprovisions for remediation or reprovisioning, or waits to retry a
+	// failed provisioning
+	if host.Spec.Image == nil && m.BareMetalMachine.Spec.UserData != nil &&
+		!m.waitingForDeprovisioning(host) && !m.waitingForProvisioningRetry(host) {
+		m.provisioningRetried()
 		host.Spec.Image = &bmh.Image{
-			URL:      m.BareMetalMachine.Spec.Image.URL,
+			URL:      m.imageURL(host),
 			Checksum: m.BareMetalMachine.Spec.Image.Checksum,
 		}
 		host.Spec.UserData = m.BareMetalMachine.Spec.UserData
 		if host.Spec.UserData != nil && host.Spec.UserData.Namespace == "" {
 			host.Spec.UserData.Namespace = m.Machine.Namespace
 		}
+		if host.Annotations == nil {
+			host.Annotations = map[string]string{}
+		}
+		host.Annotations[BootstrapFormatAnnotation] = string(m.bootstrapFormat())
+		host.Annotations[OSTypeAnnotation] = string(m.osType())
+		host.Annotations[BootModeAnnotation] = string(m.bootMode())
+		host.Annotations[AutomatedCleaningModeAnnotation] = string(m.automatedCleaningMode())
+		m.setSecureBootCondition()
+		deployRamdisk := ""
+		if m.BareMetalMachine.Spec.DeployKernelURL != "" {
+			deployRamdisk = m.BareMetalMachine.Spec.DeployRamdiskURL
+		}
+		setHostAnnotation(host, DeployKernelAnnotation, m.BareMetalMachine.Spec.DeployKernelURL)
+		setHostAnnotation(host, DeployRamdiskAnnotation, deployRamdisk)
+		setHostAnnotation(host, NetworkDataAnnotation, m.networkDataKey())
+		setHostAnnotation(host, MetaDataAnnotation, m.metaDataKey())
+		firmware, err := m.firmwareSettings()
+		if err != nil {
+			return err
+		}
+		setHostAnnotation(host, FirmwareAnnotation, firmware)
+		hints, err := m.rootDeviceHints()
+		if err != nil {
+			return err
+		}
+		setHostAnnotation(host, RootDeviceHintsAnnotation, hints)
+	}
+
+	if host.Spec.ConsumerRef == nil ||
+		!consumerRefMatches(host.Spec.ConsumerRef, m.BareMetalMachine) {
+		if err := m.AuditBinding(host, BindingActionClaim); err != nil {
+			return err
+		}
 	}
 
 	host.Spec.ConsumerRef = &corev1.ObjectReference{
@@ -739,13 +1051,81 @@ func (m *MachineManager) setHostSpec(ctx context.Context, host *bmh.BareMetalHos
 		Namespace:  m.BareMetalMachine.Namespace,
 		APIVersion: m.BareMetalMachine.APIVersion,
 	}
+	m.renewConsumerLease(host)
 
-	host.Spec.Online = true
+	if m.managesPower() {
+		host.Spec.Online = true
+	}
 	// Set OwnerReferences
 	host.OwnerReferences = m.SetOwnerRef(host.OwnerReferences, true)
 	return m.client.Update(ctx, host)
 }
 
+// hostSpecAnnotations are the annotations set by setHostSpec on a
+// BareMetalHost for its BareMetalMachine. They are removed when the host is
+// released, so that the next BareMetalMachine does not inherit them.
+var hostSpecAnnotations = []string{
+	BootstrapFormatAnnotation,
+	OSTypeAnnotation,
+	BootModeAnnotation,
+	AutomatedCleaningModeAnnotation,
+	DeployKernelAnnotation,
+	DeployRamdiskAnnotation,
+	NetworkDataAnnotation,
+	MetaDataAnnotation,
+	FirmwareAnnotation,
+	RootDeviceHintsAnnotation,
+}
+
+// setHostAnnotation sets the annotation of the host to the value, or removes
+// it if the value is empty, e.g. when the previous consumer of the host set
+// it.
+func setHostAnnotation(host *bmh.BareMetalHost, key, value string) {
+	if value == "" {
+		delete(host.Annotations, key)
+		return
+	}
+	host.Annotations[key] = value
+}
+
+// clearHostSpecAnnotations removes the hostSpecAnnotations of the host.
+func clearHostSpecAnnotations(host *bmh.BareMetalHost) {
+	for _, key := range hostSpecAnnotations {
+		delete(host.Annotations, key)
+	}
+}
+
+// managesPower returns true unless the power state of the host is left to
+// the operators by the PowerManagementPolicy.
+func (m *MachineManager) managesPower() bool {
+	return m.BareMetalMachine.Spec.PowerManagementPolicy != capm3.PowerManagementManual
+}
+
+// bootstrapFormat returns the format of the user data, cloud-init if unset.
+func (m *MachineManager) bootstrapFormat() capm3.BootstrapFormat {
+	if m.BareMetalMachine.Spec.BootstrapFormat == "" {
+		return capm3.BootstrapFormatCloudInit
+	}
+	return m.BareMetalMachine.Spec.BootstrapFormat
+}
+
+// osType returns the operating system of the image, linux if unset.
+func (m *MachineManager) osType() capm3.OSType {
+	if m.BareMetalMachine.Spec.Image.OSType == "" {
+		return capm3.OSTypeLinux
+	}
+	return m.BareMetalMachine.Spec.Image.OSType
+}
+
+// automatedCleaningMode returns the cleaning mode of the disks of the host,
+// metadata if unset.
+func (m *MachineManager) automatedCleaningMode() capm3.AutomatedCleaningMode {
+	if m.BareMetalMachine.Spec.AutomatedCleaningMode == "" {
+		return capm3.CleaningModeMetadata
+	}
+	return m.BareMetalMachine.Spec.AutomatedCleaningMode
+}
+
 // ensureAnnotation makes sure the machine has an annotation that references the
 // host and uses the API to update the machine if necessary.
 func (m *MachineManager) ensureAnnotation(ctx context.Context, host *bmh.BareMetalHost) error {
@@ -803,8 +1183,21 @@ func (m *MachineManager) clearError() {
 func (m *MachineManager) updateMachineStatus(ctx context.Context, host *bmh.BareMetalHost) error {
 	addrs := m.nodeAddresses(host)
 
+	poweredOn := hostPoweredOn(host)
+	failureDomain := hostFailureDomain(host)
+	hardwareDetails := hostHardwareDetails(host)
+	if err := m.setNetworkConfiguredCondition(ctx, host); err != nil {
+		return err
+	}
+	m.updateDownloadProgress(host)
+	m.setHostErrorCondition(host)
+	m.setHostStateCondition(host)
+
 	machineCopy := m.BareMetalMachine.DeepCopy()
 	machineCopy.Status.Addresses = addrs
+	machineCopy.Status.PoweredOn = poweredOn
+	machineCopy.Status.FailureDomain = failureDomain
+	machineCopy.Status.HardwareDetails = hardwareDetails
 
 	if equality.Semantic.DeepEqual(m.Machine.Status, machineCopy.Status) {
 		// Status did not change
@@ -814,10 +1207,49 @@ func (m *MachineManager) updateMachineStatus(ctx context.Context, host *bmh.Bare
 	now := metav1.Now()
 	m.BareMetalMachine.Status.LastUpdated = &now
 	m.BareMetalMachine.Status.Addresses = addrs
+	m.BareMetalMachine.Status.PoweredOn = poweredOn
+	m.BareMetalMachine.Status.FailureDomain = failureDomain
+	m.BareMetalMachine.Status.HardwareDetails = hardwareDetails
 
 	return nil
 }
 
+// hostHardwareDetails returns the summary of the inventory of the host, or nil
+// if the host has not reported it yet.
+func hostHardwareDetails(host *bmh.BareMetalHost) *capm3.HardwareDetails {
+	if host == nil || host.Status.HardwareDetails == nil {
+		return nil
+	}
+	return &capm3.HardwareDetails{
+		CPUCount:     host.Status.HardwareDetails.CPU.Count,
+		RAMMebibytes: host.Status.HardwareDetails.RAMMebibytes,
+		NICCount:     len(host.Status.HardwareDetails.NIC),
+	}
+}
+
+// hostFailureDomain returns the failure domain of the host, or nil if the host
+// has none.
+func hostFailureDomain(host *bmh.BareMetalHost) *string {
+	if host == nil {
+		return nil
+	}
+	failureDomain, ok := host.Labels[FailureDomainLabel]
+	if !ok {
+		return nil
+	}
+	return &failureDomain
+}
+
+// hostPoweredOn returns the power state of the host, or nil if the host has
+// not reported its status yet.
+func hostPoweredOn(host *bmh.BareMetalHost) *bool {
+	if host == nil || host.Status.LastUpdated == nil {
+		return nil
+	}
+	poweredOn := host.Status.PoweredOn
+	return &poweredOn
+}
+
 // NodeAddresses returns a slice of corev1.NodeAddress objects for a
 // given Baremetal machine.
 func (m *MachineManager) nodeAddresses(host *bmh.BareMetalHost) []capi.MachineAddress {
@@ -169,6 +169,14 @@ func bmmObjectMetaWithValidAnnotations() *metav1.ObjectMeta {
 	}
 }
 
+func bmmObjectMetaWithClusterLabel() *metav1.ObjectMeta {
+	objMeta := bmmObjectMetaWithValidAnnotations()
+	objMeta.Labels = map[string]string{
+		capi.ClusterLabelName: clusterName,
+	}
+	return objMeta
+}
+
 func bmmObjectMetaWithInvalidAnnotations() *metav1.ObjectMeta {
 	return &metav1.ObjectMeta{
 		Name:            "foobarbmmachine",
@@ -437,6 +445,44 @@ var _ = Describe("BareMetalMachine manager", func() {
 				Labels:    map[string]string{"key1": "value1"},
 			},
 		}
+		hostInRack1 := bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "hostInRack1",
+				Namespace: "myns",
+				Labels:    map[string]string{FailureDomainLabel: "rack-1"},
+			},
+		}
+		hostInRack2 := bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "hostInRack2",
+				Namespace: "myns",
+				Labels:    map[string]string{FailureDomainLabel: "rack-2"},
+			},
+		}
+		smallHost := bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "smallHost",
+				Namespace: "myns",
+			},
+			Status: bmh.BareMetalHostStatus{
+				HardwareDetails: &bmh.HardwareDetails{
+					CPU:          bmh.CPU{Count: 4},
+					RAMMebibytes: 8192,
+				},
+			},
+		}
+		bigHost := bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bigHost",
+				Namespace: "myns",
+			},
+			Status: bmh.BareMetalHostStatus{
+				HardwareDetails: &bmh.HardwareDetails{
+					CPU:          bmh.CPU{Count: 32},
+					RAMMebibytes: 131072,
+				},
+			},
+		}
 
 		bmmconfig, infrastructureRef := newConfig("", map[string]string{},
 			[]capm3.HostSelectorRequirement{},
@@ -466,6 +512,22 @@ var _ = Describe("BareMetalMachine manager", func() {
 			},
 		)
 
+		bmmconfigRack2, infrastructureRefRack2 := newConfig("",
+			map[string]string{}, []capm3.HostSelectorRequirement{},
+		)
+		bmmconfigRack2.Spec.FailureDomain = pointer.StringPtr("rack-2")
+		machineInRack2 := newMachine("machine1", "", infrastructureRef)
+		machineInRack2.Spec.FailureDomain = pointer.StringPtr("rack-2")
+
+		bmmconfigMinCPUs, infrastructureRefMinCPUs := newConfig("",
+			map[string]string{}, []capm3.HostSelectorRequirement{},
+		)
+		bmmconfigMinCPUs.Spec.MinCPUs = 16
+		bmmconfigMinMemory, infrastructureRefMinMemory := newConfig("",
+			map[string]string{}, []capm3.HostSelectorRequirement{},
+		)
+		bmmconfigMinMemory.Spec.MinMemoryMiB = 65536
+
 		type testCaseChooseHost struct {
 			Machine          *capi.Machine
 			Hosts            []runtime.Object
@@ -554,6 +616,50 @@ var _ = Describe("BareMetalMachine manager", func() {
 					ExpectedHostName: "",
 				},
 			),
+			Entry("Pick the host in the failure domain", testCaseChooseHost{
+				Machine:          newMachine("machine1", "", infrastructureRefRack2),
+				Hosts:            []runtime.Object{&hostInRack1, &hostInRack2, &host2},
+				BMMachine:        bmmconfigRack2,
+				ExpectedHostName: hostInRack2.Name,
+			}),
+			Entry("Pick the host in the failure domain of the Machine", testCaseChooseHost{
+				Machine:          machineInRack2,
+				Hosts:            []runtime.Object{&hostInRack1, &hostInRack2, &host2},
+				BMMachine:        bmmconfig,
+				ExpectedHostName: hostInRack2.Name,
+			}),
+			Entry("No host in the failure domain", testCaseChooseHost{
+				Machine:          newMachine("machine1", "", infrastructureRefRack2),
+				Hosts:            []runtime.Object{&hostInRack1, &host2},
+				BMMachine:        bmmconfigRack2,
+				ExpectedHostName: "",
+			}),
+			Entry("Skip the host with too few CPUs", testCaseChooseHost{
+				Machine:          newMachine("machine1", "", infrastructureRefMinCPUs),
+				Hosts:            []runtime.Object{&smallHost, &bigHost},
+				BMMachine:        bmmconfigMinCPUs,
+				ExpectedHostName: bigHost.Name,
+			}),
+			Entry("Skip the host with too little memory", testCaseChooseHost{
+				Machine:          newMachine("machine1", "", infrastructureRefMinMemory),
+				Hosts:            []runtime.Object{&smallHost, &bigHost},
+				BMMachine:        bmmconfigMinMemory,
+				ExpectedHostName: bigHost.Name,
+			}),
+			Entry("Skip the host without inventory when minimums are set",
+				testCaseChooseHost{
+					Machine:          newMachine("machine1", "", infrastructureRefMinCPUs),
+					Hosts:            []runtime.Object{&host2, &bigHost},
+					BMMachine:        bmmconfigMinCPUs,
+					ExpectedHostName: bigHost.Name,
+				},
+			),
+			Entry("No host meets the minimums", testCaseChooseHost{
+				Machine:          newMachine("machine1", "", infrastructureRefMinCPUs),
+				Hosts:            []runtime.Object{&smallHost, &host2},
+				BMMachine:        bmmconfigMinCPUs,
+				ExpectedHostName: "",
+			}),
 			Entry("No host chosen, invalid match expression", testCaseChooseHost{
 				Machine:          newMachine("machine1", "", infrastructureRef5),
 				Hosts:            []runtime.Object{&host2, &hostWithLabel, &host1},
@@ -569,6 +675,35 @@ var _ = Describe("BareMetalMachine manager", func() {
 		Host                      *bmh.BareMetalHost
 		ExpectedImage             *bmh.Image
 		ExpectUserData            bool
+		BootstrapFormat           capm3.BootstrapFormat
+		ExpectedBootstrapFormat   string
+		OSType                    capm3.OSType
+		ExpectedOSType            string
+		PowerManagementPolicy     capm3.PowerManagementPolicy
+		ExpectOffline             bool
+		Firmware                  *capm3.Firmware
+		ExpectedFirmware          string
+		RootDeviceHints           *capm3.RootDeviceHints
+		ExpectedRootDeviceHints   string
+		DeployKernelURL           string
+		DeployRamdiskURL          string
+		MetaData                  *corev1.SecretReference
+		ExpectedMetaData          string
+	}
+
+	// hostWithStaleAnnotations is a host released by a BareMetalMachine
+	// that set all the optional annotations
+	hostWithStaleAnnotations := func() *bmh.BareMetalHost {
+		host := newBareMetalHost("host2", nil, bmh.StateNone, nil, false, false)
+		host.Annotations = map[string]string{
+			DeployKernelAnnotation:    "http://example.com/old-kernel",
+			DeployRamdiskAnnotation:   "http://example.com/old-ramdisk",
+			NetworkDataAnnotation:     "myns/old-network-data",
+			MetaDataAnnotation:        "myns/old-metadata",
+			FirmwareAnnotation:        `{"sriovEnabled":true}`,
+			RootDeviceHintsAnnotation: `{"deviceName":"/dev/sdb"}`,
+		}
+		return host
 	}
 
 	DescribeTable("Test SetHostSpec",
@@ -578,6 +713,14 @@ var _ = Describe("BareMetalMachine manager", func() {
 			bmmconfig, infrastructureRef := newConfig(tc.UserDataNamespace,
 				map[string]string{}, []capm3.HostSelectorRequirement{},
 			)
+			bmmconfig.Spec.BootstrapFormat = tc.BootstrapFormat
+			bmmconfig.Spec.Image.OSType = tc.OSType
+			bmmconfig.Spec.PowerManagementPolicy = tc.PowerManagementPolicy
+			bmmconfig.Spec.Firmware = tc.Firmware
+			bmmconfig.Spec.RootDeviceHints = tc.RootDeviceHints
+			bmmconfig.Spec.DeployKernelURL = tc.DeployKernelURL
+			bmmconfig.Spec.DeployRamdiskURL = tc.DeployRamdiskURL
+			bmmconfig.Spec.MetaData = tc.MetaData
 			machine := newMachine("machine1", "", infrastructureRef)
 
 			machineMgr, err := NewMachineManager(c, nil, nil, machine, bmmconfig,
@@ -605,7 +748,7 @@ var _ = Describe("BareMetalMachine manager", func() {
 			Expect(savedHost.Spec.ConsumerRef.Namespace).
 				To(Equal(bmmconfig.Namespace))
 			Expect(savedHost.Spec.ConsumerRef.Kind).To(Equal("BareMetalMachine"))
-			Expect(savedHost.Spec.Online).To(BeTrue())
+			Expect(savedHost.Spec.Online).To(Equal(!tc.ExpectOffline))
 			if tc.ExpectedImage == nil {
 				Expect(savedHost.Spec.Image).To(BeNil())
 			} else {
@@ -619,6 +762,21 @@ var _ = Describe("BareMetalMachine manager", func() {
 			} else {
 				Expect(savedHost.Spec.UserData).To(BeNil())
 			}
+			Expect(savedHost.Annotations[BootstrapFormatAnnotation]).
+				To(Equal(tc.ExpectedBootstrapFormat))
+			Expect(savedHost.Annotations[OSTypeAnnotation]).
+				To(Equal(tc.ExpectedOSType))
+			Expect(savedHost.Annotations[FirmwareAnnotation]).
+				To(Equal(tc.ExpectedFirmware))
+			Expect(savedHost.Annotations[RootDeviceHintsAnnotation]).
+				To(Equal(tc.ExpectedRootDeviceHints))
+			Expect(savedHost.Annotations[DeployKernelAnnotation]).
+				To(Equal(tc.DeployKernelURL))
+			Expect(savedHost.Annotations[DeployRamdiskAnnotation]).
+				To(Equal(tc.DeployRamdiskURL))
+			Expect(savedHost.Annotations[MetaDataAnnotation]).
+				To(Equal(tc.ExpectedMetaData))
+			Expect(savedHost.Annotations).NotTo(HaveKey(NetworkDataAnnotation))
 			_, err = machineMgr.FindOwnerRef(savedHost.OwnerReferences)
 			Expect(err).NotTo(HaveOccurred())
 		},
@@ -628,8 +786,10 @@ var _ = Describe("BareMetalMachine manager", func() {
 			Host: newBareMetalHost("host2", nil, bmh.StateNone,
 				nil, false, false,
 			),
-			ExpectedImage:  expectedImg(),
-			ExpectUserData: true,
+			ExpectedImage:           expectedImg(),
+			ExpectUserData:          true,
+			ExpectedBootstrapFormat: "cloud-init",
+			ExpectedOSType:          "linux",
 		}),
 		Entry("User data has no namespace", testCaseSetHostSpec{
 			UserDataNamespace:         "",
@@ -637,8 +797,10 @@ var _ = Describe("BareMetalMachine manager", func() {
 			Host: newBareMetalHost("host2", nil, bmh.StateNone,
 				nil, false, false,
 			),
-			ExpectedImage:  expectedImg(),
-			ExpectUserData: true,
+			ExpectedImage:           expectedImg(),
+			ExpectUserData:          true,
+			ExpectedBootstrapFormat: "cloud-init",
+			ExpectedOSType:          "linux",
 		}),
 		Entry("Externally provisioned, same machine", testCaseSetHostSpec{
 			UserDataNamespace:         "",
@@ -646,8 +808,129 @@ var _ = Describe("BareMetalMachine manager", func() {
 			Host: newBareMetalHost("host2", nil, bmh.StateNone,
 				nil, false, false,
 			),
-			ExpectedImage:  expectedImg(),
-			ExpectUserData: true,
+			ExpectedImage:           expectedImg(),
+			ExpectUserData:          true,
+			ExpectedBootstrapFormat: "cloud-init",
+			ExpectedOSType:          "linux",
+		}),
+		Entry("Ignition bootstrap format", testCaseSetHostSpec{
+			UserDataNamespace:         "",
+			ExpectedUserDataNamespace: "myns",
+			Host: newBareMetalHost("host2", nil, bmh.StateNone,
+				nil, false, false,
+			),
+			ExpectedImage:           expectedImg(),
+			ExpectUserData:          true,
+			BootstrapFormat:         capm3.BootstrapFormatIgnition,
+			ExpectedBootstrapFormat: "ignition",
+			ExpectedOSType:          "linux",
+		}),
+		Entry("Windows image", testCaseSetHostSpec{
+			UserDataNamespace:         "",
+			ExpectedUserDataNamespace: "myns",
+			Host: newBareMetalHost("host2", nil, bmh.StateNone,
+				nil, false, false,
+			),
+			ExpectedImage:           expectedImg(),
+			ExpectUserData:          true,
+			ExpectedBootstrapFormat: "cloud-init",
+			OSType:                  capm3.OSTypeWindows,
+			ExpectedOSType:          "windows",
+		}),
+		Entry("Manual power management", testCaseSetHostSpec{
+			UserDataNamespace:         "",
+			ExpectedUserDataNamespace: "myns",
+			Host: newBareMetalHost("host2", nil, bmh.StateNone,
+				nil, false, false,
+			),
+			ExpectedImage:           expectedImg(),
+			ExpectUserData:          true,
+			ExpectedBootstrapFormat: "cloud-init",
+			ExpectedOSType:          "linux",
+			PowerManagementPolicy:   capm3.PowerManagementManual,
+			ExpectOffline:           true,
+		}),
+		Entry("Firmware settings", testCaseSetHostSpec{
+			UserDataNamespace:         "",
+			ExpectedUserDataNamespace: "myns",
+			Host: newBareMetalHost("host2", nil, bmh.StateNone,
+				nil, false, false,
+			),
+			ExpectedImage:           expectedImg(),
+			ExpectUserData:          true,
+			ExpectedBootstrapFormat: "cloud-init",
+			ExpectedOSType:          "linux",
+			Firmware: &capm3.Firmware{
+				VirtualizationEnabled: pointer.BoolPtr(true),
+				SriovEnabled:          pointer.BoolPtr(false),
+			},
+			ExpectedFirmware: `{"virtualizationEnabled":true,"sriovEnabled":false}`,
+		}),
+		Entry("Root device hints", testCaseSetHostSpec{
+			UserDataNamespace:         "",
+			ExpectedUserDataNamespace: "myns",
+			Host: newBareMetalHost("host2", nil, bmh.StateNone,
+				nil, false, false,
+			),
+			ExpectedImage:           expectedImg(),
+			ExpectUserData:          true,
+			ExpectedBootstrapFormat: "cloud-init",
+			ExpectedOSType:          "linux",
+			RootDeviceHints: &capm3.RootDeviceHints{
+				DeviceName: "/dev/sda",
+				Rotational: pointer.BoolPtr(false),
+			},
+			ExpectedRootDeviceHints: `{"deviceName":"/dev/sda","rotational":false}`,
+		}),
+		Entry("Annotations of the previous consumer", testCaseSetHostSpec{
+			UserDataNamespace:         "",
+			ExpectedUserDataNamespace: "myns",
+			Host:                      hostWithStaleAnnotations(),
+			ExpectedImage:             expectedImg(),
+			ExpectUserData:            true,
+			ExpectedBootstrapFormat:   "cloud-init",
+			ExpectedOSType:            "linux",
+		}),
+		Entry("Custom deploy image", testCaseSetHostSpec{
+			UserDataNamespace:         "",
+			ExpectedUserDataNamespace: "myns",
+			Host: newBareMetalHost("host2", nil, bmh.StateNone,
+				nil, false, false,
+			),
+			ExpectedImage:           expectedImg(),
+			ExpectUserData:          true,
+			ExpectedBootstrapFormat: "cloud-init",
+			ExpectedOSType:          "linux",
+			DeployKernelURL:         "http://172.22.0.1/images/ipa.kernel",
+			DeployRamdiskURL:        "http://172.22.0.1/images/ipa.initramfs",
+		}),
+		Entry("Meta data has no namespace", testCaseSetHostSpec{
+			UserDataNamespace:         "",
+			ExpectedUserDataNamespace: "myns",
+			Host: newBareMetalHost("host2", nil, bmh.StateNone,
+				nil, false, false,
+			),
+			ExpectedImage:           expectedImg(),
+			ExpectUserData:          true,
+			ExpectedBootstrapFormat: "cloud-init",
+			ExpectedOSType:          "linux",
+			MetaData:                &corev1.SecretReference{Name: "meta-data"},
+			ExpectedMetaData:        "myns/meta-data",
+		}),
+		Entry("Meta data has explicit alternate namespace", testCaseSetHostSpec{
+			UserDataNamespace:         "",
+			ExpectedUserDataNamespace: "myns",
+			Host: newBareMetalHost("host2", nil, bmh.StateNone,
+				nil, false, false,
+			),
+			ExpectedImage:           expectedImg(),
+			ExpectUserData:          true,
+			ExpectedBootstrapFormat: "cloud-init",
+			ExpectedOSType:          "linux",
+			MetaData: &corev1.SecretReference{
+				Name: "meta-data", Namespace: "otherns",
+			},
+			ExpectedMetaData: "otherns/meta-data",
 		}),
 		Entry("Previously provisioned, different image",
 			testCaseSetHostSpec{
@@ -662,6 +945,36 @@ var _ = Describe("BareMetalMachine manager", func() {
 		),
 	)
 
+	DescribeTable("Test SetHostSpec with an AutomatedCleaningMode",
+		func(mode capm3.AutomatedCleaningMode, expectedMode string) {
+			host := newBareMetalHost("host2", nil, bmh.StateNone, nil, false,
+				false,
+			)
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+			bmmconfig, infrastructureRef := newConfig("",
+				map[string]string{}, []capm3.HostSelectorRequirement{},
+			)
+			bmmconfig.Spec.AutomatedCleaningMode = mode
+			machineMgr, err := NewMachineManager(c, nil, nil,
+				newMachine("machine1", "", infrastructureRef), bmmconfig,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(machineMgr.setHostSpec(context.TODO(), host)).To(Succeed())
+
+			savedHost := bmh.BareMetalHost{}
+			Expect(c.Get(context.TODO(), client.ObjectKey{
+				Name: host.Name, Namespace: host.Namespace,
+			}, &savedHost)).To(Succeed())
+			Expect(savedHost.Annotations[AutomatedCleaningModeAnnotation]).
+				To(Equal(expectedMode))
+		},
+		Entry("Defaults to metadata", capm3.AutomatedCleaningMode(""), "metadata"),
+		Entry("Metadata", capm3.CleaningModeMetadata, "metadata"),
+		Entry("Disabled", capm3.CleaningModeDisabled, "disabled"),
+	)
+
 	Describe("Test Exists function", func() {
 		host := bmh.BareMetalHost{
 			ObjectMeta: metav1.ObjectMeta{
@@ -1034,6 +1347,7 @@ var _ = Describe("BareMetalMachine manager", func() {
 				objects = append(objects, tc.BMCSecret)
 			}
 			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), objects...)
+			tc.BMMachine.Status.HostName = pointer.StringPtr("myhost")
 
 			machineMgr, err := NewMachineManager(c, nil, nil, tc.Machine,
 				tc.BMMachine, klogr.New(),
@@ -1044,10 +1358,13 @@ var _ = Describe("BareMetalMachine manager", func() {
 
 			if tc.ExpectedResult == nil {
 				Expect(err).NotTo(HaveOccurred())
+				// The host is released
+				Expect(tc.BMMachine.Status.HostName).To(BeNil())
 			} else {
 				perr, ok := err.(*RequeueAfterError)
 				Expect(ok).To(BeTrue())
 				Expect(perr.Error()).To(Equal(tc.ExpectedResult.Error()))
+				Expect(tc.BMMachine.Status.HostName).To(Equal(pointer.StringPtr("myhost")))
 			}
 
 			if tc.Host != nil {
@@ -1284,6 +1601,184 @@ var _ = Describe("BareMetalMachine manager", func() {
 			ExpectSecretDeleted:       true,
 			ExpectClusterLabelDeleted: false,
 		}),
+		Entry("Machine is gone, secret we created should be deleted",
+			testCaseDelete{
+				Host: newBareMetalHost("myhost", bmhSpecNoImg(), bmh.StateReady,
+					bmhStatus(), false, false,
+				),
+				Machine: nil,
+				BMMachine: newBareMetalMachine("mybmmachine", nil, bmmSecret(), nil,
+					bmmObjectMetaWithValidAnnotations(),
+				),
+				Secret:              newSecretWithFinalizer(userDataFinalizer),
+				ExpectSecretDeleted: true,
+			},
+		),
+		Entry("Machine is gone, secret we did not create should not be deleted",
+			testCaseDelete{
+				Host: newBareMetalHost("myhost", bmhSpecNoImg(), bmh.StateReady,
+					bmhStatus(), false, false,
+				),
+				Machine: nil,
+				BMMachine: newBareMetalMachine("mybmmachine", nil, bmmSecret(), nil,
+					bmmObjectMetaWithValidAnnotations(),
+				),
+				Secret:              newSecret(),
+				ExpectSecretDeleted: false,
+			},
+		),
+		Entry("Machine is gone, Clusterlabel should be removed", testCaseDelete{
+			Machine: nil,
+			BMMachine: newBareMetalMachine("mybmmachine", nil, bmmSpecAll(), nil,
+				bmmObjectMetaWithClusterLabel(),
+			),
+			Host:                      newBareMetalHost("myhost", bmhSpecBMC(), bmh.StateNone, nil, false, true),
+			BMCSecret:                 newBMCSecret("mycredentials", true),
+			ExpectSecretDeleted:       true,
+			ExpectClusterLabelDeleted: true,
+		}),
+	)
+
+	It("Removes the annotations of the BareMetalMachine from the released host", func() {
+		host := newBareMetalHost("myhost", bmhSpecNoImg(), bmh.StateReady,
+			bmhStatus(), false, false,
+		)
+		host.Annotations = map[string]string{"foo": "bar"}
+		for _, key := range hostSpecAnnotations {
+			host.Annotations[key] = "value"
+		}
+		bmMachine := newBareMetalMachine("mybmmachine", nil, bmmSecret(), nil,
+			bmmObjectMetaWithValidAnnotations(),
+		)
+		bmMachine.Status.HostName = pointer.StringPtr("myhost")
+		c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), bmMachine,
+			host, newSecret(),
+		)
+		machineMgr, err := NewMachineManager(c, nil, nil,
+			newMachine("mymachine", "", nil), bmMachine, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(machineMgr.Delete(context.TODO())).To(Succeed())
+
+		savedHost := bmh.BareMetalHost{}
+		Expect(c.Get(context.TODO(), client.ObjectKey{
+			Name: host.Name, Namespace: host.Namespace,
+		}, &savedHost)).To(Succeed())
+		Expect(savedHost.Spec.ConsumerRef).To(BeNil())
+		for _, key := range hostSpecAnnotations {
+			Expect(savedHost.Annotations).NotTo(HaveKey(key))
+		}
+		// Other annotations are not removed
+		Expect(savedHost.Annotations["foo"]).To(Equal("bar"))
+	})
+
+	type testCasePropagateLabels struct {
+		ClusterLabels  map[string]string
+		MachineLabels  map[string]string
+		Keys           []string
+		PrefixMatch    bool
+		ExpectedLabels map[string]string
+	}
+
+	DescribeTable("Test PropagateLabels",
+		func(tc testCasePropagateLabels) {
+			cluster := &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Labels: tc.ClusterLabels},
+			}
+			bmMachine := &capm3.BareMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{Labels: tc.MachineLabels},
+			}
+			machineMgr, err := NewMachineManager(nil, cluster, nil, nil,
+				bmMachine, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			machineMgr.PropagateLabels(tc.Keys, tc.PrefixMatch)
+			Expect(bmMachine.Labels).To(Equal(tc.ExpectedLabels))
+		},
+		Entry("Allowed keys are propagated", testCasePropagateLabels{
+			ClusterLabels: map[string]string{
+				"owner": "team-a", "cost-center": "42", "env": "prod",
+			},
+			Keys: []string{"owner", "cost-center"},
+			ExpectedLabels: map[string]string{
+				"owner": "team-a", "cost-center": "42",
+			},
+		}),
+		Entry("Existing labels are not clobbered", testCasePropagateLabels{
+			ClusterLabels: map[string]string{
+				"owner": "team-a", "cost-center": "42",
+			},
+			MachineLabels: map[string]string{"owner": "team-b"},
+			Keys:          []string{"owner", "cost-center"},
+			ExpectedLabels: map[string]string{
+				"owner": "team-b", "cost-center": "42",
+			},
+		}),
+		Entry("Keys are matched as prefixes", testCasePropagateLabels{
+			ClusterLabels: map[string]string{
+				"example.com/owner": "team-a",
+				"example.com/cost":  "42",
+				"other.com/owner":   "team-c",
+			},
+			Keys:        []string{"example.com/"},
+			PrefixMatch: true,
+			ExpectedLabels: map[string]string{
+				"example.com/owner": "team-a", "example.com/cost": "42",
+			},
+		}),
+		Entry("Keys are not matched as prefixes by default",
+			testCasePropagateLabels{
+				ClusterLabels: map[string]string{
+					"example.com/owner": "team-a",
+				},
+				MachineLabels:  map[string]string{"app": "foo"},
+				Keys:           []string{"example.com/"},
+				ExpectedLabels: map[string]string{"app": "foo"},
+			},
+		),
+		Entry("No allowed keys", testCasePropagateLabels{
+			ClusterLabels:  map[string]string{"owner": "team-a"},
+			ExpectedLabels: nil,
+		}),
+	)
+
+	DescribeTable("Test hostFailureDomain",
+		func(host *bmh.BareMetalHost, expected *string) {
+			Expect(hostFailureDomain(host)).To(Equal(expected))
+		},
+		Entry("Host with failure domain", &bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{FailureDomainLabel: "rack-1"},
+			},
+		}, pointer.StringPtr("rack-1")),
+		Entry("Host without failure domain", &bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"key1": "value1"},
+			},
+		}, nil),
+		Entry("No host", nil, nil),
+	)
+
+	DescribeTable("Test hostPoweredOn",
+		func(host *bmh.BareMetalHost, expected *bool) {
+			Expect(hostPoweredOn(host)).To(Equal(expected))
+		},
+		Entry("Host powered on", &bmh.BareMetalHost{
+			Status: bmh.BareMetalHostStatus{
+				LastUpdated: &metav1.Time{},
+				PoweredOn:   true,
+			},
+		}, pointer.BoolPtr(true)),
+		Entry("Host powered off", &bmh.BareMetalHost{
+			Status: bmh.BareMetalHostStatus{
+				LastUpdated: &metav1.Time{},
+				PoweredOn:   false,
+			},
+		}, pointer.BoolPtr(false)),
+		Entry("Host status not reported yet", &bmh.BareMetalHost{}, nil),
+		Entry("No host", nil, nil),
 	)
 
 	Describe("Test UpdateMachineStatus", func() {
@@ -1488,6 +1983,37 @@ var _ = Describe("BareMetalMachine manager", func() {
 		)
 	})
 
+	It("Reflects the hardware details of the host", func() {
+		host := &bmh.BareMetalHost{
+			Status: bmh.BareMetalHostStatus{
+				HardwareDetails: &bmh.HardwareDetails{
+					CPU:          bmh.CPU{Count: 32},
+					RAMMebibytes: 131072,
+					NIC: []bmh.NIC{
+						{IP: "192.168.1.1"}, {IP: "172.0.20.2"},
+					},
+				},
+			},
+		}
+		bmMachine := newBareMetalMachine("mybmmachine", nil, nil, nil, nil)
+		machineMgr, err := NewMachineManager(
+			fakeclient.NewFakeClientWithScheme(setupSchemeMm()), nil, nil,
+			&capi.Machine{}, bmMachine, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(machineMgr.updateMachineStatus(context.TODO(), host)).To(Succeed())
+		Expect(bmMachine.Status.HardwareDetails).To(Equal(&capm3.HardwareDetails{
+			CPUCount:     32,
+			RAMMebibytes: 131072,
+			NICCount:     2,
+		}))
+
+		host.Status.HardwareDetails = nil
+		Expect(machineMgr.updateMachineStatus(context.TODO(), host)).To(Succeed())
+		Expect(bmMachine.Status.HardwareDetails).To(BeNil())
+	})
+
 	Describe("Test NodeAddresses", func() {
 		nic1 := bmh.NIC{
 			IP: "192.168.1.1",
@@ -1859,6 +2385,88 @@ var _ = Describe("BareMetalMachine manager", func() {
 		}),
 	)
 
+	type testCaseUserDataPrecedence struct {
+		UserData         *corev1.SecretReference
+		DataSecretName   *string
+		ExpectedUserData *corev1.SecretReference
+		ExpectOverridden bool
+	}
+
+	DescribeTable("Test GetUserData precedence",
+		func(tc testCaseUserDataPrecedence) {
+			machine := &capi.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "myns",
+				},
+				Spec: capi.MachineSpec{
+					Bootstrap: capi.Bootstrap{
+						DataSecretName: tc.DataSecretName,
+					},
+				},
+			}
+			bmMachine := newBareMetalMachine("mybmmachine", nil,
+				&capm3.BareMetalMachineSpec{UserData: tc.UserData}, nil, nil,
+			)
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), machine,
+				bmMachine,
+			)
+
+			machineMgr, err := NewMachineManager(c, nil, nil, machine,
+				bmMachine, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = machineMgr.GetUserData(context.TODO(),
+				newBareMetalHost("myhost", nil, bmh.StateNone, nil, false, false),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(bmMachine.Spec.UserData).To(Equal(tc.ExpectedUserData))
+			condition := bmMachine.Status.Conditions.Get(capm3.UserDataOverriddenCondition)
+			if tc.ExpectOverridden {
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+				Expect(condition.Message).To(ContainSubstring("myns/explicit"))
+				Expect(condition.Message).To(ContainSubstring("takes precedence"))
+			} else {
+				Expect(condition).To(BeNil())
+			}
+		},
+		Entry("UserData and bootstrap data conflict", testCaseUserDataPrecedence{
+			UserData: &corev1.SecretReference{
+				Name: "explicit", Namespace: "myns",
+			},
+			DataSecretName: pointer.StringPtr("Foobar"),
+			ExpectedUserData: &corev1.SecretReference{
+				Name: "Foobar", Namespace: "myns",
+			},
+			ExpectOverridden: true,
+		}),
+		Entry("UserData already set to the bootstrap data", testCaseUserDataPrecedence{
+			UserData: &corev1.SecretReference{
+				Name: "Foobar", Namespace: "myns",
+			},
+			DataSecretName: pointer.StringPtr("Foobar"),
+			ExpectedUserData: &corev1.SecretReference{
+				Name: "Foobar", Namespace: "myns",
+			},
+		}),
+		Entry("UserData without bootstrap data", testCaseUserDataPrecedence{
+			UserData: &corev1.SecretReference{
+				Name: "explicit", Namespace: "myns",
+			},
+			ExpectedUserData: &corev1.SecretReference{
+				Name: "explicit", Namespace: "myns",
+			},
+		}),
+		Entry("Bootstrap data without UserData", testCaseUserDataPrecedence{
+			DataSecretName: pointer.StringPtr("Foobar"),
+			ExpectedUserData: &corev1.SecretReference{
+				Name: "Foobar", Namespace: "myns",
+			},
+		}),
+	)
+
 	type testCaseAssociate struct {
 		Machine            *capi.Machine
 		Host               *bmh.BareMetalHost
@@ -1892,6 +2500,7 @@ var _ = Describe("BareMetalMachine manager", func() {
 			if tc.ExpectRequeue {
 				_, ok := errors.Cause(err).(HasRequeueAfterError)
 				Expect(ok).To(BeTrue())
+				Expect(tc.BMMachine.Status.HostName).To(BeNil())
 			} else {
 				Expect(err).NotTo(HaveOccurred())
 			}
@@ -1912,6 +2521,7 @@ var _ = Describe("BareMetalMachine manager", func() {
 			_, err = machineMgr.FindOwnerRef(savedHost.OwnerReferences)
 			if tc.ExpectOwnerRef {
 				Expect(err).NotTo(HaveOccurred())
+				Expect(tc.BMMachine.Status.HostName).To(Equal(&savedHost.Name))
 			} else {
 				Expect(err).To(HaveOccurred())
 			}
@@ -1989,6 +2599,144 @@ var _ = Describe("BareMetalMachine manager", func() {
 		),
 	)
 
+	type testCaseGetOwnerMachine struct {
+		OwnerRefs       []metav1.OwnerReference
+		Machine         *capi.Machine
+		ExpectError     bool
+		ExpectMachine   bool
+		ExpectCondition bool
+	}
+
+	DescribeTable("Test GetOwnerMachine",
+		func(tc testCaseGetOwnerMachine) {
+			objMeta := bmmObjectMetaWithValidAnnotations()
+			objMeta.OwnerReferences = tc.OwnerRefs
+			bmMachine := newBareMetalMachine("mybmmachine", nil, nil, nil,
+				objMeta,
+			)
+			objects := []runtime.Object{bmMachine}
+			if tc.Machine != nil {
+				objects = append(objects, tc.Machine)
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), objects...)
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, bmMachine,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			machine, err := machineMgr.GetOwnerMachine(context.TODO())
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			if tc.ExpectMachine {
+				Expect(machine).NotTo(BeNil())
+				Expect(machine.Name).To(Equal(tc.Machine.Name))
+				Expect(machineMgr.Machine).To(Equal(machine))
+			} else {
+				Expect(machine).To(BeNil())
+			}
+			Expect(bmMachine.Status.Conditions.IsTrue(
+				capm3.WaitingForOwnerCondition,
+			)).To(Equal(tc.ExpectCondition))
+		},
+		Entry("No owner reference", testCaseGetOwnerMachine{
+			ExpectCondition: true,
+		}),
+		Entry("Owner Machine found", testCaseGetOwnerMachine{
+			OwnerRefs: []metav1.OwnerReference{{
+				APIVersion: capi.GroupVersion.String(),
+				Kind:       "Machine",
+				Name:       "mymachine",
+			}},
+			Machine:       newMachine("mymachine", "mybmmachine", nil),
+			ExpectMachine: true,
+		}),
+		Entry("Owner Machine not found", testCaseGetOwnerMachine{
+			OwnerRefs: []metav1.OwnerReference{{
+				APIVersion: capi.GroupVersion.String(),
+				Kind:       "Machine",
+				Name:       "mymachine",
+			}},
+			ExpectError: true,
+		}),
+	)
+
+	DescribeTable("Test checkHostConsumer",
+		func(consumer *corev1.ObjectReference, expectConflict bool) {
+			host := newBareMetalHost("myhost", nil, bmh.StateNone, nil,
+				false, false,
+			)
+			host.Spec.ConsumerRef = consumer
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+			machineMgr, err := NewMachineManager(c, nil, nil, nil,
+				newBareMetalMachine("mybmmachine", nil, nil, nil,
+					bmmObjectMetaWithValidAnnotations(),
+				), klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = machineMgr.checkHostConsumer(context.TODO(),
+				newBareMetalHost("myhost", nil, bmh.StateNone, nil, false, false),
+			)
+			if expectConflict {
+				Expect(err).To(BeAssignableToTypeOf(&HostConflictError{}))
+				_, ok := errors.Cause(err).(HasRequeueAfterError)
+				Expect(ok).To(BeTrue())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+		},
+		Entry("No consumer", nil, false),
+		Entry("Consumer is the machine", consumerRef(), false),
+		Entry("Consumer is another machine", consumerRefSome(), true),
+	)
+
+	It("Lets only one of two BareMetalMachines claim the same host", func() {
+		host := newBareMetalHost("myhost", nil, bmh.StateNone, nil,
+			false, false,
+		)
+		bmMachineA := newBareMetalMachine("bmmachine-a", nil, bmmSpecAll(), nil,
+			nil,
+		)
+		bmMachineB := newBareMetalMachine("bmmachine-b", nil, bmmSpecAll(), nil,
+			nil,
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host,
+			bmMachineA, bmMachineB,
+		)
+		machineMgrA, err := NewMachineManager(c, nil, nil,
+			newMachine("machine-a", "bmmachine-a", nil), bmMachineA, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		machineMgrB, err := NewMachineManager(c, nil, nil,
+			newMachine("machine-b", "bmmachine-b", nil), bmMachineB, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Both machines pick the host before either of them claims it
+		hostA, err := machineMgrA.chooseHost(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hostA).NotTo(BeNil())
+		hostB, err := machineMgrB.chooseHost(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hostB).NotTo(BeNil())
+		Expect(hostB.Name).To(Equal(hostA.Name))
+
+		Expect(machineMgrA.checkHostConsumer(context.TODO(), hostA)).To(Succeed())
+		Expect(machineMgrA.setHostSpec(context.TODO(), hostA)).To(Succeed())
+
+		err = machineMgrB.checkHostConsumer(context.TODO(), hostB)
+		Expect(err).To(BeAssignableToTypeOf(&HostConflictError{}))
+
+		savedHost := bmh.BareMetalHost{}
+		Expect(c.Get(context.TODO(), client.ObjectKey{
+			Name: "myhost", Namespace: "myns",
+		}, &savedHost)).To(Succeed())
+		Expect(savedHost.Spec.ConsumerRef.Name).To(Equal("bmmachine-a"))
+	})
+
 	type testCaseUpdate struct {
 		Machine   *capi.Machine
 		Host      *bmh.BareMetalHost
@@ -2425,6 +3173,12 @@ func newBMCSecret(name string, clusterlabel bool) *corev1.Secret {
 	}
 }
 
+func newSecretWithFinalizer(finalizer string) *corev1.Secret {
+	secret := newSecret()
+	secret.Finalizers = []string{finalizer}
+	return secret
+}
+
 func newSecret() *corev1.Secret {
 	return &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{
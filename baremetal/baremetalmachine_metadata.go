@@ -0,0 +1,37 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+// MetaDataAnnotation is the key for an annotation set on a BareMetalHost to
+// reference the Secret holding the OpenStack-style metadata written to its
+// config drive, as namespace/name.
+const MetaDataAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/meta-data"
+
+// metaDataKey returns the namespace/name of the MetaData Secret, or an empty
+// string if there is none. As for the UserData, the namespace defaults to the
+// one of the Machine.
+func (m *MachineManager) metaDataKey() string {
+	metaData := m.BareMetalMachine.Spec.MetaData
+	if metaData == nil {
+		return ""
+	}
+	namespace := metaData.Namespace
+	if namespace == "" {
+		namespace = m.Machine.Namespace
+	}
+	return namespace + "/" + metaData.Name
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// NetworkDataAnnotation is the key for an annotation set on a
+	// BareMetalHost to reference the Secret holding its network
+	// configuration, as namespace/name.
+	NetworkDataAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/network-data"
+	// networkDataSecretKey is the key of the NetworkData Secret holding the
+	// OpenStack-style network_data.json.
+	networkDataSecretKey = "networkData"
+
+	networkConfiguredReason = "NICsConfigured"
+	waitingForNICsReason    = "WaitingForNICs"
+)
+
+// networkDataKey returns the namespace/name of the NetworkData Secret, or an
+// empty string if there is none.
+func (m *MachineManager) networkDataKey() string {
+	networkData := m.BareMetalMachine.Spec.NetworkData
+	if networkData == nil {
+		return ""
+	}
+	namespace := networkData.Namespace
+	if namespace == "" {
+		namespace = m.BareMetalMachine.Namespace
+	}
+	return namespace + "/" + networkData.Name
+}
+
+// setNetworkConfiguredCondition sets the NetworkConfigured condition from the
+// NICs reported by the host. The condition is removed when there is no
+// NetworkData.
+func (m *MachineManager) setNetworkConfiguredCondition(ctx context.Context,
+	host *bmh.BareMetalHost) error {
+
+	conditions := &m.BareMetalMachine.Status.Conditions
+	if m.BareMetalMachine.Spec.NetworkData == nil {
+		if conditions.Get(capm3.NetworkConfiguredCondition) != nil {
+			conditions.Remove(capm3.NetworkConfiguredCondition)
+		}
+		return nil
+	}
+
+	macs, err := m.networkDataMACs(ctx)
+	if err != nil {
+		return err
+	}
+	if hostNetworkConfigured(host, macs) {
+		conditions.Set(capm3.Condition{
+			Type:    capm3.NetworkConfiguredCondition,
+			Status:  corev1.ConditionTrue,
+			Reason:  networkConfiguredReason,
+			Message: "The host reports its NICs configured",
+		})
+		return nil
+	}
+	conditions.Set(capm3.Condition{
+		Type:    capm3.NetworkConfiguredCondition,
+		Status:  corev1.ConditionFalse,
+		Reason:  waitingForNICsReason,
+		Message: "Waiting for the host to report its NICs configured",
+	})
+	return nil
+}
+
+// networkDataMACs returns the lowercased MAC addresses of the links
+// configured by the NetworkData, read from the networkData key of its Secret.
+func (m *MachineManager) networkDataMACs(ctx context.Context) ([]string, error) {
+	networkData := m.BareMetalMachine.Spec.NetworkData
+	key := client.ObjectKey{
+		Name:      networkData.Name,
+		Namespace: networkData.Namespace,
+	}
+	if key.Namespace == "" {
+		key.Namespace = m.BareMetalMachine.Namespace
+	}
+	secret := corev1.Secret{}
+	if err := m.client.Get(ctx, key, &secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to get the NetworkData Secret %s/%s",
+			key.Namespace, key.Name,
+		)
+	}
+
+	content := struct {
+		Links []struct {
+			MACAddress string `json:"ethernet_mac_address"`
+		} `json:"links"`
+	}{}
+	if err := json.Unmarshal(secret.Data[networkDataSecretKey], &content); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the NetworkData Secret %s/%s",
+			key.Namespace, key.Name,
+		)
+	}
+	macs := []string{}
+	for _, link := range content.Links {
+		if link.MACAddress != "" {
+			macs = append(macs, strings.ToLower(link.MACAddress))
+		}
+	}
+	return macs, nil
+}
+
+// networkReady returns true if the machine has no NetworkData, or if the host
+// reported its NICs configured.
+func (m *MachineManager) networkReady() bool {
+	if m.BareMetalMachine.Spec.NetworkData == nil {
+		return true
+	}
+	return m.BareMetalMachine.Status.Conditions.IsTrue(
+		capm3.NetworkConfiguredCondition,
+	)
+}
+
+// hostNetworkConfigured returns true if the host reports an IP address on
+// the NICs with the given MAC addresses. The NICs not configured by the
+// NetworkData, e.g. unplugged ones, are ignored. Without MAC addresses, one
+// NIC with an IP address is enough.
+func hostNetworkConfigured(host *bmh.BareMetalHost, macs []string) bool {
+	if host == nil || host.Status.HardwareDetails == nil {
+		return false
+	}
+	addressed := map[string]bool{}
+	for _, nic := range host.Status.HardwareDetails.NIC {
+		if nic.IP != "" {
+			addressed[strings.ToLower(nic.MAC)] = true
+		}
+	}
+	if len(macs) == 0 {
+		return len(addressed) > 0
+	}
+	for _, mac := range macs {
+		if !addressed[mac] {
+			return false
+		}
+	}
+	return true
+}
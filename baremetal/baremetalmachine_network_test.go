@@ -0,0 +1,268 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalMachine network configuration", func() {
+
+	provisionedHost := func(nics []bmh.NIC) *bmh.BareMetalHost {
+		return &bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "myhost",
+				Namespace: "myns",
+				UID:       "12345ID6789",
+			},
+			Status: bmh.BareMetalHostStatus{
+				Provisioning: bmh.ProvisionStatus{
+					State: bmh.StateProvisioned,
+				},
+				HardwareDetails: &bmh.HardwareDetails{
+					NIC: nics,
+				},
+			},
+		}
+	}
+
+	networkData := &corev1.SecretReference{Name: "mybmmachine-network-data"}
+
+	// networkDataSecret configures the NIC with the MAC 00:00:00:00:00:01
+	networkDataSecret := func() *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "mybmmachine-network-data",
+				Namespace: "myns",
+			},
+			Data: map[string][]byte{
+				"networkData": []byte(`{"links":[{"id":"eth0","type":"phy","ethernet_mac_address":"00:00:00:00:00:01"}]}`),
+			},
+		}
+	}
+
+	type testCaseNetworkGating struct {
+		NetworkData             *corev1.SecretReference
+		NICs                    []bmh.NIC
+		ExpectPresent           bool
+		ExpectedPhase           string
+		ExpectedConditionStatus corev1.ConditionStatus
+	}
+
+	DescribeTable("Test GetBaremetalHostID network gating",
+		func(tc testCaseNetworkGating) {
+			spec := bmmSpec()
+			spec.NetworkData = tc.NetworkData
+			bmMachine := newBareMetalMachine("mybmmachine", nil, spec, nil,
+				bmmObjectMetaWithValidAnnotations(),
+			)
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(),
+				provisionedHost(tc.NICs), networkDataSecret(),
+			)
+			machineMgr, err := NewMachineManager(c, nil, nil,
+				newMachine("", "", nil), bmMachine, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			bmhID, err := machineMgr.GetBaremetalHostID(context.TODO())
+			if tc.ExpectPresent {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(bmhID).NotTo(BeNil())
+			} else {
+				_, ok := err.(HasRequeueAfterError)
+				Expect(ok).To(BeTrue())
+				Expect(bmhID).To(BeNil())
+			}
+			Expect(bmMachine.Status.Phase).To(Equal(tc.ExpectedPhase))
+
+			condition := bmMachine.Status.Conditions.Get(
+				capm3.NetworkConfiguredCondition,
+			)
+			if tc.ExpectedConditionStatus == "" {
+				Expect(condition).To(BeNil())
+				return
+			}
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(tc.ExpectedConditionStatus))
+		},
+		Entry("No NetworkData", testCaseNetworkGating{
+			NetworkData:   nil,
+			NICs:          []bmh.NIC{{Name: "eth0"}},
+			ExpectPresent: true,
+			ExpectedPhase: capm3.BareMetalMachinePhaseProvisioned,
+		}),
+		Entry("NetworkData, NICs not configured", testCaseNetworkGating{
+			NetworkData: networkData,
+			NICs: []bmh.NIC{
+				{Name: "eth0", MAC: "00:00:00:00:00:01"},
+				{Name: "eth1", MAC: "00:00:00:00:00:02", IP: "192.168.111.20"},
+			},
+			ExpectPresent:           false,
+			ExpectedPhase:           capm3.BareMetalMachinePhaseProvisioning,
+			ExpectedConditionStatus: corev1.ConditionFalse,
+		}),
+		Entry("NetworkData, no NICs reported", testCaseNetworkGating{
+			NetworkData:             networkData,
+			NICs:                    nil,
+			ExpectPresent:           false,
+			ExpectedPhase:           capm3.BareMetalMachinePhaseProvisioning,
+			ExpectedConditionStatus: corev1.ConditionFalse,
+		}),
+		Entry("NetworkData, NICs configured", testCaseNetworkGating{
+			NetworkData: networkData,
+			NICs: []bmh.NIC{
+				{Name: "eth0", MAC: "00:00:00:00:00:01", IP: "192.168.111.20"},
+				{Name: "eth1", MAC: "00:00:00:00:00:02", IP: "172.22.0.20"},
+			},
+			ExpectPresent:           true,
+			ExpectedPhase:           capm3.BareMetalMachinePhaseProvisioned,
+			ExpectedConditionStatus: corev1.ConditionTrue,
+		}),
+		Entry("NetworkData, unplugged NIC not configured by it", testCaseNetworkGating{
+			NetworkData: networkData,
+			NICs: []bmh.NIC{
+				{Name: "eth0", MAC: "00:00:00:00:00:01", IP: "192.168.111.20"},
+				{Name: "eth1", MAC: "00:00:00:00:00:02"},
+			},
+			ExpectPresent:           true,
+			ExpectedPhase:           capm3.BareMetalMachinePhaseProvisioned,
+			ExpectedConditionStatus: corev1.ConditionTrue,
+		}),
+	)
+
+	It("Transitions NetworkConfigured once the NICs are configured", func() {
+		spec := bmmSpec()
+		spec.NetworkData = networkData
+		bmMachine := newBareMetalMachine("mybmmachine", nil, spec, nil,
+			bmmObjectMetaWithValidAnnotations(),
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(),
+			networkDataSecret(),
+		)
+		machineMgr, err := NewMachineManager(c, nil, nil, nil, bmMachine,
+			klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		host := provisionedHost([]bmh.NIC{
+			{Name: "eth0", MAC: "00:00:00:00:00:01"},
+		})
+		Expect(machineMgr.setNetworkConfiguredCondition(context.TODO(), host)).
+			To(Succeed())
+		Expect(machineMgr.networkReady()).To(BeFalse())
+		since := metav1.NewTime(time.Now().Add(-time.Hour))
+		bmMachine.Status.Conditions[0].LastTransitionTime = since
+
+		// No transition while the NICs stay unconfigured
+		Expect(machineMgr.setNetworkConfiguredCondition(context.TODO(), host)).
+			To(Succeed())
+		condition := bmMachine.Status.Conditions.Get(
+			capm3.NetworkConfiguredCondition,
+		)
+		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition.LastTransitionTime).To(Equal(since))
+
+		host.Status.HardwareDetails.NIC[0].IP = "192.168.111.20"
+		Expect(machineMgr.setNetworkConfiguredCondition(context.TODO(), host)).
+			To(Succeed())
+		Expect(machineMgr.networkReady()).To(BeTrue())
+		condition = bmMachine.Status.Conditions.Get(
+			capm3.NetworkConfiguredCondition,
+		)
+		Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+		Expect(condition.LastTransitionTime.After(since.Time)).To(BeTrue())
+
+		// The condition is dropped with the NetworkData
+		bmMachine.Spec.NetworkData = nil
+		Expect(machineMgr.setNetworkConfiguredCondition(context.TODO(), host)).
+			To(Succeed())
+		Expect(bmMachine.Status.Conditions.Get(
+			capm3.NetworkConfiguredCondition,
+		)).To(BeNil())
+	})
+
+	DescribeTable("Test hostNetworkConfigured",
+		func(nics []bmh.NIC, macs []string, expected bool) {
+			host := provisionedHost(nics)
+			Expect(hostNetworkConfigured(host, macs)).To(Equal(expected))
+		},
+		Entry("No NIC reported", nil, []string{"00:00:00:00:00:01"}, false),
+		Entry("Configured NIC addressed, MAC case ignored", []bmh.NIC{
+			{MAC: "AA:00:00:00:00:01", IP: "192.168.111.20"},
+		}, []string{"aa:00:00:00:00:01"}, true),
+		Entry("Configured NIC missing", []bmh.NIC{
+			{MAC: "00:00:00:00:00:02", IP: "192.168.111.20"},
+		}, []string{"00:00:00:00:00:01"}, false),
+		Entry("No MAC in the NetworkData, one NIC addressed", []bmh.NIC{
+			{MAC: "00:00:00:00:00:01", IP: "192.168.111.20"},
+			{MAC: "00:00:00:00:00:02"},
+		}, []string{}, true),
+		Entry("No MAC in the NetworkData, no NIC addressed", []bmh.NIC{
+			{MAC: "00:00:00:00:00:01"},
+		}, []string{}, false),
+	)
+
+	It("Fails when the NetworkData Secret is missing", func() {
+		spec := bmmSpec()
+		spec.NetworkData = networkData
+		machineMgr, err := NewMachineManager(
+			fakeclient.NewFakeClientWithScheme(setupSchemeMm()), nil, nil, nil,
+			newBareMetalMachine("mybmmachine", nil, spec, nil, nil),
+			klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = machineMgr.setNetworkConfiguredCondition(context.TODO(),
+			provisionedHost(nil),
+		)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("myns/mybmmachine-network-data"))
+	})
+
+	DescribeTable("Test networkDataKey",
+		func(networkData *corev1.SecretReference, expected string) {
+			spec := bmmSpec()
+			spec.NetworkData = networkData
+			machineMgr, err := NewMachineManager(nil, nil, nil, nil,
+				newBareMetalMachine("mybmmachine", nil, spec, nil, nil),
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(machineMgr.networkDataKey()).To(Equal(expected))
+		},
+		Entry("No NetworkData", nil, ""),
+		Entry("NetworkData without namespace", &corev1.SecretReference{
+			Name: "mynetworkdata",
+		}, "myns/mynetworkdata"),
+		Entry("NetworkData with namespace", &corev1.SecretReference{
+			Name:      "mynetworkdata",
+			Namespace: "otherns",
+		}, "otherns/mynetworkdata"),
+	)
+})
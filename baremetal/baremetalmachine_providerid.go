@@ -0,0 +1,36 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"fmt"
+
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+)
+
+// ProviderID returns the ProviderID of the BareMetalMachine for the host
+// with the given ID, in the format selected by the
+// ProviderIDFormatAnnotation, the current one by default.
+func (m *MachineManager) ProviderID(bmhID string) string {
+	format := capm3.ProviderIDFormat(
+		m.BareMetalMachine.Annotations[capm3.ProviderIDFormatAnnotation],
+	)
+	if format == capm3.ProviderIDFormatLegacy {
+		return fmt.Sprintf("baremetal:////%s", m.BareMetalMachine.Name)
+	}
+	return fmt.Sprintf("metal3://%s", bmhID)
+}
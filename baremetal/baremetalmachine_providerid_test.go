@@ -0,0 +1,58 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"k8s.io/klog/klogr"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalMachine ProviderID", func() {
+
+	DescribeTable("Test ProviderID",
+		func(format *capm3.ProviderIDFormat, expectedProviderID string) {
+			objMeta := bmmObjectMetaWithValidAnnotations()
+			if format != nil {
+				objMeta.Annotations[capm3.ProviderIDFormatAnnotation] = string(*format)
+			}
+			bmMachine := newBareMetalMachine("mybmmachine", nil, nil, nil, objMeta)
+			machineMgr, err := NewMachineManager(
+				fakeclient.NewFakeClientWithScheme(setupSchemeMm()),
+				nil, nil, nil, bmMachine, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(machineMgr.ProviderID("abc")).To(Equal(expectedProviderID))
+		},
+		Entry("Current format by default", nil, "metal3://abc"),
+		Entry("Current format", formatPtr(capm3.ProviderIDFormatCurrent),
+			"metal3://abc",
+		),
+		Entry("Legacy format", formatPtr(capm3.ProviderIDFormatLegacy),
+			"baremetal:////mybmmachine",
+		),
+	)
+})
+
+func formatPtr(format capm3.ProviderIDFormat) *capm3.ProviderIDFormat {
+	return &format
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+)
+
+const (
+	provisioningTimedOutReason = "ProvisioningTimeoutExceeded"
+
+	// ProvisioningRetryBaseDelay is the delay before the first retry of a
+	// failed provisioning. It doubles with each retry, up to
+	// ProvisioningRetryMaxDelay.
+	ProvisioningRetryBaseDelay = time.Minute
+	// ProvisioningRetryMaxDelay caps the delay between the retries of a
+	// failed provisioning.
+	ProvisioningRetryMaxDelay = 30 * time.Minute
+)
+
+// provisioningTimeout returns the ProvisioningTimeout of the machine, or
+// DefaultProvisioningTimeout if unset.
+func (m *MachineManager) provisioningTimeout() time.Duration {
+	if m.BareMetalMachine.Spec.ProvisioningTimeout == nil {
+		return capm3.DefaultProvisioningTimeout
+	}
+	return m.BareMetalMachine.Spec.ProvisioningTimeout.Duration
+}
+
+// checkProvisioningTimeout is called while waiting for the host to
+// provision. It records when the provisioning started, and sets the machine
+// Failed once the ProvisioningTimeout is exceeded.
+func (m *MachineManager) checkProvisioningTimeout(hostName string) {
+	status := &m.BareMetalMachine.Status
+	if status.ProvisioningStartTime == nil {
+		now := metav1.Now()
+		status.ProvisioningStartTime = &now
+	}
+
+	timeout := m.provisioningTimeout()
+	if timeout == 0 || time.Since(status.ProvisioningStartTime.Time) < timeout {
+		return
+	}
+
+	m.Log.Info("Timed out provisioning BaremetalHost", "host", hostName,
+		"timeout", timeout,
+	)
+	message := fmt.Sprintf("Host %s did not provision within %s",
+		hostName, timeout,
+	)
+	status.Phase = capm3.BareMetalMachinePhaseFailed
+	status.Conditions.Set(capm3.Condition{
+		Type:    capm3.ProvisioningTimedOutCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  provisioningTimedOutReason,
+		Message: message,
+	})
+	m.setError(message, capierrors.CreateMachineError)
+}
+
+// checkProvisioningFailure is called while waiting for the host to
+// provision. When the host reports a provisioning error, its image is
+// removed so that it is deprovisioned, and set again once the backoff delay
+// is elapsed and the host deprovisioned. A RequeueAfterError is returned
+// while the retry is pending. The machine is set Failed once
+// MaxProvisioningRetries retries failed. Without MaxProvisioningRetries, the
+// error is left to the host and the ProvisioningTimeout.
+func (m *MachineManager) checkProvisioningFailure(ctx context.Context, host *bmh.BareMetalHost) error {
+	status := &m.BareMetalMachine.Status
+	retry := status.ProvisioningRetry
+	if retry != nil && retry.NextRetryTime != nil {
+		if !m.waitingForProvisioningRetry(host) {
+			m.Log.Info("Provisioning BaremetalHost again", "host", host.Name,
+				"attempt", retry.Attempts,
+			)
+			return m.setHostSpec(ctx, host)
+		}
+		wait := time.Until(retry.NextRetryTime.Time)
+		if wait < requeueAfter {
+			wait = requeueAfter
+		}
+		return &RequeueAfterError{RequeueAfter: wait}
+	}
+	if host.Status.ErrorType != bmh.ProvisioningError ||
+		m.BareMetalMachine.Spec.MaxProvisioningRetries == 0 {
+		return nil
+	}
+
+	attempts := 0
+	if retry != nil {
+		attempts = retry.Attempts
+	}
+	if attempts >= m.BareMetalMachine.Spec.MaxProvisioningRetries {
+		m.Log.Info("Failed provisioning BaremetalHost", "host", host.Name,
+			"retries", attempts,
+		)
+		status.Phase = capm3.BareMetalMachinePhaseFailed
+		m.setError(fmt.Sprintf("Host %s failed provisioning after %d retries: %s",
+			host.Name, attempts, host.Status.ErrorMessage,
+		), capierrors.CreateMachineError)
+		return nil
+	}
+
+	host.Spec.Image = nil
+	if err := m.client.Update(ctx, host); err != nil {
+		return err
+	}
+	attempts++
+	delay := provisioningRetryDelay(attempts)
+	next := metav1.NewTime(time.Now().Add(delay))
+	status.ProvisioningRetry = &capm3.ProvisioningRetryStatus{
+		Attempts:      attempts,
+		NextRetryTime: &next,
+	}
+	m.Log.Info("Retrying provisioning of BaremetalHost", "host", host.Name,
+		"attempt", attempts, "delay", delay,
+	)
+	return &RequeueAfterError{RequeueAfter: delay}
+}
+
+// provisioningRetryDelay returns the backoff delay before the given retry,
+// counted from 1.
+func provisioningRetryDelay(attempt int) time.Duration {
+	delay := ProvisioningRetryBaseDelay
+	for i := 1; i < attempt && delay < ProvisioningRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > ProvisioningRetryMaxDelay {
+		delay = ProvisioningRetryMaxDelay
+	}
+	return delay
+}
+
+// waitingForProvisioningRetry returns true while a retry of the provisioning
+// is pending, until the backoff delay is elapsed and the host deprovisioned.
+func (m *MachineManager) waitingForProvisioningRetry(host *bmh.BareMetalHost) bool {
+	retry := m.BareMetalMachine.Status.ProvisioningRetry
+	if retry == nil || retry.NextRetryTime == nil {
+		return false
+	}
+	if time.Now().Before(retry.NextRetryTime.Time) {
+		return true
+	}
+	switch host.Status.Provisioning.State {
+	case bmh.StateReady, bmh.StateAvailable:
+		return false
+	}
+	return true
+}
+
+// provisioningRetried marks the pending retry of the provisioning, if any,
+// as started. The number of attempts is kept until the host is provisioned.
+func (m *MachineManager) provisioningRetried() {
+	if retry := m.BareMetalMachine.Status.ProvisioningRetry; retry != nil {
+		retry.NextRetryTime = nil
+	}
+}
+
+// provisioningDone clears the provisioning start time, the retries and the
+// timeout condition once the host is provisioned.
+func (m *MachineManager) provisioningDone() {
+	m.BareMetalMachine.Status.ProvisioningStartTime = nil
+	m.BareMetalMachine.Status.ProvisioningRetry = nil
+	if m.BareMetalMachine.Status.Conditions.Get(capm3.ProvisioningTimedOutCondition) != nil {
+		m.BareMetalMachine.Status.Conditions.Remove(capm3.ProvisioningTimedOutCondition)
+	}
+}
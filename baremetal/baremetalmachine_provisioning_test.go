@@ -0,0 +1,246 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalMachine provisioning timeout", func() {
+
+	type testCaseProvisioningTimeout struct {
+		Timeout             *metav1.Duration
+		StartedAgo          time.Duration
+		HostState           bmh.ProvisioningState
+		ExpectProvisioned   bool
+		ExpectFailed        bool
+		ExpectStartRecorded bool
+	}
+
+	DescribeTable("Test GetBaremetalHostID with ProvisioningTimeout",
+		func(tc testCaseProvisioningTimeout) {
+			host := newBareMetalHost("myhost", bmhSpecNoImg(), tc.HostState,
+				bmhStatus(), false, false,
+			)
+			status := &capm3.BareMetalMachineStatus{}
+			if tc.StartedAgo != 0 {
+				start := metav1.NewTime(time.Now().Add(-tc.StartedAgo))
+				status.ProvisioningStartTime = &start
+			}
+			spec := bmmSecret()
+			spec.ProvisioningTimeout = tc.Timeout
+			bmMachine := newBareMetalMachine("mybmmachine", nil, spec, status,
+				bmmObjectMetaWithValidAnnotations(),
+			)
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+
+			machineMgr, err := NewMachineManager(c, nil, nil,
+				newMachine("mymachine", "", nil), bmMachine, klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			providerID, err := machineMgr.GetBaremetalHostID(context.TODO())
+			if tc.ExpectProvisioned {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(providerID).NotTo(BeNil())
+				Expect(bmMachine.Status.ProvisioningStartTime).To(BeNil())
+			} else {
+				_, ok := err.(*RequeueAfterError)
+				Expect(ok).To(BeTrue())
+			}
+
+			conditions := bmMachine.Status.Conditions
+			if tc.ExpectFailed {
+				Expect(bmMachine.Status.Phase).To(Equal(capm3.BareMetalMachinePhaseFailed))
+				Expect(*bmMachine.Status.FailureReason).To(
+					Equal(capierrors.CreateMachineError),
+				)
+				Expect(*bmMachine.Status.FailureMessage).To(ContainSubstring("myhost"))
+				Expect(conditions.IsTrue(capm3.ProvisioningTimedOutCondition)).To(BeTrue())
+			} else {
+				Expect(bmMachine.Status.Phase).NotTo(Equal(capm3.BareMetalMachinePhaseFailed))
+				Expect(bmMachine.Status.FailureReason).To(BeNil())
+				Expect(conditions.Get(capm3.ProvisioningTimedOutCondition)).To(BeNil())
+			}
+
+			if tc.ExpectStartRecorded {
+				Expect(bmMachine.Status.ProvisioningStartTime).NotTo(BeNil())
+			}
+		},
+		Entry("Start time recorded", testCaseProvisioningTimeout{
+			Timeout:             &metav1.Duration{Duration: time.Hour},
+			HostState:           bmh.StateProvisioning,
+			ExpectStartRecorded: true,
+		}),
+		Entry("Within the timeout", testCaseProvisioningTimeout{
+			Timeout:             &metav1.Duration{Duration: time.Hour},
+			StartedAgo:          time.Minute,
+			HostState:           bmh.StateProvisioning,
+			ExpectStartRecorded: true,
+		}),
+		Entry("Timeout exceeded", testCaseProvisioningTimeout{
+			Timeout:             &metav1.Duration{Duration: time.Hour},
+			StartedAgo:          2 * time.Hour,
+			HostState:           bmh.StateProvisioning,
+			ExpectFailed:        true,
+			ExpectStartRecorded: true,
+		}),
+		Entry("Default timeout exceeded", testCaseProvisioningTimeout{
+			StartedAgo:          capm3.DefaultProvisioningTimeout + time.Minute,
+			HostState:           bmh.StateProvisioning,
+			ExpectFailed:        true,
+			ExpectStartRecorded: true,
+		}),
+		Entry("No limit", testCaseProvisioningTimeout{
+			Timeout:             &metav1.Duration{},
+			StartedAgo:          24 * time.Hour,
+			HostState:           bmh.StateProvisioning,
+			ExpectStartRecorded: true,
+		}),
+		Entry("Provisioned before the timeout", testCaseProvisioningTimeout{
+			Timeout:           &metav1.Duration{Duration: time.Hour},
+			StartedAgo:        time.Minute,
+			HostState:         bmh.StateProvisioned,
+			ExpectProvisioned: true,
+		}),
+	)
+})
+
+var _ = Describe("BareMetalMachine provisioning retries", func() {
+
+	DescribeTable("Test provisioningRetryDelay",
+		func(attempt int, expected time.Duration) {
+			Expect(provisioningRetryDelay(attempt)).To(Equal(expected))
+		},
+		Entry("First retry", 1, ProvisioningRetryBaseDelay),
+		Entry("Second retry", 2, 2*ProvisioningRetryBaseDelay),
+		Entry("Third retry", 3, 4*ProvisioningRetryBaseDelay),
+		Entry("Capped", 10, ProvisioningRetryMaxDelay),
+	)
+
+	It("Retries up to MaxProvisioningRetries then sets Failed", func() {
+		host := newBareMetalHost("myhost", bmhSpecNoImg(), bmh.StateProvisioning,
+			bmhStatus(), false, false,
+		)
+		spec := bmmSecret()
+		spec.MaxProvisioningRetries = 2
+		bmMachine := newBareMetalMachine("mybmmachine", nil, spec, nil,
+			bmmObjectMetaWithValidAnnotations(),
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+		machineMgr, err := NewMachineManager(c, nil, nil,
+			newMachine("mymachine", "", nil), bmMachine, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		getHost := func() *bmh.BareMetalHost {
+			savedHost := &bmh.BareMetalHost{}
+			Expect(c.Get(context.TODO(), client.ObjectKey{
+				Name: host.Name, Namespace: host.Namespace,
+			}, savedHost)).To(Succeed())
+			return savedHost
+		}
+		// failProvisioning has the host report a provisioning error
+		failProvisioning := func() {
+			failedHost := getHost()
+			failedHost.Status.Provisioning.State = bmh.StateProvisioning
+			failedHost.Status.ErrorType = bmh.ProvisioningError
+			failedHost.Status.ErrorMessage = "Image provisioning failed"
+			Expect(c.Update(context.TODO(), failedHost)).To(Succeed())
+		}
+		// retry has the host deprovisioned once the backoff delay is elapsed,
+		// and the reconciliation provisions it again
+		retry := func() {
+			past := metav1.NewTime(time.Now().Add(-time.Second))
+			bmMachine.Status.ProvisioningRetry.NextRetryTime = &past
+			readyHost := getHost()
+			readyHost.Status.Provisioning.State = bmh.StateReady
+			readyHost.Status.ErrorType = ""
+			readyHost.Status.ErrorMessage = ""
+			Expect(c.Update(context.TODO(), readyHost)).To(Succeed())
+			_, err := machineMgr.GetBaremetalHostID(context.TODO())
+			_, ok := err.(*RequeueAfterError)
+			Expect(ok).To(BeTrue())
+			Expect(getHost().Spec.Image).NotTo(BeNil())
+			Expect(bmMachine.Status.ProvisioningRetry.NextRetryTime).To(BeNil())
+		}
+
+		for attempt := 1; attempt <= 2; attempt++ {
+			failProvisioning()
+			_, err = machineMgr.GetBaremetalHostID(context.TODO())
+			requeueErr, ok := err.(*RequeueAfterError)
+			Expect(ok).To(BeTrue())
+			Expect(requeueErr.RequeueAfter).To(Equal(provisioningRetryDelay(attempt)))
+			Expect(bmMachine.Status.ProvisioningRetry.Attempts).To(Equal(attempt))
+			Expect(bmMachine.Status.ProvisioningRetry.NextRetryTime).NotTo(BeNil())
+			Expect(getHost().Spec.Image).To(BeNil())
+			Expect(bmMachine.Status.Phase).NotTo(Equal(capm3.BareMetalMachinePhaseFailed))
+
+			// Nothing is done until the backoff delay is elapsed
+			_, err = machineMgr.GetBaremetalHostID(context.TODO())
+			_, ok = err.(*RequeueAfterError)
+			Expect(ok).To(BeTrue())
+			Expect(getHost().Spec.Image).To(BeNil())
+			retry()
+		}
+
+		// The retries are exhausted
+		failProvisioning()
+		_, err = machineMgr.GetBaremetalHostID(context.TODO())
+		_, ok := err.(*RequeueAfterError)
+		Expect(ok).To(BeTrue())
+		Expect(bmMachine.Status.Phase).To(Equal(capm3.BareMetalMachinePhaseFailed))
+		Expect(*bmMachine.Status.FailureReason).To(Equal(capierrors.CreateMachineError))
+		Expect(*bmMachine.Status.FailureMessage).To(ContainSubstring("after 2 retries"))
+		Expect(getHost().Spec.Image).NotTo(BeNil())
+	})
+
+	It("Leaves the failure to the host without retries", func() {
+		host := newBareMetalHost("myhost", bmhSpecNoImg(), bmh.StateProvisioning,
+			bmhStatus(), false, false,
+		)
+		host.Status.ErrorType = bmh.ProvisioningError
+		bmMachine := newBareMetalMachine("mybmmachine", nil, bmmSecret(), nil,
+			bmmObjectMetaWithValidAnnotations(),
+		)
+		c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+		machineMgr, err := NewMachineManager(c, nil, nil,
+			newMachine("mymachine", "", nil), bmMachine, klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = machineMgr.GetBaremetalHostID(context.TODO())
+		_, ok := err.(*RequeueAfterError)
+		Expect(ok).To(BeTrue())
+		Expect(bmMachine.Status.Phase).To(Equal(capm3.BareMetalMachinePhaseProvisioning))
+		Expect(bmMachine.Status.FailureReason).To(BeNil())
+		Expect(bmMachine.Status.ProvisioningRetry).To(BeNil())
+	})
+})
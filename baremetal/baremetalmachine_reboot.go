@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RebootHost requests the baremetal operator to reboot the host of the
+// BareMetalMachine, by setting the RebootAnnotation on it. The reason and
+// time of the request are recorded in Status.LastReboot. Nothing is done if
+// a reboot is already pending, the recorded request is then left as is.
+func (m *MachineManager) RebootHost(ctx context.Context, reason string) error {
+	host, err := m.getHost(ctx)
+	if err != nil {
+		return err
+	}
+	if host == nil {
+		return fmt.Errorf("host not found for machine %s", m.BareMetalMachine.Name)
+	}
+
+	if _, ok := host.Annotations[RebootAnnotation]; ok {
+		m.Log.Info("Reboot already pending", "host", host.Name)
+		return nil
+	}
+	return m.requestReboot(ctx, host, reason)
+}
+
+// requestReboot sets the RebootAnnotation on the host and records the
+// request in Status.LastReboot.
+func (m *MachineManager) requestReboot(ctx context.Context, host *bmh.BareMetalHost,
+	reason string,
+) error {
+	if host.Annotations == nil {
+		host.Annotations = map[string]string{}
+	}
+	host.Annotations[RebootAnnotation] = ""
+	if err := m.client.Update(ctx, host); err != nil {
+		return err
+	}
+
+	now := metav1.Now()
+	m.BareMetalMachine.Status.LastReboot = &capm3.RebootStatus{
+		Reason:      reason,
+		RequestTime: &now,
+	}
+	m.Log.Info("Reboot requested", "host", host.Name, "reason", reason)
+	return nil
+}
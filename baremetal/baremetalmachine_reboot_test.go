@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalMachine host reboot", func() {
+
+	var host *bmh.BareMetalHost
+	var bmMachine *capm3.BareMetalMachine
+	var c client.Client
+	var machineMgr *MachineManager
+
+	BeforeEach(func() {
+		host = &bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "myhost",
+				Namespace: "myns",
+			},
+		}
+		bmMachine = newBareMetalMachine("mybmmachine", nil, nil, nil,
+			bmmObjectMetaWithValidAnnotations(),
+		)
+		c = fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+		var err error
+		machineMgr, err = NewMachineManager(c, nil, nil, nil, bmMachine,
+			klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	getHost := func() bmh.BareMetalHost {
+		savedHost := bmh.BareMetalHost{}
+		Expect(c.Get(context.TODO(),
+			client.ObjectKey{Name: host.Name, Namespace: host.Namespace},
+			&savedHost,
+		)).To(Succeed())
+		return savedHost
+	}
+
+	It("Requests a reboot and records the reason", func() {
+		Expect(machineMgr.RebootHost(context.TODO(), "KernelUpgrade")).To(Succeed())
+
+		Expect(getHost().Annotations).To(HaveKey(RebootAnnotation))
+		Expect(bmMachine.Status.LastReboot).NotTo(BeNil())
+		Expect(bmMachine.Status.LastReboot.Reason).To(Equal("KernelUpgrade"))
+		Expect(bmMachine.Status.LastReboot.RequestTime).NotTo(BeNil())
+	})
+
+	It("Does nothing while a reboot is pending", func() {
+		Expect(machineMgr.RebootHost(context.TODO(), "KernelUpgrade")).To(Succeed())
+		first := bmMachine.Status.LastReboot.DeepCopy()
+		resourceVersion := getHost().ResourceVersion
+
+		Expect(machineMgr.RebootHost(context.TODO(), "Maintenance")).To(Succeed())
+
+		Expect(getHost().ResourceVersion).To(Equal(resourceVersion))
+		Expect(bmMachine.Status.LastReboot).To(Equal(first))
+	})
+
+	It("Fails without a host", func() {
+		Expect(c.Delete(context.TODO(), host)).To(Succeed())
+
+		Expect(machineMgr.RebootHost(context.TODO(), "KernelUpgrade")).NotTo(Succeed())
+		Expect(bmMachine.Status.LastReboot).To(BeNil())
+	})
+})
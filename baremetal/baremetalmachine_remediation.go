@@ -0,0 +1,172 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/metal3-io/cluster-api-provider-baremetal/featuregate"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// RemediationRequestedAnnotation is set on a BareMetalMachine, e.g. by a
+	// MachineHealthCheck, while its node is unhealthy and should be
+	// remediated. It is expected to be removed once the node is healthy.
+	RemediationRequestedAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/remediation-requested"
+	// RebootAnnotation is the BareMetalHost annotation requesting the
+	// baremetal operator to reboot the host.
+	RebootAnnotation = "reboot.metal3.io"
+	// MaxRemediationReboots is the number of reboots attempted before the
+	// host is reprovisioned.
+	MaxRemediationReboots = 3
+	// RemediationRebootInterval is the delay given to a rebooted host to
+	// become healthy before it is rebooted again.
+	RemediationRebootInterval = 5 * time.Minute
+
+	remediationReasonRebooting      = "Rebooting"
+	remediationReasonReprovisioning = "Reprovisioning"
+
+	// rebootReasonRemediation is the reason recorded for the reboots of the
+	// remediation.
+	rebootReasonRemediation = "Remediation"
+)
+
+// Remediate reacts to the RemediationRequestedAnnotation on the
+// BareMetalMachine. The host is rebooted, up to MaxRemediationReboots times
+// spaced by RemediationRebootInterval, and then reprovisioned. The
+// RemediationInProgress condition is set meanwhile, and cleared once the
+// annotation is removed. The annotation is ignored while the HostRemediation
+// feature gate is disabled.
+func (m *MachineManager) Remediate(ctx context.Context) error {
+	_, requested := m.BareMetalMachine.Annotations[RemediationRequestedAnnotation]
+	if !requested || !featuregate.Enabled(featuregate.HostRemediation) {
+		if m.BareMetalMachine.Status.Conditions.Get(capm3.RemediationInProgressCondition) != nil {
+			m.Log.Info("Remediation completed")
+		}
+		m.BareMetalMachine.Status.Conditions.Remove(capm3.RemediationInProgressCondition)
+		m.BareMetalMachine.Status.Remediation = nil
+		return nil
+	}
+
+	host, err := m.getHost(ctx)
+	if err != nil {
+		return err
+	}
+	if host == nil {
+		return fmt.Errorf("host not found for machine %s", m.BareMetalMachine.Name)
+	}
+
+	if m.isReprovisioning() {
+		// Nothing left to try, wait for the host to come back
+		return nil
+	}
+
+	if _, ok := host.Annotations[RebootAnnotation]; ok {
+		// The previous reboot was not processed yet
+		return &RequeueAfterError{RequeueAfter: requeueAfter}
+	}
+
+	remediation := m.BareMetalMachine.Status.Remediation
+	if remediation == nil {
+		remediation = &capm3.RemediationStatus{}
+		m.BareMetalMachine.Status.Remediation = remediation
+	}
+
+	if remediation.LastRebootTime != nil {
+		wait := time.Until(remediation.LastRebootTime.Add(RemediationRebootInterval))
+		if wait > 0 {
+			return &RequeueAfterError{RequeueAfter: wait}
+		}
+	}
+
+	if remediation.RebootCount >= MaxRemediationReboots {
+		return m.reprovisionHost(ctx, host)
+	}
+	return m.rebootHost(ctx, host, remediation)
+}
+
+// rebootHost requests a reboot of the host to the baremetal operator.
+func (m *MachineManager) rebootHost(ctx context.Context, host *bmh.BareMetalHost,
+	remediation *capm3.RemediationStatus,
+) error {
+	if err := m.requestReboot(ctx, host, rebootReasonRemediation); err != nil {
+		return err
+	}
+
+	remediation.RebootCount++
+	remediation.LastRebootTime = m.BareMetalMachine.Status.LastReboot.RequestTime.DeepCopy()
+	m.BareMetalMachine.Status.Conditions.Set(capm3.Condition{
+		Type:   capm3.RemediationInProgressCondition,
+		Status: corev1.ConditionTrue,
+		Reason: remediationReasonRebooting,
+		Message: fmt.Sprintf("Reboot %d of %d requested",
+			remediation.RebootCount, MaxRemediationReboots,
+		),
+	})
+	m.Log.Info("Rebooting host for remediation", "host", host.Name,
+		"reboots", remediation.RebootCount,
+	)
+	return &RequeueAfterError{RequeueAfter: RemediationRebootInterval}
+}
+
+// reprovisionHost removes the image from the host, so that it is
+// deprovisioned. The image is set again by Update once the host is ready.
+func (m *MachineManager) reprovisionHost(ctx context.Context, host *bmh.BareMetalHost) error {
+	host.Spec.Image = nil
+	if err := m.client.Update(ctx, host); err != nil {
+		return err
+	}
+
+	m.BareMetalMachine.Status.Conditions.Set(capm3.Condition{
+		Type:   capm3.RemediationInProgressCondition,
+		Status: corev1.ConditionTrue,
+		Reason: remediationReasonReprovisioning,
+		Message: fmt.Sprintf("Host still unhealthy after %d reboots",
+			MaxRemediationReboots,
+		),
+	})
+	m.Log.Info("Reprovisioning host for remediation", "host", host.Name)
+	return nil
+}
+
+// isReprovisioning returns true if the remediation escalated to the
+// reprovisioning of the host.
+func (m *MachineManager) isReprovisioning() bool {
+	condition := m.BareMetalMachine.Status.Conditions.Get(
+		capm3.RemediationInProgressCondition,
+	)
+	return condition != nil && condition.Reason == remediationReasonReprovisioning
+}
+
+// waitingForDeprovisioning returns true if the host was asked to deprovision
+// for remediation or on request, and has not reached the ready state yet.
+func (m *MachineManager) waitingForDeprovisioning(host *bmh.BareMetalHost) bool {
+	if !m.isReprovisioning() &&
+		!m.BareMetalMachine.Status.Conditions.IsTrue(capm3.ReprovisioningCondition) {
+		return false
+	}
+	switch host.Status.Provisioning.State {
+	case bmh.StateReady, bmh.StateAvailable:
+		return false
+	}
+	return true
+}
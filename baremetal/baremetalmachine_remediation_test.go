@@ -0,0 +1,210 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/metal3-io/cluster-api-provider-baremetal/featuregate"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalMachine remediation", func() {
+
+	type testCaseRemediate struct {
+		RemediationRequested bool
+		RemediationDisabled  bool
+		HostAnnotations      map[string]string
+		Status               capm3.BareMetalMachineStatus
+		ExpectRequeue        bool
+		ExpectReboot         bool
+		ExpectReprovision    bool
+		ExpectedRebootCount  int
+		ExpectedReason       string
+	}
+
+	DescribeTable("Test Remediate",
+		func(tc testCaseRemediate) {
+			defer func(enabled bool) {
+				_ = featuregate.Gates.SetFromMap(map[string]bool{
+					string(featuregate.HostRemediation): enabled,
+				})
+			}(featuregate.Enabled(featuregate.HostRemediation))
+			Expect(featuregate.Gates.SetFromMap(map[string]bool{
+				string(featuregate.HostRemediation): !tc.RemediationDisabled,
+			})).To(Succeed())
+
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "myhost",
+					Namespace:   "myns",
+					Annotations: tc.HostAnnotations,
+				},
+				Spec: bmh.BareMetalHostSpec{
+					Image: &bmh.Image{URL: testImageURL},
+				},
+			}
+			objMeta := bmmObjectMetaWithValidAnnotations()
+			if tc.RemediationRequested {
+				objMeta.Annotations[RemediationRequestedAnnotation] = ""
+			}
+			bmMachine := newBareMetalMachine("mybmmachine", nil, nil,
+				&tc.Status, objMeta,
+			)
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, bmMachine,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = machineMgr.Remediate(context.TODO())
+			if tc.ExpectRequeue {
+				Expect(err).To(BeAssignableToTypeOf(&RequeueAfterError{}))
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			savedHost := bmh.BareMetalHost{}
+			Expect(c.Get(context.TODO(), client.ObjectKey{
+				Name: "myhost", Namespace: "myns",
+			}, &savedHost)).To(Succeed())
+			_, rebootPending := tc.HostAnnotations[RebootAnnotation]
+			_, rebootRequested := savedHost.Annotations[RebootAnnotation]
+			Expect(rebootRequested).To(Equal(tc.ExpectReboot || rebootPending))
+			Expect(savedHost.Spec.Image == nil).To(Equal(tc.ExpectReprovision))
+
+			condition := bmMachine.Status.Conditions.Get(
+				capm3.RemediationInProgressCondition,
+			)
+			if tc.ExpectedReason == "" {
+				Expect(condition).To(BeNil())
+				Expect(bmMachine.Status.Remediation).To(BeNil())
+			} else {
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+				Expect(condition.Reason).To(Equal(tc.ExpectedReason))
+				Expect(bmMachine.Status.Remediation.RebootCount).
+					To(Equal(tc.ExpectedRebootCount))
+			}
+		},
+		Entry("No remediation requested", testCaseRemediate{}),
+		Entry("Remediation completed, condition cleared", testCaseRemediate{
+			Status: capm3.BareMetalMachineStatus{
+				Conditions: capm3.Conditions{{
+					Type:   capm3.RemediationInProgressCondition,
+					Status: corev1.ConditionTrue,
+					Reason: remediationReasonRebooting,
+				}},
+				Remediation: &capm3.RemediationStatus{RebootCount: 1},
+			},
+		}),
+		Entry("Remediation requested, first reboot", testCaseRemediate{
+			RemediationRequested: true,
+			ExpectRequeue:        true,
+			ExpectReboot:         true,
+			ExpectedRebootCount:  1,
+			ExpectedReason:       remediationReasonRebooting,
+		}),
+		Entry("Remediation requested, gate disabled", testCaseRemediate{
+			RemediationRequested: true,
+			RemediationDisabled:  true,
+		}),
+		Entry("Remediation requested, reboot still pending", testCaseRemediate{
+			RemediationRequested: true,
+			HostAnnotations:      map[string]string{RebootAnnotation: ""},
+			Status:               rebootingStatus(1, time.Minute),
+			ExpectRequeue:        true,
+			ExpectedRebootCount:  1,
+			ExpectedReason:       remediationReasonRebooting,
+		}),
+		Entry("Remediation requested, rebooted recently", testCaseRemediate{
+			RemediationRequested: true,
+			Status:               rebootingStatus(1, time.Minute),
+			ExpectRequeue:        true,
+			ExpectedRebootCount:  1,
+			ExpectedReason:       remediationReasonRebooting,
+		}),
+		Entry("Remediation requested, second reboot", testCaseRemediate{
+			RemediationRequested: true,
+			Status: rebootingStatus(1,
+				RemediationRebootInterval+time.Minute,
+			),
+			ExpectRequeue:       true,
+			ExpectReboot:        true,
+			ExpectedRebootCount: 2,
+			ExpectedReason:      remediationReasonRebooting,
+		}),
+		Entry("Remediation requested, reboots exhausted", testCaseRemediate{
+			RemediationRequested: true,
+			Status: rebootingStatus(MaxRemediationReboots,
+				RemediationRebootInterval+time.Minute,
+			),
+			ExpectReprovision:   true,
+			ExpectedRebootCount: MaxRemediationReboots,
+			ExpectedReason:      remediationReasonReprovisioning,
+		}),
+	)
+
+	It("Does not provision the host again while it deprovisions", func() {
+		bmMachine := newBareMetalMachine("mybmmachine", nil, nil,
+			&capm3.BareMetalMachineStatus{
+				Conditions: capm3.Conditions{{
+					Type:   capm3.RemediationInProgressCondition,
+					Status: corev1.ConditionTrue,
+					Reason: remediationReasonReprovisioning,
+				}},
+			}, nil,
+		)
+		machineMgr, err := NewMachineManager(nil, nil, nil, nil, bmMachine,
+			klogr.New(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		host := &bmh.BareMetalHost{}
+		host.Status.Provisioning.State = bmh.StateDeprovisioning
+		Expect(machineMgr.waitingForDeprovisioning(host)).To(BeTrue())
+		host.Status.Provisioning.State = bmh.StateReady
+		Expect(machineMgr.waitingForDeprovisioning(host)).To(BeFalse())
+	})
+})
+
+func rebootingStatus(rebootCount int, sinceLastReboot time.Duration) capm3.BareMetalMachineStatus {
+	lastReboot := metav1.NewTime(time.Now().Add(-sinceLastReboot))
+	return capm3.BareMetalMachineStatus{
+		Conditions: capm3.Conditions{{
+			Type:   capm3.RemediationInProgressCondition,
+			Status: corev1.ConditionTrue,
+			Reason: remediationReasonRebooting,
+		}},
+		Remediation: &capm3.RemediationStatus{
+			RebootCount:    rebootCount,
+			LastRebootTime: &lastReboot,
+		},
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ReprovisionRequestedAnnotation is set on a BareMetalMachine to have its
+	// host deprovisioned and provisioned again with the current Image. It is
+	// removed once the host is provisioned again.
+	ReprovisionRequestedAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/reprovision-requested"
+
+	reprovisionReasonDeprovisioning = "Deprovisioning"
+	reprovisionReasonProvisioning   = "Provisioning"
+)
+
+// Reprovision reacts to the ReprovisionRequestedAnnotation on the
+// BareMetalMachine. The image is removed from the host, so that it is
+// deprovisioned, and is set again by Update once the host is ready. The
+// Reprovisioning condition is set meanwhile, and the annotation and the
+// condition are removed once the host is provisioned again. Nothing is done
+// while the Machine is being deleted.
+func (m *MachineManager) Reprovision(ctx context.Context) error {
+	condition := m.BareMetalMachine.Status.Conditions.Get(capm3.ReprovisioningCondition)
+	if _, ok := m.BareMetalMachine.Annotations[ReprovisionRequestedAnnotation]; !ok && condition == nil {
+		return nil
+	}
+
+	if m.Machine != nil && !m.Machine.DeletionTimestamp.IsZero() {
+		m.Log.Info("Machine is being deleted, not reprovisioning the host")
+		return nil
+	}
+
+	host, err := m.getHost(ctx)
+	if err != nil {
+		return err
+	}
+	if host == nil {
+		return fmt.Errorf("host not found for machine %s", m.BareMetalMachine.Name)
+	}
+
+	if condition == nil {
+		if m.isReprovisioning() {
+			// The host is already reprovisioned for remediation
+			return &RequeueAfterError{RequeueAfter: requeueAfter}
+		}
+		return m.deprovisionHost(ctx, host)
+	}
+
+	switch host.Status.Provisioning.State {
+	case bmh.StateReady, bmh.StateAvailable:
+		if condition.Reason == reprovisionReasonDeprovisioning {
+			m.BareMetalMachine.Status.Conditions.Set(capm3.Condition{
+				Type:    capm3.ReprovisioningCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  reprovisionReasonProvisioning,
+				Message: "Host deprovisioned, provisioning it again",
+			})
+		}
+	case bmh.StateProvisioned:
+		if host.Spec.Image != nil && condition.Reason == reprovisionReasonProvisioning {
+			delete(m.BareMetalMachine.Annotations, ReprovisionRequestedAnnotation)
+			m.BareMetalMachine.Status.Conditions.Remove(capm3.ReprovisioningCondition)
+			m.Log.Info("Reprovisioning completed", "host", host.Name)
+			return nil
+		}
+	}
+	return &RequeueAfterError{RequeueAfter: requeueAfter}
+}
+
+// deprovisionHost removes the image from the host and sets the
+// Reprovisioning condition.
+func (m *MachineManager) deprovisionHost(ctx context.Context, host *bmh.BareMetalHost) error {
+	host.Spec.Image = nil
+	if err := m.client.Update(ctx, host); err != nil {
+		return err
+	}
+
+	m.BareMetalMachine.Status.Conditions.Set(capm3.Condition{
+		Type:    capm3.ReprovisioningCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  reprovisionReasonDeprovisioning,
+		Message: "Reprovisioning requested",
+	})
+	m.Log.Info("Reprovisioning host on request", "host", host.Name)
+	return &RequeueAfterError{RequeueAfter: requeueAfter}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalMachine reprovisioning", func() {
+
+	reprovisioningStatus := func(reason string) capm3.BareMetalMachineStatus {
+		return capm3.BareMetalMachineStatus{
+			Conditions: capm3.Conditions{{
+				Type:   capm3.ReprovisioningCondition,
+				Status: corev1.ConditionTrue,
+				Reason: reason,
+			}},
+		}
+	}
+
+	type testCaseReprovision struct {
+		ReprovisionRequested bool
+		MachineDeleting      bool
+		HostState            bmh.ProvisioningState
+		Status               capm3.BareMetalMachineStatus
+		ExpectRequeue        bool
+		ExpectDeprovision    bool
+		ExpectAnnotation     bool
+		ExpectedReason       string
+	}
+
+	DescribeTable("Test Reprovision",
+		func(tc testCaseReprovision) {
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "myhost",
+					Namespace: "myns",
+				},
+				Spec: bmh.BareMetalHostSpec{
+					Image: &bmh.Image{URL: testImageURL},
+				},
+				Status: bmh.BareMetalHostStatus{
+					Provisioning: bmh.ProvisionStatus{State: tc.HostState},
+				},
+			}
+			objMeta := bmmObjectMetaWithValidAnnotations()
+			if tc.ReprovisionRequested {
+				objMeta.Annotations[ReprovisionRequestedAnnotation] = ""
+			}
+			bmMachine := newBareMetalMachine("mybmmachine", nil, nil,
+				&tc.Status, objMeta,
+			)
+			machine := newMachine("mymachine", "mybmmachine", nil)
+			if tc.MachineDeleting {
+				now := metav1.Now()
+				machine.DeletionTimestamp = &now
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), host)
+
+			machineMgr, err := NewMachineManager(c, nil, nil, machine, bmMachine,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = machineMgr.Reprovision(context.TODO())
+			if tc.ExpectRequeue {
+				Expect(err).To(BeAssignableToTypeOf(&RequeueAfterError{}))
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			savedHost := bmh.BareMetalHost{}
+			Expect(c.Get(context.TODO(), client.ObjectKey{
+				Name: "myhost", Namespace: "myns",
+			}, &savedHost)).To(Succeed())
+			Expect(savedHost.Spec.Image == nil).To(Equal(tc.ExpectDeprovision))
+
+			_, annotated := bmMachine.Annotations[ReprovisionRequestedAnnotation]
+			Expect(annotated).To(Equal(tc.ExpectAnnotation))
+
+			condition := bmMachine.Status.Conditions.Get(
+				capm3.ReprovisioningCondition,
+			)
+			if tc.ExpectedReason == "" {
+				Expect(condition).To(BeNil())
+			} else {
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+				Expect(condition.Reason).To(Equal(tc.ExpectedReason))
+			}
+		},
+		Entry("No reprovisioning requested", testCaseReprovision{
+			HostState: bmh.StateProvisioned,
+		}),
+		Entry("Reprovisioning requested", testCaseReprovision{
+			ReprovisionRequested: true,
+			HostState:            bmh.StateProvisioned,
+			ExpectRequeue:        true,
+			ExpectDeprovision:    true,
+			ExpectAnnotation:     true,
+			ExpectedReason:       reprovisionReasonDeprovisioning,
+		}),
+		Entry("Reprovisioning requested, Machine being deleted",
+			testCaseReprovision{
+				ReprovisionRequested: true,
+				MachineDeleting:      true,
+				HostState:            bmh.StateProvisioned,
+				ExpectAnnotation:     true,
+			},
+		),
+		Entry("Reprovisioning requested during a remediation",
+			testCaseReprovision{
+				ReprovisionRequested: true,
+				HostState:            bmh.StateDeprovisioning,
+				Status: capm3.BareMetalMachineStatus{
+					Conditions: capm3.Conditions{{
+						Type:   capm3.RemediationInProgressCondition,
+						Status: corev1.ConditionTrue,
+						Reason: remediationReasonReprovisioning,
+					}},
+				},
+				ExpectRequeue:    true,
+				ExpectAnnotation: true,
+			},
+		),
+		Entry("Host still deprovisioning", testCaseReprovision{
+			ReprovisionRequested: true,
+			HostState:            bmh.StateDeprovisioning,
+			Status:               reprovisioningStatus(reprovisionReasonDeprovisioning),
+			ExpectRequeue:        true,
+			ExpectAnnotation:     true,
+			ExpectedReason:       reprovisionReasonDeprovisioning,
+		}),
+		Entry("Host deprovisioned", testCaseReprovision{
+			ReprovisionRequested: true,
+			HostState:            bmh.StateReady,
+			Status:               reprovisioningStatus(reprovisionReasonDeprovisioning),
+			ExpectRequeue:        true,
+			ExpectAnnotation:     true,
+			ExpectedReason:       reprovisionReasonProvisioning,
+		}),
+		Entry("Host provisioned again, annotation cleared", testCaseReprovision{
+			ReprovisionRequested: true,
+			HostState:            bmh.StateProvisioned,
+			Status:               reprovisioningStatus(reprovisionReasonProvisioning),
+		}),
+	)
+
+	It("Does not set the image while the host deprovisions", func() {
+		host := &bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "myhost",
+				Namespace: "myns",
+			},
+			Status: bmh.BareMetalHostStatus{
+				Provisioning: bmh.ProvisionStatus{State: bmh.StateDeprovisioning},
+			},
+		}
+		status := reprovisioningStatus(reprovisionReasonDeprovisioning)
+		machineMgr := &MachineManager{BareMetalMachine: newBareMetalMachine(
+			"mybmmachine", nil, nil, &status, nil,
+		)}
+		Expect(machineMgr.waitingForDeprovisioning(host)).To(BeTrue())
+
+		host.Status.Provisioning.State = bmh.StateReady
+		Expect(machineMgr.waitingForDeprovisioning(host)).To(BeFalse())
+	})
+})
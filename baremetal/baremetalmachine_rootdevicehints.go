@@ -0,0 +1,42 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"encoding/json"
+)
+
+const (
+	// RootDeviceHintsAnnotation is the key for an annotation set on a
+	// BareMetalHost to pass the root device hints of the BareMetalMachine, as
+	// JSON.
+	RootDeviceHintsAnnotation = "baremetalmachine.infrastructure.cluster.x-k8s.io/root-device-hints"
+)
+
+// rootDeviceHints returns the JSON encoded root device hints of the machine,
+// or an empty string if there are none.
+func (m *MachineManager) rootDeviceHints() (string, error) {
+	hints := m.BareMetalMachine.Spec.RootDeviceHints
+	if hints == nil || hints.IsEmpty() {
+		return "", nil
+	}
+	encoded, err := json.Marshal(hints)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ImageSignatureKeySecretKey is the key of the Secret referenced by
+	// Image.SignatureKeyRef holding the armored OpenPGP public keys.
+	ImageSignatureKeySecretKey = "key"
+
+	// imageSignatureTimeout bounds each download of a signature or checksum.
+	imageSignatureTimeout = 30 * time.Second
+	// maxImageSignatureSize bounds the size of a downloaded signature or
+	// checksum document.
+	maxImageSignatureSize = 1 << 20
+)
+
+// imageSignatureClient downloads the image signatures and checksums.
+var imageSignatureClient = &http.Client{Timeout: imageSignatureTimeout}
+
+// verifyImageSignature checks the detached OpenPGP signature of the image
+// checksum against the trusted keys, when a SignatureURL is set. The signed
+// content is the document Checksum points to if it is a URL, the Checksum
+// itself otherwise.
+func (m *MachineManager) verifyImageSignature(ctx context.Context) error {
+	image := m.BareMetalMachine.Spec.Image
+	if image.SignatureURL == "" {
+		return nil
+	}
+	if image.SignatureKeyRef == nil {
+		return errors.New("image signature key is not set")
+	}
+
+	keyring, err := m.imageSignatureKeyring(ctx, image.SignatureKeyRef)
+	if err != nil {
+		return err
+	}
+
+	signature, err := downloadImageSignatureData(ctx, image.SignatureURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to download the image signature")
+	}
+
+	signed := []byte(image.Checksum)
+	if u, err := url.Parse(image.Checksum); err == nil && u.Scheme != "" {
+		signed, err = downloadImageSignatureData(ctx, image.Checksum)
+		if err != nil {
+			return errors.Wrap(err, "failed to download the image checksum")
+		}
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("-----BEGIN")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring,
+			bytes.NewReader(signed), bytes.NewReader(signature),
+		)
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyring,
+			bytes.NewReader(signed), bytes.NewReader(signature),
+		)
+	}
+	if err != nil {
+		return errors.Wrap(err, "invalid image signature")
+	}
+	m.Log.Info("Verified the image signature", "signature", image.SignatureURL)
+	return nil
+}
+
+// imageSignatureKeyring reads the trusted public keys from the referenced
+// Secret. The Namespace defaults to, and must be, the one of the
+// BareMetalMachine, so that a machine cannot borrow the keys of another
+// namespace.
+func (m *MachineManager) imageSignatureKeyring(ctx context.Context,
+	ref *corev1.SecretReference) (openpgp.EntityList, error) {
+
+	namespace := m.BareMetalMachine.Namespace
+	if ref.Namespace != "" && ref.Namespace != namespace {
+		return nil, errors.Errorf(
+			"image signature key Secret %s/%s is not in the namespace of the BareMetalMachine",
+			ref.Namespace, ref.Name,
+		)
+	}
+	secret := corev1.Secret{}
+	key := client.ObjectKey{Name: ref.Name, Namespace: namespace}
+	if err := m.client.Get(ctx, key, &secret); err != nil {
+		return nil, errors.Wrapf(err,
+			"failed to get the image signature key Secret %s/%s",
+			namespace, ref.Name,
+		)
+	}
+
+	data, ok := secret.Data[ImageSignatureKeySecretKey]
+	if !ok {
+		return nil, errors.Errorf("image signature key Secret %s/%s has no %s",
+			namespace, ref.Name, ImageSignatureKeySecretKey,
+		)
+	}
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"invalid keys in the image signature key Secret %s/%s",
+			namespace, ref.Name,
+		)
+	}
+	return keyring, nil
+}
+
+// downloadImageSignatureData returns the body of a GET request on rawURL,
+// which must be an http(s) URL. Bodies larger than maxImageSignatureSize are
+// rejected.
+func downloadImageSignatureData(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("%s is not an http(s) URL", rawURL)
+	}
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := imageSignatureClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("GET %s returned %s", rawURL, resp.Status)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxImageSignatureSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImageSignatureSize {
+		return nil, errors.Errorf("GET %s returned more than %d bytes",
+			rawURL, maxImageSignatureSize,
+		)
+	}
+	return data, nil
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/packet"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BareMetalMachine image signature", func() {
+
+	const checksum = "97830b21ed272a3d854615beb54cf004"
+	const checksumDocument = checksum + "  centos.qcow2\n"
+
+	config := &packet.Config{RSABits: 1024}
+	// The keys are needed to build the table entries, before Expect can
+	// be used
+	newSigner := func() *openpgp.Entity {
+		entity, err := openpgp.NewEntity("signer", "", "signer@example.com", config)
+		if err != nil {
+			panic(err)
+		}
+		return entity
+	}
+	armoredPublicKey := func(entity *openpgp.Entity) []byte {
+		buf := bytes.Buffer{}
+		w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entity.Serialize(w)).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		return buf.Bytes()
+	}
+	sign := func(entity *openpgp.Entity, content string, armored bool) []byte {
+		buf := bytes.Buffer{}
+		if armored {
+			Expect(openpgp.ArmoredDetachSign(&buf, entity,
+				strings.NewReader(content), config,
+			)).To(Succeed())
+		} else {
+			Expect(openpgp.DetachSign(&buf, entity,
+				strings.NewReader(content), config,
+			)).To(Succeed())
+		}
+		return buf.Bytes()
+	}
+
+	trusted := newSigner()
+	untrusted := newSigner()
+
+	type testCaseSignature struct {
+		Signer             *openpgp.Entity
+		SignedContent      string
+		Armored            bool
+		ChecksumIsURL      bool
+		ChecksumURL        string
+		OversizedSignature bool
+		NoSignature        bool
+		NoKeySecret        bool
+		KeyNamespace       string
+		ExpectError        bool
+		ExpectErrorText    string
+	}
+
+	DescribeTable("Test verifyImageSignature",
+		func(tc testCaseSignature) {
+			server := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					switch r.URL.Path {
+					case "/centos.qcow2.md5sum":
+						_, _ = w.Write([]byte(checksumDocument))
+					case "/centos.qcow2.md5sum.sig":
+						if tc.OversizedSignature {
+							_, _ = w.Write(make([]byte, maxImageSignatureSize+1))
+							return
+						}
+						_, _ = w.Write(sign(tc.Signer, tc.SignedContent, tc.Armored))
+					default:
+						w.WriteHeader(http.StatusNotFound)
+					}
+				},
+			))
+			defer server.Close()
+
+			image := capm3.Image{
+				URL:      server.URL + "/centos.qcow2",
+				Checksum: checksum,
+			}
+			if tc.ChecksumIsURL {
+				image.Checksum = server.URL + "/centos.qcow2.md5sum"
+				image.ChecksumType = capm3.ChecksumTypeMD5
+			}
+			if tc.ChecksumURL != "" {
+				image.Checksum = tc.ChecksumURL
+				image.ChecksumType = capm3.ChecksumTypeMD5
+			}
+			if !tc.NoSignature {
+				image.SignatureURL = server.URL + "/centos.qcow2.md5sum.sig"
+				image.SignatureKeyRef = &corev1.SecretReference{
+					Name:      "image-signing-key",
+					Namespace: tc.KeyNamespace,
+				}
+			}
+			bmMachine := newBareMetalMachine("mybmmachine", nil,
+				&capm3.BareMetalMachineSpec{Image: image}, nil, nil,
+			)
+			keyNamespace := bmMachine.Namespace
+			if tc.KeyNamespace != "" {
+				keyNamespace = tc.KeyNamespace
+			}
+			objects := []runtime.Object{}
+			if !tc.NoKeySecret {
+				objects = append(objects, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "image-signing-key",
+						Namespace: keyNamespace,
+					},
+					Data: map[string][]byte{
+						ImageSignatureKeySecretKey: armoredPublicKey(trusted),
+					},
+				})
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupSchemeMm(), objects...)
+			machineMgr, err := NewMachineManager(c, nil, nil, nil, bmMachine,
+				klogr.New(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = machineMgr.verifyImageSignature(context.TODO())
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(tc.ExpectErrorText))
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+		},
+		Entry("No signature", testCaseSignature{
+			NoSignature: true,
+			NoKeySecret: true,
+		}),
+		Entry("Armored signature of the checksum", testCaseSignature{
+			Signer:        trusted,
+			SignedContent: checksum,
+			Armored:       true,
+		}),
+		Entry("Binary signature of the checksum document", testCaseSignature{
+			Signer:        trusted,
+			SignedContent: checksumDocument,
+			ChecksumIsURL: true,
+		}),
+		Entry("Signed by an untrusted key", testCaseSignature{
+			Signer:          untrusted,
+			SignedContent:   checksum,
+			Armored:         true,
+			ExpectError:     true,
+			ExpectErrorText: "invalid image signature",
+		}),
+		Entry("Signature of another checksum", testCaseSignature{
+			Signer:          trusted,
+			SignedContent:   "0123456789abcdef0123456789abcdef",
+			Armored:         true,
+			ExpectError:     true,
+			ExpectErrorText: "invalid image signature",
+		}),
+		Entry("Missing key Secret", testCaseSignature{
+			Signer:          trusted,
+			SignedContent:   checksum,
+			NoKeySecret:     true,
+			ExpectError:     true,
+			ExpectErrorText: "image signature key Secret",
+		}),
+		Entry("Key Secret in another namespace", testCaseSignature{
+			Signer:          trusted,
+			SignedContent:   checksum,
+			Armored:         true,
+			KeyNamespace:    "kube-system",
+			ExpectError:     true,
+			ExpectErrorText: "not in the namespace of the BareMetalMachine",
+		}),
+		Entry("Checksum document not served over http(s)", testCaseSignature{
+			Signer:          trusted,
+			SignedContent:   checksumDocument,
+			ChecksumURL:     "tftp://192.168.111.1/centos.qcow2.md5sum",
+			ExpectError:     true,
+			ExpectErrorText: "is not an http(s) URL",
+		}),
+		Entry("Oversized signature", testCaseSignature{
+			OversizedSignature: true,
+			ExpectError:        true,
+			ExpectErrorText:    "more than",
+		}),
+	)
+})
@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HostManagerInterface is an interface for a HostManager
+type HostManagerInterface interface {
+	AvailableHosts(context.Context) (int, error)
+}
+
+// HostManager is responsible for summarizing the BareMetalHosts of a namespace
+type HostManager struct {
+	client client.Client
+
+	Namespace string
+	Log       logr.Logger
+}
+
+// NewHostManager returns a new helper for managing the BareMetalHosts of a
+// namespace.
+func NewHostManager(client client.Client, namespace string,
+	hostLog logr.Logger) (*HostManager, error) {
+
+	return &HostManager{
+		client:    client,
+		Namespace: namespace,
+		Log:       hostLog,
+	}, nil
+}
+
+// AvailableHosts returns the number of BareMetalHosts in the namespace that
+// have no consumer and are ready to be provisioned.
+func (h *HostManager) AvailableHosts(ctx context.Context) (int, error) {
+	hosts := bmh.BareMetalHostList{}
+	opts := &client.ListOptions{
+		Namespace: h.Namespace,
+	}
+
+	if err := h.client.List(ctx, &hosts, opts); err != nil {
+		return 0, errors.Wrapf(err, "failed to list BareMetalHosts in %s", h.Namespace)
+	}
+
+	available := 0
+	for i := range hosts.Items {
+		if hostAvailable(&hosts.Items[i]) {
+			available++
+		}
+	}
+
+	h.Log.V(1).Info("Counted available BareMetalHosts", "available", available)
+	return available, nil
+}
+
+// hostAvailable returns true if the host has no consumer and is ready to be
+// provisioned.
+func hostAvailable(host *bmh.BareMetalHost) bool {
+	if !host.Available() {
+		return false
+	}
+	switch host.Status.Provisioning.State {
+	case bmh.StateReady, bmh.StateAvailable:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Host manager", func() {
+
+	type testCaseAvailableHosts struct {
+		Hosts             []*bmh.BareMetalHost
+		ExpectedAvailable int
+	}
+
+	DescribeTable("Test AvailableHosts",
+		func(tc testCaseAvailableHosts) {
+			objects := []runtime.Object{}
+			for _, host := range tc.Hosts {
+				objects = append(objects, host)
+			}
+			c := fakeclient.NewFakeClientWithScheme(setupScheme(), objects...)
+
+			hostMgr, err := NewHostManager(c, namespaceName, klogr.New())
+			Expect(err).NotTo(HaveOccurred())
+
+			available, err := hostMgr.AvailableHosts(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(available).To(Equal(tc.ExpectedAvailable))
+		},
+		Entry("No hosts", testCaseAvailableHosts{
+			Hosts:             []*bmh.BareMetalHost{},
+			ExpectedAvailable: 0,
+		}),
+		Entry("Mix of consumed and free hosts", testCaseAvailableHosts{
+			Hosts: []*bmh.BareMetalHost{
+				newAvailabilityHost("ready", namespaceName, bmh.StateReady, false),
+				newAvailabilityHost("available", namespaceName, bmh.StateAvailable, false),
+				newAvailabilityHost("consumed", namespaceName, bmh.StateReady, true),
+				newAvailabilityHost("provisioned", namespaceName, bmh.StateProvisioned, true),
+				newAvailabilityHost("inspecting", namespaceName, bmh.StateInspecting, false),
+				newAvailabilityHost("other-namespace", "other", bmh.StateReady, false),
+			},
+			ExpectedAvailable: 2,
+		}),
+		Entry("Host in error is not available", testCaseAvailableHosts{
+			Hosts: []*bmh.BareMetalHost{
+				func() *bmh.BareMetalHost {
+					host := newAvailabilityHost("error", namespaceName, bmh.StateReady, false)
+					host.Status.ErrorMessage = "power management failure"
+					return host
+				}(),
+			},
+			ExpectedAvailable: 0,
+		}),
+	)
+})
+
+func newAvailabilityHost(name string, namespace string,
+	state bmh.ProvisioningState, consumed bool) *bmh.BareMetalHost {
+
+	host := &bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Status: bmh.BareMetalHostStatus{
+			Provisioning: bmh.ProvisionStatus{
+				State: state,
+			},
+		},
+	}
+	if consumed {
+		host.Spec.ConsumerRef = &corev1.ObjectReference{
+			Name:      "mybmmachine",
+			Namespace: namespace,
+		}
+	}
+	return host
+}
@@ -29,21 +29,26 @@ type ManagerFactoryInterface interface {
 		clusterLog logr.Logger) (ClusterManagerInterface, error)
 	NewMachineManager(*capi.Cluster, *capm3.BareMetalCluster, *capi.Machine,
 		*capm3.BareMetalMachine, logr.Logger) (MachineManagerInterface, error)
+	NewHostManager(namespace string, hostLog logr.Logger) (HostManagerInterface, error)
 }
 
-// ManagerFactory only contains a client
+// ManagerFactory contains a client and the options of the ClusterManagers
 type ManagerFactory struct {
-	client client.Client
+	client         client.Client
+	clusterOptions []Option
 }
 
-// NewManagerFactory returns a new factory.
-func NewManagerFactory(client client.Client) ManagerFactory {
-	return ManagerFactory{client: client}
+// NewManagerFactory returns a new factory. The options are applied to every
+// ClusterManager it creates.
+func NewManagerFactory(client client.Client, clusterOptions ...Option) ManagerFactory {
+	return ManagerFactory{client: client, clusterOptions: clusterOptions}
 }
 
 // NewClusterManager creates a new ClusterManager
 func (f ManagerFactory) NewClusterManager(cluster *capi.Cluster, capm3Cluster *capm3.BareMetalCluster, clusterLog logr.Logger) (ClusterManagerInterface, error) {
-	return NewClusterManager(f.client, cluster, capm3Cluster, clusterLog)
+	return NewClusterManager(f.client, cluster, capm3Cluster, clusterLog,
+		f.clusterOptions...,
+	)
 }
 
 // NewMachineManager creates a new MachineManager
@@ -54,3 +59,9 @@ func (f ManagerFactory) NewMachineManager(capiCluster *capi.Cluster,
 	return NewMachineManager(f.client, capiCluster, capm3Cluster, capiMachine,
 		capm3Machine, machineLog)
 }
+
+// NewHostManager creates a new HostManager
+func (f ManagerFactory) NewHostManager(namespace string,
+	hostLog logr.Logger) (HostManagerInterface, error) {
+	return NewHostManager(f.client, namespace, hostLog)
+}
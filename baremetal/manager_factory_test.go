@@ -21,6 +21,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/klogr"
 	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -41,6 +42,19 @@ var _ = Describe("Manager factory testing", func() {
 		Expect(managerFactory.client).To(Equal(managerClient))
 	})
 
+	It("returns a cluster manager with the options of the factory", func() {
+		recorder := record.NewFakeRecorder(1)
+		managerFactory = NewManagerFactory(managerClient,
+			WithEventRecorder(recorder), WithForceDelete(),
+		)
+		clusterMgr, err := managerFactory.NewClusterManager(&capi.Cluster{},
+			&capm3.BareMetalCluster{}, clusterLog,
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clusterMgr.(*ClusterManager).EventRecorder).To(Equal(recorder))
+		Expect(clusterMgr.(*ClusterManager).ForceDelete).To(BeTrue())
+	})
+
 	It("returns a cluster manager", func() {
 		_, err := managerFactory.NewClusterManager(&capi.Cluster{},
 			&capm3.BareMetalCluster{}, clusterLog,
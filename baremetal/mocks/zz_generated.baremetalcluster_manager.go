@@ -25,7 +25,11 @@ package baremetal_mocks
 import (
 	context "context"
 	gomock "github.com/golang/mock/gomock"
+	baremetal "github.com/metal3-io/cluster-api-provider-baremetal/baremetal"
+	field "k8s.io/apimachinery/pkg/util/validation/field"
 	reflect "reflect"
+	v1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	time "time"
 )
 
 // MockClusterManagerInterface is a mock of ClusterManagerInterface interface
@@ -51,6 +55,81 @@ func (m *MockClusterManagerInterface) EXPECT() *MockClusterManagerInterfaceMockR
 	return m.recorder
 }
 
+// Reconcile mocks base method
+func (m *MockClusterManagerInterface) Reconcile(arg0 context.Context) (baremetal.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", arg0)
+	ret0, _ := ret[0].(baremetal.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile
+func (mr *MockClusterManagerInterfaceMockRecorder) Reconcile(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockClusterManagerInterface)(nil).Reconcile), arg0)
+}
+
+// ReconcileNormal mocks base method
+func (m *MockClusterManagerInterface) ReconcileNormal(arg0 context.Context) (baremetal.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileNormal", arg0)
+	ret0, _ := ret[0].(baremetal.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileNormal indicates an expected call of ReconcileNormal
+func (mr *MockClusterManagerInterfaceMockRecorder) ReconcileNormal(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileNormal", reflect.TypeOf((*MockClusterManagerInterface)(nil).ReconcileNormal), arg0)
+}
+
+// ReconcileDelete mocks base method
+func (m *MockClusterManagerInterface) ReconcileDelete(arg0 context.Context) (baremetal.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileDelete", arg0)
+	ret0, _ := ret[0].(baremetal.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileDelete indicates an expected call of ReconcileDelete
+func (mr *MockClusterManagerInterfaceMockRecorder) ReconcileDelete(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileDelete", reflect.TypeOf((*MockClusterManagerInterface)(nil).ReconcileDelete), arg0)
+}
+
+// DeleteWithTimeout mocks base method
+func (m *MockClusterManagerInterface) DeleteWithTimeout(arg0 context.Context, arg1 time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWithTimeout", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWithTimeout indicates an expected call of DeleteWithTimeout
+func (mr *MockClusterManagerInterfaceMockRecorder) DeleteWithTimeout(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWithTimeout", reflect.TypeOf((*MockClusterManagerInterface)(nil).DeleteWithTimeout), arg0, arg1)
+}
+
+// WaitForDescendantsGone mocks base method
+func (m *MockClusterManagerInterface) WaitForDescendantsGone(arg0 context.Context, arg1 time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForDescendantsGone", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitForDescendantsGone indicates an expected call of WaitForDescendantsGone
+func (mr *MockClusterManagerInterfaceMockRecorder) WaitForDescendantsGone(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForDescendantsGone", reflect.TypeOf((*MockClusterManagerInterface)(nil).WaitForDescendantsGone), arg0, arg1)
+}
+
 // Create mocks base method
 func (m *MockClusterManagerInterface) Create(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -80,17 +159,53 @@ func (mr *MockClusterManagerInterfaceMockRecorder) Delete() *gomock.Call {
 }
 
 // UpdateClusterStatus mocks base method
-func (m *MockClusterManagerInterface) UpdateClusterStatus() error {
+func (m *MockClusterManagerInterface) UpdateClusterStatus(arg0 context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateClusterStatus")
+	ret := m.ctrl.Call(m, "UpdateClusterStatus", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateClusterStatus indicates an expected call of UpdateClusterStatus
-func (mr *MockClusterManagerInterfaceMockRecorder) UpdateClusterStatus() *gomock.Call {
+func (mr *MockClusterManagerInterfaceMockRecorder) UpdateClusterStatus(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClusterStatus", reflect.TypeOf((*MockClusterManagerInterface)(nil).UpdateClusterStatus))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClusterStatus", reflect.TypeOf((*MockClusterManagerInterface)(nil).UpdateClusterStatus), arg0)
+}
+
+// ResetStatus mocks base method
+func (m *MockClusterManagerInterface) ResetStatus() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResetStatus")
+}
+
+// ResetStatus indicates an expected call of ResetStatus
+func (mr *MockClusterManagerInterfaceMockRecorder) ResetStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetStatus", reflect.TypeOf((*MockClusterManagerInterface)(nil).ResetStatus))
+}
+
+// SetReady mocks base method
+func (m *MockClusterManagerInterface) SetReady() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetReady")
+}
+
+// SetReady indicates an expected call of SetReady
+func (mr *MockClusterManagerInterfaceMockRecorder) SetReady() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReady", reflect.TypeOf((*MockClusterManagerInterface)(nil).SetReady))
+}
+
+// ClearReady mocks base method
+func (m *MockClusterManagerInterface) ClearReady() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ClearReady")
+}
+
+// ClearReady indicates an expected call of ClearReady
+func (mr *MockClusterManagerInterfaceMockRecorder) ClearReady() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearReady", reflect.TypeOf((*MockClusterManagerInterface)(nil).ClearReady))
 }
 
 // SetFinalizer mocks base method
@@ -131,3 +246,207 @@ func (mr *MockClusterManagerInterfaceMockRecorder) CountDescendants(arg0 interfa
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDescendants", reflect.TypeOf((*MockClusterManagerInterface)(nil).CountDescendants), arg0)
 }
+
+// DescendantNames mocks base method
+func (m *MockClusterManagerInterface) DescendantNames(arg0 context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescendantNames", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescendantNames indicates an expected call of DescendantNames
+func (mr *MockClusterManagerInterfaceMockRecorder) DescendantNames(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescendantNames", reflect.TypeOf((*MockClusterManagerInterface)(nil).DescendantNames), arg0)
+}
+
+// DeleteDescendants mocks base method
+func (m *MockClusterManagerInterface) DeleteDescendants(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDescendants", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDescendants indicates an expected call of DeleteDescendants
+func (mr *MockClusterManagerInterfaceMockRecorder) DeleteDescendants(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDescendants", reflect.TypeOf((*MockClusterManagerInterface)(nil).DeleteDescendants), arg0)
+}
+
+// CanProvision mocks base method
+func (m *MockClusterManagerInterface) CanProvision(arg0 context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CanProvision", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CanProvision indicates an expected call of CanProvision
+func (mr *MockClusterManagerInterfaceMockRecorder) CanProvision(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanProvision", reflect.TypeOf((*MockClusterManagerInterface)(nil).CanProvision), arg0)
+}
+
+// Validate mocks base method
+func (m *MockClusterManagerInterface) Validate(arg0 context.Context) field.ErrorList {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", arg0)
+	ret0, _ := ret[0].(field.ErrorList)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate
+func (mr *MockClusterManagerInterfaceMockRecorder) Validate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockClusterManagerInterface)(nil).Validate), arg0)
+}
+
+// GenerateKubeconfig mocks base method
+func (m *MockClusterManagerInterface) GenerateKubeconfig(arg0 context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateKubeconfig", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateKubeconfig indicates an expected call of GenerateKubeconfig
+func (mr *MockClusterManagerInterfaceMockRecorder) GenerateKubeconfig(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateKubeconfig", reflect.TypeOf((*MockClusterManagerInterface)(nil).GenerateKubeconfig), arg0)
+}
+
+// StoreKubeconfig mocks base method
+func (m *MockClusterManagerInterface) StoreKubeconfig(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreKubeconfig", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreKubeconfig indicates an expected call of StoreKubeconfig
+func (mr *MockClusterManagerInterfaceMockRecorder) StoreKubeconfig(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreKubeconfig", reflect.TypeOf((*MockClusterManagerInterface)(nil).StoreKubeconfig), arg0)
+}
+
+// Summary mocks base method
+func (m *MockClusterManagerInterface) Summary(arg0 context.Context) (baremetal.ClusterSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Summary", arg0)
+	ret0, _ := ret[0].(baremetal.ClusterSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Summary indicates an expected call of Summary
+func (mr *MockClusterManagerInterfaceMockRecorder) Summary(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Summary", reflect.TypeOf((*MockClusterManagerInterface)(nil).Summary), arg0)
+}
+
+// Describe mocks base method
+func (m *MockClusterManagerInterface) Describe(arg0 context.Context) (baremetal.ClusterDescription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Describe", arg0)
+	ret0, _ := ret[0].(baremetal.ClusterDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Describe indicates an expected call of Describe
+func (mr *MockClusterManagerInterfaceMockRecorder) Describe(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Describe", reflect.TypeOf((*MockClusterManagerInterface)(nil).Describe), arg0)
+}
+
+// EnsureControlPlaneInitialized mocks base method
+func (m *MockClusterManagerInterface) EnsureControlPlaneInitialized(arg0 context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureControlPlaneInitialized", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnsureControlPlaneInitialized indicates an expected call of EnsureControlPlaneInitialized
+func (mr *MockClusterManagerInterfaceMockRecorder) EnsureControlPlaneInitialized(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureControlPlaneInitialized", reflect.TypeOf((*MockClusterManagerInterface)(nil).EnsureControlPlaneInitialized), arg0)
+}
+
+// GetDescendantsByPhase mocks base method
+func (m *MockClusterManagerInterface) GetDescendantsByPhase(arg0 context.Context) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDescendantsByPhase", arg0)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDescendantsByPhase indicates an expected call of GetDescendantsByPhase
+func (mr *MockClusterManagerInterfaceMockRecorder) GetDescendantsByPhase(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDescendantsByPhase", reflect.TypeOf((*MockClusterManagerInterface)(nil).GetDescendantsByPhase), arg0)
+}
+
+// FailureDomains mocks base method
+func (m *MockClusterManagerInterface) FailureDomains(arg0 context.Context) (v1alpha3.FailureDomains, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailureDomains", arg0)
+	ret0, _ := ret[0].(v1alpha3.FailureDomains)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailureDomains indicates an expected call of FailureDomains
+func (mr *MockClusterManagerInterfaceMockRecorder) FailureDomains(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailureDomains", reflect.TypeOf((*MockClusterManagerInterface)(nil).FailureDomains), arg0)
+}
+
+// Refetch mocks base method
+func (m *MockClusterManagerInterface) Refetch(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refetch", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refetch indicates an expected call of Refetch
+func (mr *MockClusterManagerInterfaceMockRecorder) Refetch(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refetch", reflect.TypeOf((*MockClusterManagerInterface)(nil).Refetch), arg0)
+}
+
+// EndpointChanged mocks base method
+func (m *MockClusterManagerInterface) EndpointChanged() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndpointChanged")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// EndpointChanged indicates an expected call of EndpointChanged
+func (mr *MockClusterManagerInterfaceMockRecorder) EndpointChanged() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndpointChanged", reflect.TypeOf((*MockClusterManagerInterface)(nil).EndpointChanged))
+}
+
+// ConsistencyCheck mocks base method
+func (m *MockClusterManagerInterface) ConsistencyCheck() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsistencyCheck")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsistencyCheck indicates an expected call of ConsistencyCheck
+func (mr *MockClusterManagerInterfaceMockRecorder) ConsistencyCheck() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsistencyCheck", reflect.TypeOf((*MockClusterManagerInterface)(nil).ConsistencyCheck))
+}
@@ -27,6 +27,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	baremetal "github.com/metal3-io/cluster-api-provider-baremetal/baremetal"
 	reflect "reflect"
+	v1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
 )
 
 // MockMachineManagerInterface is a mock of MachineManagerInterface interface
@@ -133,6 +134,20 @@ func (mr *MockMachineManagerInterfaceMockRecorder) Associate(arg0 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Associate", reflect.TypeOf((*MockMachineManagerInterface)(nil).Associate), arg0)
 }
 
+// AssociateHost mocks base method
+func (m *MockMachineManagerInterface) AssociateHost(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssociateHost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssociateHost indicates an expected call of AssociateHost
+func (mr *MockMachineManagerInterfaceMockRecorder) AssociateHost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssociateHost", reflect.TypeOf((*MockMachineManagerInterface)(nil).AssociateHost), arg0, arg1)
+}
+
 // Delete mocks base method
 func (m *MockMachineManagerInterface) Delete(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -200,3 +215,99 @@ func (mr *MockMachineManagerInterfaceMockRecorder) SetProviderID(arg0 interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProviderID", reflect.TypeOf((*MockMachineManagerInterface)(nil).SetProviderID), arg0)
 }
+
+// ProviderID mocks base method
+func (m *MockMachineManagerInterface) ProviderID(arg0 string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProviderID", arg0)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ProviderID indicates an expected call of ProviderID
+func (mr *MockMachineManagerInterfaceMockRecorder) ProviderID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProviderID", reflect.TypeOf((*MockMachineManagerInterface)(nil).ProviderID), arg0)
+}
+
+func (m *MockMachineManagerInterface) PropagateLabels(arg0 []string, arg1 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PropagateLabels", arg0, arg1)
+}
+
+// PropagateLabels indicates an expected call of PropagateLabels
+func (mr *MockMachineManagerInterfaceMockRecorder) PropagateLabels(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PropagateLabels", reflect.TypeOf((*MockMachineManagerInterface)(nil).PropagateLabels), arg0, arg1)
+}
+
+// Remediate mocks base method
+func (m *MockMachineManagerInterface) Remediate(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remediate", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remediate indicates an expected call of Remediate
+func (mr *MockMachineManagerInterfaceMockRecorder) Remediate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remediate", reflect.TypeOf((*MockMachineManagerInterface)(nil).Remediate), arg0)
+}
+
+// Reprovision mocks base method
+func (m *MockMachineManagerInterface) Reprovision(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reprovision", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reprovision indicates an expected call of Reprovision
+func (mr *MockMachineManagerInterfaceMockRecorder) Reprovision(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reprovision", reflect.TypeOf((*MockMachineManagerInterface)(nil).Reprovision), arg0)
+}
+
+// Maintenance mocks base method
+func (m *MockMachineManagerInterface) Maintenance(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Maintenance", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Maintenance indicates an expected call of Maintenance
+func (mr *MockMachineManagerInterfaceMockRecorder) Maintenance(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Maintenance", reflect.TypeOf((*MockMachineManagerInterface)(nil).Maintenance), arg0)
+}
+
+// RebootHost mocks base method
+func (m *MockMachineManagerInterface) RebootHost(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebootHost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebootHost indicates an expected call of RebootHost
+func (mr *MockMachineManagerInterfaceMockRecorder) RebootHost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebootHost", reflect.TypeOf((*MockMachineManagerInterface)(nil).RebootHost), arg0, arg1)
+}
+
+// GetOwnerMachine mocks base method
+func (m *MockMachineManagerInterface) GetOwnerMachine(arg0 context.Context) (*v1alpha3.Machine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOwnerMachine", arg0)
+	ret0, _ := ret[0].(*v1alpha3.Machine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOwnerMachine indicates an expected call of GetOwnerMachine
+func (mr *MockMachineManagerInterfaceMockRecorder) GetOwnerMachine(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwnerMachine", reflect.TypeOf((*MockMachineManagerInterface)(nil).GetOwnerMachine), arg0)
+}
@@ -0,0 +1,67 @@
+// /*
+// Copyright The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// */
+//
+//
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./baremetal/host_manager.go
+
+// Package baremetal_mocks is a generated GoMock package.
+package baremetal_mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockHostManagerInterface is a mock of HostManagerInterface interface
+type MockHostManagerInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockHostManagerInterfaceMockRecorder
+}
+
+// MockHostManagerInterfaceMockRecorder is the mock recorder for MockHostManagerInterface
+type MockHostManagerInterfaceMockRecorder struct {
+	mock *MockHostManagerInterface
+}
+
+// NewMockHostManagerInterface creates a new mock instance
+func NewMockHostManagerInterface(ctrl *gomock.Controller) *MockHostManagerInterface {
+	mock := &MockHostManagerInterface{ctrl: ctrl}
+	mock.recorder = &MockHostManagerInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockHostManagerInterface) EXPECT() *MockHostManagerInterfaceMockRecorder {
+	return m.recorder
+}
+
+// AvailableHosts mocks base method
+func (m *MockHostManagerInterface) AvailableHosts(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AvailableHosts", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AvailableHosts indicates an expected call of AvailableHosts
+func (mr *MockHostManagerInterfaceMockRecorder) AvailableHosts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvailableHosts", reflect.TypeOf((*MockHostManagerInterface)(nil).AvailableHosts), arg0)
+}
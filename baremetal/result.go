@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Result tells the caller of a reconciliation whether and when the managed
+// object should be requeued. It mirrors controller-runtime's ctrl.Result.
+type Result struct {
+	// Requeue tells the caller to requeue the object.
+	Requeue bool
+	// RequeueAfter, if positive, tells the caller to requeue the object after
+	// this duration. It implies Requeue.
+	RequeueAfter time.Duration
+}
+
+// IsZero returns true if the result asks for no requeue.
+func (r Result) IsZero() bool {
+	return r == Result{}
+}
+
+// CtrlResult converts the result to a controller-runtime ctrl.Result. Requeue
+// is set when RequeueAfter is, since the latter implies it.
+func (r Result) CtrlResult() ctrl.Result {
+	return ctrl.Result{
+		Requeue:      r.Requeue || r.RequeueAfter > 0,
+		RequeueAfter: r.RequeueAfter,
+	}
+}
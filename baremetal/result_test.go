@@ -0,0 +1,44 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Result testing", func() {
+	It("Is zero when no requeue is requested", func() {
+		Expect(Result{}.IsZero()).To(BeTrue())
+		Expect(Result{Requeue: true}.IsZero()).To(BeFalse())
+		Expect(Result{RequeueAfter: time.Second}.IsZero()).To(BeFalse())
+	})
+
+	It("Converts to a ctrl.Result", func() {
+		res := Result{Requeue: true, RequeueAfter: time.Second}
+		Expect(res.CtrlResult()).To(Equal(ctrl.Result{
+			Requeue: true, RequeueAfter: time.Second,
+		}))
+		Expect(Result{RequeueAfter: time.Second}.CtrlResult()).To(Equal(
+			ctrl.Result{Requeue: true, RequeueAfter: time.Second},
+		))
+	})
+})
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// StatusUpdateAttempts is the maximum number of times a status write is
+	// attempted before giving up and requeueing.
+	StatusUpdateAttempts = 3
+	// statusUpdateBackoff is the base delay between two status write attempts.
+	statusUpdateBackoff = 200 * time.Millisecond
+	// statusUpdateJitter is the maximum jitter factor applied to the backoff.
+	statusUpdateJitter = 1.0
+)
+
+// RetryStatusUpdate calls update until it succeeds, up to StatusUpdateAttempts
+// times. Only the transient errors of the API server are retried, see
+// IsTransientError. Between two attempts it sleeps on the given clock for a
+// jittered, linearly increasing backoff. The error of the last attempt is
+// returned if none succeeded. A nil clock defaults to the real clock.
+func RetryStatusUpdate(clk clock.Clock, update func() error) error {
+	if clk == nil {
+		clk = clock.RealClock{}
+	}
+	var err error
+	for attempt := 1; attempt <= StatusUpdateAttempts; attempt++ {
+		if err = update(); err == nil {
+			return nil
+		}
+		if !IsTransientError(err) {
+			return err
+		}
+		if attempt < StatusUpdateAttempts {
+			clk.Sleep(wait.Jitter(time.Duration(attempt)*statusUpdateBackoff,
+				statusUpdateJitter,
+			))
+		}
+	}
+	return err
+}
+
+// IsTransientError returns true if err, or every error it aggregates, is a
+// timeout, a throttling or an internal error of the API server, that a later
+// attempt may not hit.
+func IsTransientError(err error) bool {
+	return allCauses(err, func(err error) bool {
+		return apierrors.IsServerTimeout(err) ||
+			apierrors.IsTooManyRequests(err) ||
+			apierrors.IsServiceUnavailable(err) ||
+			apierrors.IsTimeout(err) ||
+			apierrors.IsInternalError(err)
+	})
+}
+
+// IsNotFoundError returns true if err, or every error it aggregates, is a
+// NotFound error, e.g. for a write of an object deleted once its finalizer
+// was removed.
+func IsNotFoundError(err error) bool {
+	return allCauses(err, apierrors.IsNotFound)
+}
+
+// allCauses returns true if the cause of err, or the cause of every error
+// aggregated in it, matches.
+func allCauses(err error, match func(error) bool) bool {
+	if err == nil {
+		return false
+	}
+	cause := errors.Cause(err)
+	aggregate, ok := cause.(kerrors.Aggregate)
+	if !ok {
+		return match(cause)
+	}
+	if len(aggregate.Errors()) == 0 {
+		return false
+	}
+	for _, err := range aggregate.Errors() {
+		if !allCauses(err, match) {
+			return false
+		}
+	}
+	return true
+}
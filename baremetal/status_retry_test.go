@@ -0,0 +1,155 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+var _ = Describe("Status update retries", func() {
+
+	resource := schema.GroupResource{
+		Group:    "infrastructure.cluster.x-k8s.io",
+		Resource: "baremetalclusters",
+	}
+	transientErr := apierrors.NewServerTimeout(resource, "patch", 1)
+
+	type testCaseRetryStatusUpdate struct {
+		Failures         int
+		Err              error
+		ExpectError      bool
+		ExpectedAttempts int
+	}
+
+	DescribeTable("Test RetryStatusUpdate",
+		func(tc testCaseRetryStatusUpdate) {
+			start := time.Now()
+			fakeClock := clock.NewFakeClock(start)
+			attempts := 0
+
+			err := RetryStatusUpdate(fakeClock, func() error {
+				attempts++
+				if attempts <= tc.Failures {
+					return tc.Err
+				}
+				return nil
+			})
+
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(attempts).To(Equal(tc.ExpectedAttempts))
+			// The fake clock only moves forward when we wait between attempts.
+			if tc.ExpectedAttempts > 1 {
+				Expect(fakeClock.Now().After(start)).To(BeTrue())
+			} else {
+				Expect(fakeClock.Now()).To(Equal(start))
+			}
+		},
+		Entry("Succeeds on first attempt", testCaseRetryStatusUpdate{
+			Failures:         0,
+			ExpectError:      false,
+			ExpectedAttempts: 1,
+		}),
+		Entry("Transient failure, succeeds on second attempt",
+			testCaseRetryStatusUpdate{
+				Failures:         1,
+				Err:              transientErr,
+				ExpectError:      false,
+				ExpectedAttempts: 2,
+			},
+		),
+		Entry("Aggregated transient failure, succeeds on second attempt",
+			testCaseRetryStatusUpdate{
+				Failures: 1,
+				Err: kerrors.NewAggregate([]error{transientErr,
+					apierrors.NewTooManyRequests("throttled", 1),
+				}),
+				ExpectError:      false,
+				ExpectedAttempts: 2,
+			},
+		),
+		Entry("Fails on all attempts", testCaseRetryStatusUpdate{
+			Failures:         StatusUpdateAttempts,
+			Err:              transientErr,
+			ExpectError:      true,
+			ExpectedAttempts: StatusUpdateAttempts,
+		}),
+		Entry("Conflict not retried", testCaseRetryStatusUpdate{
+			Failures:         1,
+			Err:              apierrors.NewConflict(resource, "bmc", errors.New("conflict")),
+			ExpectError:      true,
+			ExpectedAttempts: 1,
+		}),
+		Entry("Aggregated conflict not retried", testCaseRetryStatusUpdate{
+			Failures: 1,
+			Err: kerrors.NewAggregate([]error{transientErr,
+				apierrors.NewConflict(resource, "bmc", errors.New("conflict")),
+			}),
+			ExpectError:      true,
+			ExpectedAttempts: 1,
+		}),
+		Entry("Other error not retried", testCaseRetryStatusUpdate{
+			Failures:         1,
+			Err:              errors.New("Error"),
+			ExpectError:      true,
+			ExpectedAttempts: 1,
+		}),
+	)
+
+	type testCaseIsNotFoundError struct {
+		Err            error
+		ExpectNotFound bool
+	}
+
+	DescribeTable("Test IsNotFoundError",
+		func(tc testCaseIsNotFoundError) {
+			Expect(IsNotFoundError(tc.Err)).To(Equal(tc.ExpectNotFound))
+		},
+		Entry("No error", testCaseIsNotFoundError{}),
+		Entry("NotFound", testCaseIsNotFoundError{
+			Err:            apierrors.NewNotFound(resource, "bmc"),
+			ExpectNotFound: true,
+		}),
+		Entry("Wrapped aggregated NotFound", testCaseIsNotFoundError{
+			Err: errors.Wrap(kerrors.NewAggregate([]error{
+				apierrors.NewNotFound(resource, "bmc"),
+			}), "failed to patch"),
+			ExpectNotFound: true,
+		}),
+		Entry("NotFound aggregated with another error", testCaseIsNotFoundError{
+			Err: kerrors.NewAggregate([]error{
+				apierrors.NewNotFound(resource, "bmc"), transientErr,
+			}),
+		}),
+		Entry("Other error", testCaseIsNotFoundError{
+			Err: transientErr,
+		}),
+	)
+})
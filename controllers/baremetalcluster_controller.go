@@ -26,6 +26,7 @@ import (
 	capm3 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
 	"github.com/metal3-io/cluster-api-provider-baremetal/baremetal"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/utils/pointer"
 	capi "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
@@ -48,11 +49,16 @@ type BareMetalClusterReconciler struct {
 	Client         client.Client
 	ManagerFactory baremetal.ManagerFactoryInterface
 	Log            logr.Logger
+	// Clock is used to wait between status write attempts. Defaults to the
+	// real clock when unset.
+	Clock clock.Clock
 }
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=baremetalclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=baremetalclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch
 
 // Reconcile reads that state of the cluster for a BareMetalCluster object and makes changes based on the state read
 // and what is in the BareMetalCluster.Spec
@@ -77,15 +83,32 @@ func (r *BareMetalClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result,
 		return ctrl.Result{}, errors.Wrap(err, "failed to init patch helper")
 	}
 	// Always patch baremetalCluster when exiting this function so we can persist any BaremetalCluster changes.
+	// Transient write failures are retried before giving up and requeueing.
 	defer func() {
-		err := helper.Patch(ctx, baremetalCluster)
+		err := baremetal.RetryStatusUpdate(r.Clock, func() error {
+			return helper.Patch(ctx, baremetalCluster)
+		})
+		if err != nil && !baremetalCluster.DeletionTimestamp.IsZero() &&
+			baremetal.IsNotFoundError(err) {
+			// The removal of the finalizer completed the deletion
+			err = nil
+		}
 		if err != nil {
 			clusterLog.Error(err, "failed to Patch baremetalCluster")
+			if rerr == nil {
+				rerr = err
+			}
 		}
 	}()
 
 	// Fetch the Cluster.
 	cluster, err := util.GetOwnerCluster(ctx, r.Client, baremetalCluster.ObjectMeta)
+	if apierrors.IsNotFound(errors.Cause(err)) && !baremetalCluster.DeletionTimestamp.IsZero() {
+		// The owner Cluster was deleted first, let the manager clean up
+		// without it
+		clusterLog.Info("Owner Cluster is gone, deleting the BareMetalCluster")
+		return r.reconcileOrphanDelete(ctx, baremetalCluster, clusterLog)
+	}
 	if err != nil {
 		error := capierrors.InvalidConfigurationClusterError
 		baremetalCluster.Status.FailureReason = &error
@@ -99,12 +122,6 @@ func (r *BareMetalClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result,
 
 	clusterLog = clusterLog.WithValues("cluster", cluster.Name)
 
-	// Return early if BMCluster or Cluster is paused.
-	if util.IsPaused(cluster, baremetalCluster) {
-		clusterLog.Info("reconciliation is paused for this object")
-		return ctrl.Result{Requeue: true, RequeueAfter: requeueAfter}, nil
-	}
-
 	clusterLog.Info("Reconciling BaremetalCluster")
 
 	// Create a helper for managing a baremetal cluster.
@@ -118,52 +135,53 @@ func (r *BareMetalClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result,
 
 	// Handle deleted clusters
 	if !baremetalCluster.DeletionTimestamp.IsZero() {
-		return reconcileDelete(ctx, clusterMgr)
+		result, err := clusterMgr.ReconcileDelete(ctx)
+		return result.CtrlResult(), err
+	}
+
+	// Surface the number of free hosts, so that users know if they can scale
+	if err := r.setAvailableHosts(ctx, baremetalCluster, clusterLog); err != nil {
+		return ctrl.Result{}, err
 	}
 
 	// Handle non-deleted clusters
-	return reconcileNormal(ctx, clusterMgr)
+	result, err := clusterMgr.ReconcileNormal(ctx)
+	return result.CtrlResult(), err
 }
 
-func reconcileNormal(ctx context.Context, clusterMgr baremetal.ClusterManagerInterface) (ctrl.Result, error) {
-	// If the BareMetalCluster doesn't have finalizer, add it.
-	clusterMgr.SetFinalizer()
-
-	//Create the baremetal cluster (no-op)
-	if err := clusterMgr.Create(ctx); err != nil {
-		return ctrl.Result{}, err
+// reconcileOrphanDelete runs the deletion of a BareMetalCluster whose owner
+// Cluster no longer exists.
+func (r *BareMetalClusterReconciler) reconcileOrphanDelete(ctx context.Context,
+	baremetalCluster *capm3.BareMetalCluster, clusterLog logr.Logger,
+) (ctrl.Result, error) {
+	clusterMgr, err := r.ManagerFactory.NewClusterManager(nil, baremetalCluster, clusterLog)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the clusterMgr")
 	}
-
-	// Set APIEndpoints so the Cluster API Cluster Controller can pull it
-	if err := clusterMgr.UpdateClusterStatus(); err != nil {
-		return ctrl.Result{}, errors.Wrap(err, "failed to get ip for the API endpoint")
+	if clusterMgr == nil {
+		return ctrl.Result{}, nil
 	}
 
-	return ctrl.Result{}, nil
+	result, err := clusterMgr.ReconcileDelete(ctx)
+	return result.CtrlResult(), err
 }
 
-func reconcileDelete(ctx context.Context,
-	clusterMgr baremetal.ClusterManagerInterface) (ctrl.Result, error) {
-
-	// Verify that no baremetalmachine depend on the baremetalcluster
-	descendants, err := clusterMgr.CountDescendants(ctx)
+// setAvailableHosts sets the number of available BareMetalHosts in the
+// BareMetalCluster status.
+func (r *BareMetalClusterReconciler) setAvailableHosts(ctx context.Context,
+	baremetalCluster *capm3.BareMetalCluster, clusterLog logr.Logger,
+) error {
+	hostMgr, err := r.ManagerFactory.NewHostManager(baremetalCluster.Namespace, clusterLog)
 	if err != nil {
-		return ctrl.Result{}, err
-	}
-	if descendants > 0 {
-		// Requeue so we can check the next time to see if there are still any
-		// descendants left.
-		return ctrl.Result{Requeue: true, RequeueAfter: requeueAfter}, nil
+		return errors.Wrapf(err, "failed to create helper for managing the hostMgr")
 	}
 
-	if err := clusterMgr.Delete(); err != nil {
-		return ctrl.Result{}, errors.Wrap(err, "failed to delete BareMetalCluster")
+	availableHosts, err := hostMgr.AvailableHosts(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to count available BareMetalHosts")
 	}
-
-	// Cluster is deleted so remove the finalizer.
-	clusterMgr.UnsetFinalizer()
-
-	return ctrl.Result{}, nil
+	baremetalCluster.Status.AvailableHosts = availableHosts
+	return nil
 }
 
 // SetupWithManager will add watches for this controller
@@ -15,6 +15,7 @@ package controllers
 import (
 	"context"
 	"reflect"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -22,13 +23,17 @@ import (
 	"github.com/pkg/errors"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 
+	bmh "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
 	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
 	"github.com/metal3-io/cluster-api-provider-baremetal/baremetal"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/klog/klogr"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -194,6 +199,27 @@ var _ = Describe("Reconcile Baremetalcluster", func() {
 				RequeueExpected: false,
 			},
 		),
+		// Reconcile Deletion after the owner Cluster
+		Entry("Should reconcileDelete when the owner Cluster is gone",
+			TestCaseReconcileBMC{
+				Objects: []runtime.Object{
+					&infrav1.BareMetalCluster{
+						TypeMeta: metav1.TypeMeta{
+							Kind: "BareMetalCluster",
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              baremetalClusterName,
+							Namespace:         namespaceName,
+							DeletionTimestamp: &deletionTimestamp,
+							OwnerReferences:   []metav1.OwnerReference{*bmcOwnerRef()},
+						},
+						Spec: *bmcSpec(),
+					},
+				},
+				ErrorExpected:   false,
+				RequeueExpected: false,
+			},
+		),
 		// Reconcile Deletion, wait for baremetalmachine
 		Entry("reconcileDelete should wait for baremetalmachine",
 			TestCaseReconcileBMC{
@@ -220,3 +246,114 @@ var _ = Describe("Reconcile Baremetalcluster", func() {
 	)
 
 })
+
+var _ = Describe("Reconcile Baremetalcluster status write retries", func() {
+
+	It("Should retry a transient status write failure without requeueing", func() {
+		c := &flakyStatusClient{
+			Client: fake.NewFakeClientWithScheme(setupScheme(),
+				newBareMetalCluster(baremetalClusterName, bmcOwnerRef(), bmcSpec(), nil, false),
+				newCluster(clusterName, nil, nil),
+			),
+			failures: 1,
+		}
+
+		r := &BareMetalClusterReconciler{
+			Client:         c,
+			ManagerFactory: baremetal.NewManagerFactory(c),
+			Log:            klogr.New(),
+			Clock:          clock.NewFakeClock(time.Now()),
+		}
+
+		res, err := r.Reconcile(reconcile.Request{
+			NamespacedName: *getKey(baremetalClusterName),
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Requeue).To(BeFalse())
+		Expect(c.statusPatches).To(Equal(2))
+
+		testclstr := &infrav1.BareMetalCluster{}
+		Expect(c.Get(context.TODO(), *getKey(baremetalClusterName), testclstr)).To(Succeed())
+		Expect(testclstr.Status.Ready).To(BeTrue())
+	})
+
+	It("Should return an error once all status write attempts failed", func() {
+		c := &flakyStatusClient{
+			Client: fake.NewFakeClientWithScheme(setupScheme(),
+				newBareMetalCluster(baremetalClusterName, bmcOwnerRef(), bmcSpec(), nil, false),
+				newCluster(clusterName, nil, nil),
+			),
+			failures: baremetal.StatusUpdateAttempts,
+		}
+
+		r := &BareMetalClusterReconciler{
+			Client:         c,
+			ManagerFactory: baremetal.NewManagerFactory(c),
+			Log:            klogr.New(),
+			Clock:          clock.NewFakeClock(time.Now()),
+		}
+
+		_, err := r.Reconcile(reconcile.Request{
+			NamespacedName: *getKey(baremetalClusterName),
+		})
+
+		Expect(err).To(HaveOccurred())
+		Expect(c.statusPatches).To(Equal(baremetal.StatusUpdateAttempts))
+	})
+})
+
+var _ = Describe("Reconcile Baremetalcluster available hosts", func() {
+
+	It("Should set the number of available BareMetalHosts on the status", func() {
+		c := fake.NewFakeClientWithScheme(setupScheme(),
+			newBareMetalCluster(baremetalClusterName, bmcOwnerRef(), bmcSpec(), nil, false),
+			newCluster(clusterName, nil, nil),
+			newBareMetalHost(nil, &bmh.BareMetalHostStatus{
+				Provisioning: bmh.ProvisionStatus{
+					State: bmh.StateReady,
+				},
+			}),
+		)
+
+		r := &BareMetalClusterReconciler{
+			Client:         c,
+			ManagerFactory: baremetal.NewManagerFactory(c),
+			Log:            klogr.New(),
+		}
+
+		_, err := r.Reconcile(reconcile.Request{
+			NamespacedName: *getKey(baremetalClusterName),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		testclstr := &infrav1.BareMetalCluster{}
+		Expect(c.Get(context.TODO(), *getKey(baremetalClusterName), testclstr)).To(Succeed())
+		Expect(testclstr.Status.AvailableHosts).To(Equal(1))
+	})
+})
+
+// flakyStatusClient wraps a client and fails the first status patches.
+type flakyStatusClient struct {
+	client.Client
+	failures      int
+	statusPatches int
+}
+
+func (c *flakyStatusClient) Status() client.StatusWriter {
+	return &flakyStatusWriter{StatusWriter: c.Client.Status(), parent: c}
+}
+
+type flakyStatusWriter struct {
+	client.StatusWriter
+	parent *flakyStatusClient
+}
+
+func (w *flakyStatusWriter) Patch(ctx context.Context, obj runtime.Object,
+	patch client.Patch, opts ...client.PatchOption) error {
+	w.parent.statusPatches++
+	if w.parent.statusPatches <= w.parent.failures {
+		return apierrors.NewServiceUnavailable("the server is currently unable to handle the request")
+	}
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
@@ -19,26 +19,49 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
+	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
+	infrav1 "github.com/metal3-io/cluster-api-provider-baremetal/api/v1alpha3"
+	"github.com/metal3-io/cluster-api-provider-baremetal/baremetal"
 	baremetal_mocks "github.com/metal3-io/cluster-api-provider-baremetal/baremetal/mocks"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/klogr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
-var _ = Describe("BareMetalCluster controller", func() {
-
-	type testCaseClusterNormal struct {
-		CreateError   bool
-		UpdateError   bool
-		ExpectError   bool
-		ExpectRequeue bool
+// clusterManagerFactory returns the same ClusterManager for every cluster,
+// the other managers being the real ones. onNew, if set, is passed the
+// BareMetalCluster being reconciled.
+type clusterManagerFactory struct {
+	baremetal.ManagerFactory
+	clusterMgr baremetal.ClusterManagerInterface
+	onNew      func(*infrav1.BareMetalCluster)
+}
+
+func (f clusterManagerFactory) NewClusterManager(cluster *clusterv1.Cluster,
+	bareMetalCluster *infrav1.BareMetalCluster,
+	clusterLog logr.Logger) (baremetal.ClusterManagerInterface, error) {
+	if f.onNew != nil {
+		f.onNew(bareMetalCluster)
 	}
+	return f.clusterMgr, nil
+}
 
-	type testCaseClusterDelete struct {
-		DescendantsCount int
-		DescendantsError bool
-		DeleteError      bool
-		ExpectError      bool
-		ExpectRequeue    bool
+var _ = Describe("BareMetalCluster controller", func() {
+
+	type testCaseClusterReconcile struct {
+		Deleted        bool
+		ClusterGone    bool
+		FinalizerGone  bool
+		Result         baremetal.Result
+		ReturnError    bool
+		ExpectError    bool
+		ExpectedResult reconcile.Result
 	}
 
 	var gomockCtrl *gomock.Controller
@@ -51,134 +74,106 @@ var _ = Describe("BareMetalCluster controller", func() {
 		gomockCtrl.Finish()
 	})
 
-	DescribeTable("Test ClusterReconcileNormal",
-		func(tc testCaseClusterNormal) {
+	DescribeTable("Test ClusterReconcile",
+		func(tc testCaseClusterReconcile) {
 			var returnedError error
-			m := baremetal_mocks.NewMockClusterManagerInterface(gomockCtrl)
-
-			m.EXPECT().SetFinalizer()
-
-			if tc.CreateError {
+			if tc.ReturnError {
 				returnedError = errors.New("Error")
-				m.EXPECT().UpdateClusterStatus().MaxTimes(0)
-			} else {
-				if tc.UpdateError {
-					returnedError = errors.New("Error")
-				} else {
-					returnedError = nil
-				}
-				m.EXPECT().UpdateClusterStatus().Return(returnedError)
-				returnedError = nil
-			}
-			m.EXPECT().
-				Create(context.TODO()).Return(returnedError)
-
-			res, err := reconcileNormal(context.TODO(), m)
-
-			if tc.ExpectError {
-				Expect(err).To(HaveOccurred())
-			} else {
-				Expect(err).NotTo(HaveOccurred())
-			}
-			if tc.ExpectRequeue {
-				Expect(res.Requeue).To(BeTrue())
-			} else {
-				Expect(res.Requeue).To(BeFalse())
 			}
-		},
-		Entry("No errors", testCaseClusterNormal{
-			CreateError:   false,
-			UpdateError:   false,
-			ExpectError:   false,
-			ExpectRequeue: false,
-		}),
-		Entry("Create error", testCaseClusterNormal{
-			CreateError:   true,
-			UpdateError:   false,
-			ExpectError:   true,
-			ExpectRequeue: false,
-		}),
-		Entry("Update error", testCaseClusterNormal{
-			CreateError:   false,
-			UpdateError:   true,
-			ExpectError:   true,
-			ExpectRequeue: false,
-		}),
-	)
-
-	DescribeTable("Test ClusterReconcileDelete",
-		func(tc testCaseClusterDelete) {
-			var returnedError error
 			m := baremetal_mocks.NewMockClusterManagerInterface(gomockCtrl)
-
-			// If we get an error while listing descendants or some still exists,
-			// we will exit with error or requeue.
-			if tc.DescendantsError || tc.DescendantsCount != 0 {
-				m.EXPECT().Delete().MaxTimes(0)
-				m.EXPECT().UnsetFinalizer().MaxTimes(0)
+			var reconciled *infrav1.BareMetalCluster
+			var c client.Client
+			if tc.Deleted {
+				m.EXPECT().ReconcileNormal(gomock.Any()).MaxTimes(0)
+				m.EXPECT().ReconcileDelete(gomock.Any()).DoAndReturn(
+					func(ctx context.Context) (baremetal.Result, error) {
+						if tc.FinalizerGone {
+							// The API server deletes the object once its
+							// finalizer is removed
+							reconciled.Finalizers = nil
+							Expect(c.Delete(ctx, reconciled.DeepCopy())).To(Succeed())
+						}
+						return tc.Result, returnedError
+					},
+				)
 			} else {
-				// if no descendants are left, but we hit an error during delete,
-				// we do not remove the finalizers
-				if tc.DeleteError {
-					m.EXPECT().UnsetFinalizer().MaxTimes(0)
-					returnedError = errors.New("Error")
-				} else {
-					m.EXPECT().UnsetFinalizer()
-					returnedError = nil
-				}
-				m.EXPECT().Delete().Return(returnedError)
+				m.EXPECT().ReconcileDelete(gomock.Any()).MaxTimes(0)
+				m.EXPECT().ReconcileNormal(gomock.Any()).Return(tc.Result,
+					returnedError,
+				)
 			}
 
-			if tc.DescendantsError {
-				returnedError = errors.New("Error")
-			} else {
-				returnedError = nil
-			}
-			m.EXPECT().
-				CountDescendants(context.TODO()).Return(tc.DescendantsCount,
-				returnedError,
+			bmCluster := newBareMetalCluster(baremetalClusterName, bmcOwnerRef(),
+				bmcSpec(), nil, false,
 			)
+			if tc.Deleted {
+				bmCluster.DeletionTimestamp = &deletionTimestamp
+				bmCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			}
+			objects := []runtime.Object{bmCluster}
+			if !tc.ClusterGone {
+				objects = append(objects, newCluster(clusterName, nil, nil))
+			}
+			c = fake.NewFakeClientWithScheme(setupScheme(), objects...)
+			r := &BareMetalClusterReconciler{
+				Client: c,
+				ManagerFactory: clusterManagerFactory{
+					ManagerFactory: baremetal.NewManagerFactory(c),
+					clusterMgr:     m,
+					onNew: func(bmCluster *infrav1.BareMetalCluster) {
+						reconciled = bmCluster
+					},
+				},
+				Log: klogr.New(),
+			}
 
-			res, err := reconcileDelete(context.TODO(), m)
+			res, err := r.Reconcile(reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      baremetalClusterName,
+					Namespace: namespaceName,
+				},
+			})
 
 			if tc.ExpectError {
 				Expect(err).To(HaveOccurred())
 			} else {
 				Expect(err).NotTo(HaveOccurred())
 			}
-			if tc.ExpectRequeue {
-				Expect(res.Requeue).To(BeTrue())
-			} else {
-				Expect(res.Requeue).To(BeFalse())
-			}
+			Expect(res).To(Equal(tc.ExpectedResult))
 		},
-		Entry("No errors", testCaseClusterDelete{
-			DescendantsCount: 0,
-			DescendantsError: false,
-			DeleteError:      false,
-			ExpectError:      false,
-			ExpectRequeue:    false,
+		Entry("No errors", testCaseClusterReconcile{}),
+		Entry("Reconcile error", testCaseClusterReconcile{
+			ReturnError: true,
+			ExpectError: true,
+		}),
+		Entry("Reconcile requeue", testCaseClusterReconcile{
+			Result: baremetal.Result{RequeueAfter: requeueAfter},
+			ExpectedResult: reconcile.Result{
+				Requeue: true, RequeueAfter: requeueAfter,
+			},
+		}),
+		Entry("Delete no errors", testCaseClusterReconcile{
+			Deleted: true,
+		}),
+		Entry("Delete error", testCaseClusterReconcile{
+			Deleted:     true,
+			ReturnError: true,
+			ExpectError: true,
 		}),
-		Entry("Descendants left", testCaseClusterDelete{
-			DescendantsCount: 1,
-			DescendantsError: false,
-			DeleteError:      false,
-			ExpectError:      false,
-			ExpectRequeue:    true,
+		Entry("Delete after the Cluster", testCaseClusterReconcile{
+			Deleted:     true,
+			ClusterGone: true,
 		}),
-		Entry("Descendants error", testCaseClusterDelete{
-			DescendantsCount: 0,
-			DescendantsError: true,
-			DeleteError:      false,
-			ExpectError:      true,
-			ExpectRequeue:    false,
+		Entry("Delete completed by the finalizer removal", testCaseClusterReconcile{
+			Deleted:       true,
+			FinalizerGone: true,
 		}),
-		Entry("Delete error", testCaseClusterDelete{
-			DescendantsCount: 0,
-			DescendantsError: false,
-			DeleteError:      true,
-			ExpectError:      true,
-			ExpectRequeue:    false,
+		Entry("Delete requeue", testCaseClusterReconcile{
+			Deleted: true,
+			Result:  baremetal.Result{RequeueAfter: requeueAfter},
+			ExpectedResult: reconcile.Result{
+				Requeue: true, RequeueAfter: requeueAfter,
+			},
 		}),
 	)
 })
@@ -89,14 +89,25 @@ func (r *BareMetalMachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result,
 	clearErrorBMMachine(capm3Machine)
 
 	// Fetch the Machine.
-	capiMachine, err := util.GetOwnerMachine(ctx, r.Client, capm3Machine.ObjectMeta)
-
+	ownerMgr, err := r.ManagerFactory.NewMachineManager(nil, nil, nil, capm3Machine, machineLog)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the machineMgr")
+	}
+	capiMachine, err := ownerMgr.GetOwnerMachine(ctx)
+
+	if err != nil && apierrors.IsNotFound(err) &&
+		!capm3Machine.ObjectMeta.DeletionTimestamp.IsZero() {
+		// The owner Machine was deleted while the BareMetalMachine lingers.
+		// There is no bootstrap data left to wait for, release the host.
+		machineLog.Info("BareMetalMachine's owner Machine is gone, releasing the host")
+		return r.reconcileOrphanDelete(ctx, capm3Machine, machineLog)
+	}
 	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "BareMetalMachine's owner Machine could not be retrieved")
 	}
 	if capiMachine == nil {
 		machineLog.Info("Waiting for Machine Controller to set OwnerRef on BareMetalMachine")
-		return ctrl.Result{}, nil
+		return ctrl.Result{Requeue: true, RequeueAfter: requeueAfter}, nil
 	}
 
 	machineLog = machineLog.WithValues("machine", capiMachine.Name)
@@ -156,20 +167,43 @@ func (r *BareMetalMachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result,
 		return r.reconcileDelete(ctx, machineMgr)
 	}
 
+	// Create a helper for checking the cluster provisioning limits.
+	clusterMgr, err := r.ManagerFactory.NewClusterManager(cluster, baremetalCluster, machineLog)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the clusterMgr")
+	}
+
 	// Handle non-deleted machines
-	return r.reconcileNormal(ctx, machineMgr)
+	return r.reconcileNormal(ctx, machineMgr, clusterMgr)
 }
 
 func (r *BareMetalMachineReconciler) reconcileNormal(ctx context.Context,
 	machineMgr baremetal.MachineManagerInterface,
+	clusterMgr baremetal.ClusterManagerInterface,
 ) (ctrl.Result, error) {
 	// If the BareMetalMachine doesn't have finalizer, add it.
 	machineMgr.SetFinalizer()
 
-	// if the machine is already provisioned, return
+	// if the machine is already provisioned, remediate or reprovision it if
+	// requested, pass the maintenance requests through to the host, update it
+	// and return
 	if machineMgr.IsProvisioned() {
-		err := machineMgr.Update(ctx)
-		return ctrl.Result{}, err
+		remediateErr := machineMgr.Remediate(ctx)
+		reprovisionErr := machineMgr.Reprovision(ctx)
+		maintenanceErr := machineMgr.Maintenance(ctx)
+		if err := machineMgr.Update(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+		if maintenanceErr != nil {
+			return checkError(maintenanceErr, "failed to set the maintenance of the BaremetalHost")
+		}
+		if remediateErr != nil {
+			return checkError(remediateErr, "failed to remediate the BareMetalMachine")
+		}
+		if reprovisionErr != nil {
+			return checkError(reprovisionErr, "failed to reprovision the BareMetalMachine")
+		}
+		return ctrl.Result{}, nil
 	}
 
 	// Make sure bootstrap data is available and populated. If not, return, we
@@ -180,8 +214,17 @@ func (r *BareMetalMachineReconciler) reconcileNormal(ctx context.Context,
 
 	// Check if the baremetalmachine was associated with a baremetalhost
 	if !machineMgr.HasAnnotation() {
+		// Wait for a provisioning slot in the cluster
+		canProvision, err := clusterMgr.CanProvision(ctx)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to check the cluster provisioning limit")
+		}
+		if !canProvision {
+			return ctrl.Result{Requeue: true, RequeueAfter: requeueAfter}, nil
+		}
+
 		//Associate the baremetalhost hosting the machine
-		err := machineMgr.Associate(ctx)
+		err = machineMgr.Associate(ctx)
 		if err != nil {
 			return checkError(err, "failed to associate the BareMetalMachine to a BaremetalHost")
 		}
@@ -192,7 +235,7 @@ func (r *BareMetalMachineReconciler) reconcileNormal(ctx context.Context,
 		return checkError(err, "failed to get the providerID for the BaremetalMachine")
 	}
 	if bmhID != nil {
-		providerID := fmt.Sprintf("metal3://%s", *bmhID)
+		providerID := machineMgr.ProviderID(*bmhID)
 		// Set the providerID on the node if no Cloud provider
 		err = machineMgr.SetNodeProviderID(ctx, *bmhID, providerID, r.CapiClientGetter)
 		if err != nil {
@@ -222,6 +265,20 @@ func (r *BareMetalMachineReconciler) reconcileDelete(ctx context.Context,
 	return ctrl.Result{}, nil
 }
 
+// reconcileOrphanDelete handles the deletion of a BareMetalMachine whose owner
+// Machine no longer exists. Without a Machine, the Cluster and
+// BareMetalCluster can not be looked up, so the host is released using the
+// BareMetalMachine only.
+func (r *BareMetalMachineReconciler) reconcileOrphanDelete(ctx context.Context,
+	capm3Machine *capm3.BareMetalMachine, machineLog logr.Logger,
+) (ctrl.Result, error) {
+	machineMgr, err := r.ManagerFactory.NewMachineManager(nil, nil, nil, capm3Machine, machineLog)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the machineMgr")
+	}
+	return r.reconcileDelete(ctx, machineMgr)
+}
+
 // SetupWithManager will add watches for this controller
 func (r *BareMetalMachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
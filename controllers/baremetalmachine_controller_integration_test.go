@@ -171,6 +171,7 @@ var _ = Describe("Reconcile BaremetalMachine", func() {
 		CheckBootStrapReady     bool
 		CheckBMHostCleaned      bool
 		CheckBMHostProvisioned  bool
+		CheckWaitingForOwner    bool
 	}
 
 	DescribeTable("Reconcile tests",
@@ -249,10 +250,20 @@ var _ = Describe("Reconcile BaremetalMachine", func() {
 				Expect(testBMHost.Spec.UserData).To(BeNil())
 			}
 			if tc.CheckBMHostProvisioned {
-				Expect(*testBMHost.Spec.Image).Should(BeEquivalentTo(testBMmachine.Spec.Image))
+				Expect(testBMHost.Spec.Image.URL).To(Equal(testBMmachine.Spec.Image.URL))
+				Expect(testBMHost.Spec.Image.Checksum).To(Equal(testBMmachine.Spec.Image.Checksum))
 				Expect(testBMHost.Spec.UserData).NotTo(BeNil())
 				Expect(testBMHost.Spec.ConsumerRef.Name).To(Equal(testBMmachine.Name))
 			}
+			if tc.CheckWaitingForOwner {
+				Expect(testBMmachine.Status.Conditions.IsTrue(
+					infrav1.WaitingForOwnerCondition,
+				)).To(BeTrue())
+			} else {
+				Expect(testBMmachine.Status.Conditions.Get(
+					infrav1.WaitingForOwnerCondition,
+				)).To(BeNil())
+			}
 			if tc.ClusterInfraReady {
 				Expect(testcluster.Status.InfrastructureReady).To(BeTrue())
 			} else {
@@ -273,13 +284,15 @@ var _ = Describe("Reconcile BaremetalMachine", func() {
 		),
 		//Given: baremetalMachine with OwnerRef not set.
 		//Expected: No error. Reconciler waits for  Machine Controller to set OwnerRef
-		Entry("Should not return an error if OwnerRef is not set on BareMetalCluster",
+		Entry("Should requeue if OwnerRef is not set on BareMetalCluster",
 			TestCaseReconcile{
 				Objects: []runtime.Object{
 					newBareMetalMachine(bareMetalMachineName, nil, nil, nil, false),
 				},
-				ErrorExpected:   false,
-				RequeueExpected: false,
+				ErrorExpected:           false,
+				RequeueExpected:         true,
+				ExpectedRequeueDuration: requeueAfter,
+				CheckWaitingForOwner:    true,
 			},
 		),
 		//Given: baremetalMachine with OwnerRef set, No Machine object.
@@ -608,5 +621,45 @@ var _ = Describe("Reconcile BaremetalMachine", func() {
 				CheckBMHostCleaned:      true,
 			},
 		),
+		//Given: Deletion timestamp on BMMachine, no Machine, No BMHost Given
+		//Expected: Delete is reconciled,BMMachine Finalizer is removed
+		Entry("Should finish deletion of BareMetalMachine when Machine is gone",
+			TestCaseReconcile{
+				Objects: []runtime.Object{
+					userDataSecret(),
+					newBareMetalMachine(bareMetalMachineName, bmmMetaWithDeletion(),
+						bmmSpecWithSecret(), nil, false,
+					),
+				},
+				ErrorExpected:   false,
+				RequeueExpected: false,
+			},
+		),
+		//Given: Deletion timestamp on BMMachine, no Machine, BMHost Given
+		//Expected: Requeue Expected
+		//          Delete is reconciled. BMH should be deprovisioned
+		Entry("Should deprovision bmh when Machine is gone",
+			TestCaseReconcile{
+				Objects: []runtime.Object{
+					userDataSecret(),
+					newBareMetalMachine(bareMetalMachineName,
+						bmmMetaWithAnnotationDeletion(), bmmSpecWithSecret(), nil, false,
+					),
+					newBareMetalHost(&bmh.BareMetalHostSpec{
+						ConsumerRef: &corev1.ObjectReference{
+							Name:       bareMetalMachineName,
+							Namespace:  namespaceName,
+							Kind:       "BareMetalMachine",
+							APIVersion: infrav1.GroupVersion.String(),
+						},
+						Online: true,
+					}, &bmh.BareMetalHostStatus{}),
+				},
+				ErrorExpected:           false,
+				RequeueExpected:         true,
+				ExpectedRequeueDuration: time.Second * 0,
+				CheckBMHostCleaned:      true,
+			},
+		),
 	)
 })
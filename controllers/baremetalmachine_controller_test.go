@@ -45,6 +45,7 @@ type reconcileNormalTestCase struct {
 	Provisioned            bool
 	BootstrapNotReady      bool
 	Annotated              bool
+	CannotProvision        bool
 	AssociateFails         bool
 	GetBMHIDFails          bool
 	BMHIDSet               bool
@@ -53,20 +54,26 @@ type reconcileNormalTestCase struct {
 
 func setReconcileNormalExpectations(ctrl *gomock.Controller,
 	tc reconcileNormalTestCase,
-) *baremetal_mocks.MockMachineManagerInterface {
+) (*baremetal_mocks.MockMachineManagerInterface,
+	*baremetal_mocks.MockClusterManagerInterface,
+) {
 
 	m := baremetal_mocks.NewMockMachineManagerInterface(ctrl)
+	c := baremetal_mocks.NewMockClusterManagerInterface(ctrl)
 
 	m.EXPECT().SetFinalizer()
 
 	// provisioned, we should only call Update, nothing else
 	m.EXPECT().IsProvisioned().Return(tc.Provisioned)
 	if tc.Provisioned {
+		m.EXPECT().Remediate(context.TODO())
+		m.EXPECT().Reprovision(context.TODO())
+		m.EXPECT().Maintenance(context.TODO())
 		m.EXPECT().Update(context.TODO())
 		m.EXPECT().IsBootstrapReady().MaxTimes(0)
 		m.EXPECT().HasAnnotation().MaxTimes(0)
 		m.EXPECT().GetBaremetalHostID(context.TODO()).MaxTimes(0)
-		return m
+		return m, c
 	}
 
 	// Bootstrap data not ready, we'll requeue, not call anything else
@@ -75,18 +82,26 @@ func setReconcileNormalExpectations(ctrl *gomock.Controller,
 		m.EXPECT().HasAnnotation().MaxTimes(0)
 		m.EXPECT().GetBaremetalHostID(context.TODO()).MaxTimes(0)
 		m.EXPECT().Update(context.TODO()).MaxTimes(0)
-		return m
+		return m, c
 	}
 
 	// Bootstrap data is ready and node is not annotated, i.e. not associated
 	m.EXPECT().HasAnnotation().Return(tc.Annotated)
 	if !tc.Annotated {
+		// if too many machines are provisioning, we requeue
+		c.EXPECT().CanProvision(context.TODO()).Return(!tc.CannotProvision, nil)
+		if tc.CannotProvision {
+			m.EXPECT().Associate(context.TODO()).MaxTimes(0)
+			m.EXPECT().GetBaremetalHostID(context.TODO()).MaxTimes(0)
+			m.EXPECT().Update(context.TODO()).MaxTimes(0)
+			return m, c
+		}
 		// if associate fails, we do not go further
 		if tc.AssociateFails {
 			m.EXPECT().Associate(context.TODO()).Return(errors.New("Failed"))
 			m.EXPECT().GetBaremetalHostID(context.TODO()).MaxTimes(0)
 			m.EXPECT().Update(context.TODO()).MaxTimes(0)
-			return m
+			return m, c
 		} else {
 			m.EXPECT().Associate(context.TODO()).Return(nil)
 		}
@@ -99,7 +114,7 @@ func setReconcileNormalExpectations(ctrl *gomock.Controller,
 		)
 		m.EXPECT().Update(context.TODO()).MaxTimes(0)
 		m.EXPECT().SetProviderID("abc").MaxTimes(0)
-		return m
+		return m, c
 	}
 
 	// The ID is available (GetBaremetalHostID did not return nil)
@@ -107,6 +122,7 @@ func setReconcileNormalExpectations(ctrl *gomock.Controller,
 		m.EXPECT().GetBaremetalHostID(context.TODO()).Return(
 			pointer.StringPtr("abc"), nil,
 		)
+		m.EXPECT().ProviderID("abc").Return("metal3://abc")
 
 		// if we fail to set it on the node, we do not go further
 		if tc.SetNodeProviderIDFails {
@@ -115,7 +131,7 @@ func setReconcileNormalExpectations(ctrl *gomock.Controller,
 				Return(errors.New("Failed"))
 			m.EXPECT().SetProviderID("abc").MaxTimes(0)
 			m.EXPECT().Update(context.TODO()).MaxTimes(0)
-			return m
+			return m, c
 		}
 
 		// we successfully set it on the node
@@ -135,7 +151,7 @@ func setReconcileNormalExpectations(ctrl *gomock.Controller,
 
 	// last call
 	m.EXPECT().Update(context.TODO())
-	return m
+	return m, c
 }
 
 type reconcileDeleteTestCase struct {
@@ -193,8 +209,8 @@ var _ = Describe("BareMetalMachine manager", func() {
 
 		DescribeTable("Deletion tests",
 			func(tc reconcileNormalTestCase) {
-				m := setReconcileNormalExpectations(gomockCtrl, tc)
-				res, err := bmReconcile.reconcileNormal(context.TODO(), m)
+				m, c := setReconcileNormalExpectations(gomockCtrl, tc)
+				res, err := bmReconcile.reconcileNormal(context.TODO(), m, c)
 
 				if tc.ExpectError {
 					Expect(err).To(HaveOccurred())
@@ -222,6 +238,12 @@ var _ = Describe("BareMetalMachine manager", func() {
 				ExpectRequeue: false,
 				Annotated:     false,
 			}),
+			Entry("Not Annotated, too many machines provisioning", reconcileNormalTestCase{
+				ExpectError:     false,
+				ExpectRequeue:   true,
+				Annotated:       false,
+				CannotProvision: true,
+			}),
 			Entry("Not Annotated, Associate fails", reconcileNormalTestCase{
 				ExpectError:    true,
 				ExpectRequeue:  false,
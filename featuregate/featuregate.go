@@ -0,0 +1,161 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package featuregate holds the named boolean gates toggling optional
+// behaviours of the provider.
+package featuregate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Feature is the name of a feature gate.
+type Feature string
+
+const (
+	// ControlPlaneEndpointHealthCheck makes a BareMetalCluster Ready only once
+	// its ControlPlaneEndpoint accepts TCP connections.
+	ControlPlaneEndpointHealthCheck Feature = "ControlPlaneEndpointHealthCheck"
+	// ControlPlaneEndpointResolution makes the BareMetalCluster status update
+	// fail when the ControlPlaneEndpoint host is a DNS name that does not
+	// resolve.
+	ControlPlaneEndpointResolution Feature = "ControlPlaneEndpointResolution"
+	// HostRemediation lets the RemediationRequestedAnnotation of a
+	// BareMetalMachine reboot and reprovision its host.
+	HostRemediation Feature = "HostRemediation"
+	// ImageReachabilityCheck enables the admission-time check that the image
+	// and checksum URLs of a BareMetalMachine do not return 404.
+	ImageReachabilityCheck Feature = "ImageReachabilityCheck"
+	// ImageURLDenyList enables the admission-time check that the image URL
+	// host of a BareMetalMachine does not resolve into a denied network, e.g.
+	// the management cluster's own API or pod network.
+	ImageURLDenyList Feature = "ImageURLDenyList"
+	// StrictClusterReadiness makes every BareMetalCluster wait for all its
+	// Machines to be provisioned before being Ready, as if
+	// RequireAllMachinesReady was set.
+	StrictClusterReadiness Feature = "StrictClusterReadiness"
+)
+
+// defaultFeatures are the known gates with their default value. New gates
+// are off by default, the ones of behaviours that predate their gate are on.
+var defaultFeatures = map[Feature]bool{
+	ControlPlaneEndpointHealthCheck: false,
+	ControlPlaneEndpointResolution:  false,
+	HostRemediation:                 true,
+	ImageReachabilityCheck:          false,
+	ImageURLDenyList:                false,
+	StrictClusterReadiness:          false,
+}
+
+// Gates are the feature gates of the provider.
+var Gates = New()
+
+// Enabled returns true if the feature is enabled in Gates.
+func Enabled(f Feature) bool {
+	return Gates.Enabled(f)
+}
+
+// FeatureGate is a set of feature gates. It implements flag.Value, taking a
+// comma-separated list of Feature=bool pairs.
+type FeatureGate struct {
+	mu      sync.RWMutex
+	enabled map[Feature]bool
+}
+
+// New returns a FeatureGate with the default values.
+func New() *FeatureGate {
+	enabled := make(map[Feature]bool, len(defaultFeatures))
+	for f, value := range defaultFeatures {
+		enabled[f] = value
+	}
+	return &FeatureGate{enabled: enabled}
+}
+
+// Enabled returns true if the feature is enabled. Unknown features are
+// disabled.
+func (g *FeatureGate) Enabled(f Feature) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.enabled[f]
+}
+
+// SetFromMap sets the gates from a map of feature names to values. It fails,
+// without changing any gate, if a feature is unknown.
+func (g *FeatureGate) SetFromMap(m map[string]bool) error {
+	for name := range m {
+		if _, ok := defaultFeatures[Feature(name)]; !ok {
+			return errors.Errorf("unknown feature gate %q", name)
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for name, value := range m {
+		g.enabled[Feature(name)] = value
+	}
+	return nil
+}
+
+// Set parses a comma-separated list of Feature=bool pairs and sets the gates
+// accordingly.
+func (g *FeatureGate) Set(value string) error {
+	m := map[string]bool{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return errors.Errorf("missing bool value for feature gate %q", pair)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return errors.Wrapf(err, "invalid value %q for feature gate %q", kv[1], kv[0])
+		}
+		m[strings.TrimSpace(kv[0])] = enabled
+	}
+	return g.SetFromMap(m)
+}
+
+// String returns the gates as a sorted, comma-separated list of
+// Feature=bool pairs.
+func (g *FeatureGate) String() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	pairs := make([]string, 0, len(g.enabled))
+	for f, value := range g.enabled {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", f, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Known returns the sorted names of the known features.
+func Known() []string {
+	names := make([]string, 0, len(defaultFeatures))
+	for f := range defaultFeatures {
+		names = append(names, string(f))
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featuregate
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFeatureGateDefaults(t *testing.T) {
+	g := NewWithT(t)
+
+	gates := New()
+	for _, name := range Known() {
+		// Only the gates of behaviours that predate them are enabled
+		g.Expect(gates.Enabled(Feature(name))).To(Equal(Feature(name) == HostRemediation))
+	}
+	g.Expect(gates.Enabled("Unknown")).To(BeFalse())
+}
+
+func TestFeatureGateSetFromMap(t *testing.T) {
+	tests := []struct {
+		name      string
+		m         map[string]bool
+		expectErr bool
+		expected  bool
+	}{
+		{
+			name:      "should enable a gate",
+			m:         map[string]bool{string(ImageReachabilityCheck): true},
+			expectErr: false,
+			expected:  true,
+		},
+		{
+			name:      "should disable a gate",
+			m:         map[string]bool{string(ImageReachabilityCheck): false},
+			expectErr: false,
+			expected:  false,
+		},
+		{
+			name: "should return error and change nothing when a gate is unknown",
+			m: map[string]bool{
+				string(ImageReachabilityCheck): true,
+				"Unknown":                      true,
+			},
+			expectErr: true,
+			expected:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			gates := New()
+			if tt.expectErr {
+				g.Expect(gates.SetFromMap(tt.m)).NotTo(Succeed())
+			} else {
+				g.Expect(gates.SetFromMap(tt.m)).To(Succeed())
+			}
+			g.Expect(gates.Enabled(ImageReachabilityCheck)).To(Equal(tt.expected))
+			g.Expect(gates.Enabled(StrictClusterReadiness)).To(BeFalse())
+		})
+	}
+}
+
+func TestFeatureGateSet(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		expectErr bool
+		expected  string
+	}{
+		{
+			name:      "should set gates from a list",
+			value:     "ImageReachabilityCheck=true, StrictClusterReadiness=false",
+			expectErr: false,
+			expected:  "ControlPlaneEndpointHealthCheck=false,ControlPlaneEndpointResolution=false,HostRemediation=true,ImageReachabilityCheck=true,ImageURLDenyList=false,StrictClusterReadiness=false",
+		},
+		{
+			name:      "should accept an empty list",
+			value:     "",
+			expectErr: false,
+			expected:  "ControlPlaneEndpointHealthCheck=false,ControlPlaneEndpointResolution=false,HostRemediation=true,ImageReachabilityCheck=false,ImageURLDenyList=false,StrictClusterReadiness=false",
+		},
+		{
+			name:      "should return error when value missing",
+			value:     "ImageReachabilityCheck",
+			expectErr: true,
+			expected:  "ControlPlaneEndpointHealthCheck=false,ControlPlaneEndpointResolution=false,HostRemediation=true,ImageReachabilityCheck=false,ImageURLDenyList=false,StrictClusterReadiness=false",
+		},
+		{
+			name:      "should disable a gate enabled by default",
+			value:     "HostRemediation=false",
+			expectErr: false,
+			expected:  "ControlPlaneEndpointHealthCheck=false,ControlPlaneEndpointResolution=false,HostRemediation=false,ImageReachabilityCheck=false,ImageURLDenyList=false,StrictClusterReadiness=false",
+		},
+		{
+			name:      "should return error when value not a bool",
+			value:     "ImageReachabilityCheck=yes",
+			expectErr: true,
+			expected:  "ControlPlaneEndpointHealthCheck=false,ControlPlaneEndpointResolution=false,HostRemediation=true,ImageReachabilityCheck=false,ImageURLDenyList=false,StrictClusterReadiness=false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			gates := New()
+			if tt.expectErr {
+				g.Expect(gates.Set(tt.value)).NotTo(Succeed())
+			} else {
+				g.Expect(gates.Set(tt.value)).To(Succeed())
+			}
+			g.Expect(gates.String()).To(Equal(tt.expected))
+		})
+	}
+}
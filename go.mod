@@ -20,10 +20,11 @@ require (
 	github.com/onsi/ginkgo v1.12.0
 	github.com/onsi/gomega v1.9.0
 	github.com/pkg/errors v0.9.1
-	github.com/prometheus/client_golang v1.4.1 // indirect
+	github.com/prometheus/client_golang v1.4.1
+	github.com/prometheus/client_model v0.2.0
 	github.com/prometheus/procfs v0.0.10 // indirect
 	github.com/securego/gosec v0.0.0-20200203094520-d13bb6d2420c // indirect
-	golang.org/x/crypto v0.0.0-20200221231518-2aa609cf4a9d // indirect
+	golang.org/x/crypto v0.0.0-20200221231518-2aa609cf4a9d
 	golang.org/x/net v0.0.0-20200226051749-491c5fce7268
 	golang.org/x/sys v0.0.0-20200223170610-d5e6a3e2c0ae // indirect
 	k8s.io/api v0.17.3
@@ -37,7 +38,7 @@ require (
 	sigs.k8s.io/cluster-api v0.3.0-rc.0.0.20200216171528-7eead355bcbc
 	sigs.k8s.io/controller-runtime v0.5.0
 	sigs.k8s.io/testing_frameworks v0.1.2 // indirect
-	sigs.k8s.io/yaml v1.2.0 // indirect
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace (
@@ -20,6 +20,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	bmoapis "github.com/metal3-io/baremetal-operator/pkg/apis"
@@ -28,6 +29,7 @@ import (
 	"github.com/metal3-io/cluster-api-provider-baremetal/baremetal"
 	capm3remote "github.com/metal3-io/cluster-api-provider-baremetal/baremetal/remote"
 	"github.com/metal3-io/cluster-api-provider-baremetal/controllers"
+	"github.com/metal3-io/cluster-api-provider-baremetal/featuregate"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
@@ -52,6 +54,9 @@ var (
 	webhookPort             int
 	healthAddr              string
 	watchNamespace          string
+	forceDeleteClusters     bool
+	controlPlaneLabel       string
+	descendantLabelKeys     string
 )
 
 func init() {
@@ -76,6 +81,26 @@ func main() {
 		"Webhook Server port (set to 0 to disable)")
 	flag.StringVar(&healthAddr, "health-addr", ":9440",
 		"The address the health endpoint binds to.")
+	flag.StringVar(&infrav1.HostnamePrefix, "hostname-prefix", "",
+		"The prefix prepended to BareMetalMachine names to build hostnames, accounted for when validating the name length.")
+	flag.DurationVar(&infrav1.DefaultProvisioningTimeout, "default-provisioning-timeout", 2*time.Hour,
+		"The ProvisioningTimeout of the BareMetalMachines that do not set one, 0 for no limit.")
+	flag.DurationVar(&baremetal.ConsumerLeaseTTL, "consumer-lease-ttl", 10*time.Minute,
+		"The time after which a BareMetalHost claimed by a BareMetalMachine that no longer exists is reclaimed, if the claim was not renewed. 0 never reclaims the hosts.")
+	flag.StringVar(&baremetal.FailureDomainLabel, "failure-domain-label", baremetal.DefaultFailureDomainLabel,
+		"The BareMetalHost label holding the failure domain of the host, used to publish the failure domains of the clusters and to place their machines.")
+	flag.BoolVar(&forceDeleteClusters, "force-delete-clusters", false,
+		"Delete the BareMetalClusters without waiting for the Machines of the cluster to be deleted.")
+	flag.StringVar(&controlPlaneLabel, "control-plane-label", baremetal.DefaultControlPlaneLabel,
+		"The label key identifying the control plane Machines.")
+	flag.StringVar(&descendantLabelKeys, "descendant-label-keys", "",
+		"A comma-separated list of the label keys holding the cluster name on the Machines of a cluster. If unspecified, the Cluster API cluster name label is used.")
+	flag.Var(infrav1.DeniedImageNetworks, "denied-image-networks",
+		"A comma-separated list of CIDRs that the image URL hosts may not resolve into, e.g. the API and pod networks of the management cluster. Checked when the ImageURLDenyList feature gate is enabled.")
+	flag.Var(featuregate.Gates, "feature-gates",
+		fmt.Sprintf("A comma-separated list of Feature=bool pairs toggling optional behaviours, all disabled by default except HostRemediation. Known features: %s.",
+			strings.Join(featuregate.Known(), ", "),
+		))
 	flag.Parse()
 
 	ctrl.SetLogger(klogr.New())
@@ -166,9 +191,21 @@ func setupReconcilers(mgr ctrl.Manager) {
 		os.Exit(1)
 	}
 
+	clusterOptions := []baremetal.Option{
+		baremetal.WithEventRecorder(mgr.GetEventRecorderFor("baremetalcluster-controller")),
+		baremetal.WithControlPlaneLabel(controlPlaneLabel),
+	}
+	if forceDeleteClusters {
+		clusterOptions = append(clusterOptions, baremetal.WithForceDelete())
+	}
+	if descendantLabelKeys != "" {
+		clusterOptions = append(clusterOptions,
+			baremetal.WithDescendantLabelKeys(strings.Split(descendantLabelKeys, ",")...),
+		)
+	}
 	if err := (&controllers.BareMetalClusterReconciler{
 		Client:         mgr.GetClient(),
-		ManagerFactory: baremetal.NewManagerFactory(mgr.GetClient()),
+		ManagerFactory: baremetal.NewManagerFactory(mgr.GetClient(), clusterOptions...),
 		Log:            ctrl.Log.WithName("controllers").WithName("BareMetalCluster"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "BareMetalClusterReconciler")